@@ -0,0 +1,151 @@
+package resource
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"strconv"
+	"strings"
+	"time"
+	models "verkaufsautomat/internal/core/domain/resource"
+	"verkaufsautomat/internal/core/logger"
+)
+
+const apiKeyIDKey = "api_key_id"
+
+// apiKeyScopes maps the routes API keys may call, by method and route
+// pattern, onto the scope they need. Routes that are not listed, such as
+// account, password and administration routes other than the audit log,
+// need a login token.
+var apiKeyScopes = map[string]string{
+	"GET /auth/get_products":                                   models.ScopeProductsRead,
+	"GET /auth/get_product/:id":                                models.ScopeProductsRead,
+	"GET /api/v2/products":                                     models.ScopeProductsRead,
+	"GET /api/v2/products/:id":                                 models.ScopeProductsRead,
+	"POST /auth/create_product":                                models.ScopeProductsWrite,
+	"PUT /auth/update_product/:id":                             models.ScopeProductsWrite,
+	"DELETE /auth/delete_product/:id":                          models.ScopeProductsWrite,
+	"POST /api/v2/products":                                    models.ScopeProductsWrite,
+	"PUT /api/v2/products/:id":                                 models.ScopeProductsWrite,
+	"DELETE /api/v2/products/:id":                              models.ScopeProductsWrite,
+	"GET /api/v2/categories":                                   models.ScopeProductsRead,
+	"GET /api/v2/categories/:id":                               models.ScopeProductsRead,
+	"POST /api/v2/categories":                                  models.ScopeProductsWrite,
+	"PUT /api/v2/categories/:id":                               models.ScopeProductsWrite,
+	"DELETE /api/v2/categories/:id":                            models.ScopeProductsWrite,
+	"PATCH /auth/deposit_money":                                models.ScopeDeposits,
+	"PATCH /auth/reset_deposit":                                models.ScopeDeposits,
+	"GET /api/v2/me/deposit":                                   models.ScopeDeposits,
+	"POST /api/v2/me/deposit":                                  models.ScopeDeposits,
+	"DELETE /api/v2/me/deposit":                                models.ScopeDeposits,
+	"GET /api/v2/orders":                                       models.ScopeOrdersRead,
+	"GET /api/v2/orders/:id":                                   models.ScopeOrdersRead,
+	"POST /auth/buy_product":                                   models.ScopeOrdersWrite,
+	"POST /api/v2/orders":                                      models.ScopeOrdersWrite,
+	"GET /api/v2/events":                                       models.ScopeEvents,
+	"GET /api/v2/webhooks":                                     models.ScopeWebhooks,
+	"POST /api/v2/webhooks":                                    models.ScopeWebhooks,
+	"DELETE /api/v2/webhooks/:id":                              models.ScopeWebhooks,
+	"GET /api/v2/webhooks/:id/deliveries":                      models.ScopeWebhooks,
+	"POST /api/v2/webhooks/:id/deliveries/:delivery_id/replay": models.ScopeWebhooks,
+	"GET /api/v2/admin/audit":                                  models.ScopeAuditRead,
+	"GET /api/v2/admin/audit/export":                           models.ScopeAuditRead,
+}
+
+// authenticateAPIKey lets a request through as the user an API key acts
+// as, if the key has the scope of the route. Role checks still apply to
+// that user.
+func (s *HTTPHandler) authenticateAPIKey(c *gin.Context, credential string) {
+	key, user, err := s.MachineService.AuthenticateAPIKey(c.Request.Context(), credential)
+	if errors.Is(err, models.ErrInvalidAPIKey) {
+		requestLogger(c).Warn("Error verifying api key: " + err.Error())
+		c.JSON(401, "Unauthorized")
+		c.Abort()
+		return
+	}
+	if err != nil {
+		requestLogger(c).Error("Error verifying api key: " + err.Error())
+		abortWithError(c, err)
+		return
+	}
+
+	scope, ok := apiKeyScopes[c.Request.Method+" "+c.FullPath()]
+	if !ok || !key.HasScope(scope) {
+		requestLogger(c).With(logger.Fields{"api_key_id": key.APIKeyID}).Warn("Api key lacks the scope for " + c.FullPath())
+		c.AbortWithStatusJSON(403, gin.H{"error": "api key lacks the scope for this route"})
+		return
+	}
+
+	c.Set(userIDKey, int(user.UserID))
+	c.Set(roleIDKey, int(user.RoleID))
+	c.Set(usernameKey, user.Username)
+	c.Set(apiKeyIDKey, int(key.APIKeyID))
+	c.Request = c.Request.WithContext(logger.NewContext(c.Request.Context(), logger.Fields{"user_id": int(user.UserID), "api_key_id": int(key.APIKeyID)}))
+	c.Next()
+}
+
+type apiKeyRequest struct {
+	Name      string    `json:"name" binding:"required"`
+	UserID    uint      `json:"user_id" binding:"required"`
+	Scopes    []string  `json:"scopes" binding:"required"`
+	ExpiresAt time.Time `json:"expires_at" binding:"required"`
+}
+
+func (s *HTTPHandler) CreateAPIKey(c *gin.Context) {
+	var request apiKeyRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		requestLogger(c).Error("Error binding json: " + err.Error())
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	adminID, _ := currentUser(c)
+	key := models.APIKey{
+		Name:      request.Name,
+		UserID:    request.UserID,
+		Scopes:    strings.Join(request.Scopes, ","),
+		ExpiresAt: request.ExpiresAt,
+		CreatedBy: uint(adminID),
+	}
+	if err := s.MachineService.CreateAPIKey(c.Request.Context(), &key); err != nil {
+		requestLogger(c).Error("Error creating api key: " + err.Error())
+		abortWithError(c, err)
+		return
+	}
+
+	recorded := key
+	recorded.Key = ""
+	audit(c, strconv.Itoa(int(key.APIKeyID)), nil, recorded)
+	c.JSON(201, key)
+}
+
+func (s *HTTPHandler) GetAPIKeys(c *gin.Context) {
+	keys, err := s.MachineService.GetAPIKeys(c.Request.Context())
+	if err != nil {
+		requestLogger(c).Error("Error getting api keys: " + err.Error())
+		abortWithError(c, err)
+		return
+	}
+
+	if keys == nil {
+		keys = []models.APIKey{}
+	}
+	c.JSON(200, keys)
+}
+
+// RevokeAPIKey makes a key unusable at once. It stays in the list.
+func (s *HTTPHandler) RevokeAPIKey(c *gin.Context) {
+	id, ok := idParam(c)
+	if !ok {
+		return
+	}
+
+	key, err := s.MachineService.RevokeAPIKey(c.Request.Context(), id)
+	if err != nil {
+		requestLogger(c).Error("Error revoking api key: " + err.Error())
+		abortWithError(c, err)
+		return
+	}
+
+	audit(c, strconv.Itoa(id), nil, key)
+	c.Status(204)
+}
@@ -0,0 +1,141 @@
+package resource
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/golang/mock/gomock"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+	"verkaufsautomat/internal/core/domain/resource"
+	"verkaufsautomat/internal/core/events"
+)
+
+func TestApplication_APIKeys(t *testing.T) {
+	mockedService, _, router := newTestRouter(t, events.NewBus())
+	serve := serveRouter(t, router)
+
+	admin, _ := testTokens.Generate(&resource.User{UserID: 3, RoleID: resource.AdminRoleID, Username: "root"})
+	machine := resource.User{UserID: 2, RoleID: resource.BuyerRoleID, Username: "machine-1"}
+	key := resource.APIKey{APIKeyID: 5, UserID: 2, Prefix: "vk_0123456789ab", Scopes: "products:read,deposits", ExpiresAt: time.Now().Add(time.Hour)}
+	const credential = "vk_0123456789ab_0123456789abcdef"
+
+	t.Run("Every scoped route exists", func(t *testing.T) {
+		routes := map[string]bool{}
+		for _, route := range router.Routes() {
+			routes[route.Method+" "+route.Path] = true
+		}
+		for route := range apiKeyScopes {
+			if !routes[route] {
+				t.Errorf("Scoped route %s is not registered", route)
+			}
+		}
+	})
+
+	t.Run("API key acts as its user within its scopes", func(t *testing.T) {
+		mockedService.EXPECT().AuthenticateAPIKey(gomock.Any(), credential).Return(key, machine, nil)
+		mockedService.EXPECT().GetUserById(gomock.Any(), 2).Return(resource.User{UserID: 2, Deposit: 50}, nil)
+
+		response := serve("GET", "/api/v2/me/deposit", credential, "")
+
+		if response.Code != http.StatusOK {
+			t.Fatalf("Expected status code %d, got %d", http.StatusOK, response.Code)
+		}
+		if !strings.Contains(response.Body.String(), "50") {
+			t.Errorf("Expected the deposit of the key's user, got %s", response.Body.String())
+		}
+	})
+
+	t.Run("Routes outside the scopes are refused", func(t *testing.T) {
+		mockedService.EXPECT().AuthenticateAPIKey(gomock.Any(), credential).Return(key, machine, nil).Times(2)
+
+		for _, path := range []string{"/api/v2/orders", "/auth/me"} {
+			response := serve("GET", path, credential, "")
+
+			if response.Code != http.StatusForbidden {
+				t.Errorf("Expected status code %d for %s, got %d", http.StatusForbidden, path, response.Code)
+			}
+		}
+	})
+
+	t.Run("Invalid API keys are unauthorized", func(t *testing.T) {
+		mockedService.EXPECT().AuthenticateAPIKey(gomock.Any(), "vk_0123456789ab_revoked").Return(resource.APIKey{}, resource.User{}, resource.ErrInvalidAPIKey)
+
+		response := serve("GET", "/api/v2/products", "vk_0123456789ab_revoked", "")
+
+		if response.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status code %d, got %d", http.StatusUnauthorized, response.Code)
+		}
+	})
+
+	t.Run("Admins create keys, which are shown once", func(t *testing.T) {
+		var entry resource.AuditEntry
+		mockedService.EXPECT().RecordAudit(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, recorded *resource.AuditEntry) error {
+			entry = *recorded
+			return nil
+		})
+		mockedService.EXPECT().CreateAPIKey(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, created *resource.APIKey) error {
+			if created.UserID != 2 || created.Scopes != "products:read,deposits" || created.CreatedBy != 3 {
+				t.Errorf("Unexpected key %+v", created)
+			}
+			created.APIKeyID = 5
+			created.Prefix = key.Prefix
+			created.Key = credential
+			return nil
+		})
+
+		response := serve("POST", "/api/v2/admin/api-keys", admin, `{"name":"machine 1","user_id":2,"scopes":["products:read","deposits"],"expires_at":"2030-01-01T00:00:00Z"}`)
+
+		if response.Code != http.StatusCreated {
+			t.Fatalf("Expected status code %d, got %d", http.StatusCreated, response.Code)
+		}
+		var body resource.APIKey
+		if err := json.Unmarshal(response.Body.Bytes(), &body); err != nil {
+			t.Fatal(err)
+		}
+		if body.Key != credential || body.Prefix != key.Prefix {
+			t.Errorf("Expected the key in the response, got %+v", body)
+		}
+		if entry.Action != resource.AuditAPIKeyCreate || entry.TargetID != "5" || strings.Contains(entry.After, credential) {
+			t.Errorf("Unexpected audit entry %+v", entry)
+		}
+	})
+
+	t.Run("Invalid keys are not created", func(t *testing.T) {
+		mockedService.EXPECT().RecordAudit(gomock.Any(), gomock.Any()).Return(nil)
+		mockedService.EXPECT().CreateAPIKey(gomock.Any(), gomock.Any()).Return(resource.ErrInvalidAPIKeyInput)
+
+		response := serve("POST", "/api/v2/admin/api-keys", admin, `{"name":"machine 1","user_id":2,"scopes":["everything"],"expires_at":"2030-01-01T00:00:00Z"}`)
+
+		if response.Code != http.StatusBadRequest {
+			t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, response.Code)
+		}
+	})
+
+	t.Run("Admins revoke keys", func(t *testing.T) {
+		mockedService.EXPECT().RecordAudit(gomock.Any(), gomock.Any()).Return(nil)
+		revoked := key
+		now := time.Now()
+		revoked.RevokedAt = &now
+		mockedService.EXPECT().RevokeAPIKey(gomock.Any(), 5).Return(revoked, nil)
+
+		response := serve("DELETE", "/api/v2/admin/api-keys/5", admin, "")
+
+		if response.Code != http.StatusNoContent {
+			t.Errorf("Expected status code %d, got %d", http.StatusNoContent, response.Code)
+		}
+	})
+
+	t.Run("API keys cannot manage API keys", func(t *testing.T) {
+		adminKey := key
+		adminKey.Scopes = strings.Join(resource.APIKeyScopes, ",")
+		mockedService.EXPECT().AuthenticateAPIKey(gomock.Any(), credential).Return(adminKey, resource.User{UserID: 3, RoleID: resource.AdminRoleID}, nil)
+
+		response := serve("GET", "/api/v2/admin/api-keys", credential, "")
+
+		if response.Code != http.StatusForbidden {
+			t.Errorf("Expected status code %d, got %d", http.StatusForbidden, response.Code)
+		}
+	})
+}
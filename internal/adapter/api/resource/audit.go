@@ -0,0 +1,264 @@
+package resource
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"strconv"
+	"strings"
+	"time"
+	models "verkaufsautomat/internal/core/domain/resource"
+	"verkaufsautomat/internal/core/logger"
+)
+
+const auditKey = "audit"
+
+// auditRecord collects what a handler knows about the change it made. The
+// Audited middleware turns it into an audit entry.
+type auditRecord struct {
+	targetID string
+	before   interface{}
+	after    interface{}
+	actor    *models.User
+}
+
+func auditRecordOf(c *gin.Context) *auditRecord {
+	if record, ok := c.Get(auditKey); ok {
+		return record.(*auditRecord)
+	}
+	record := &auditRecord{}
+	c.Set(auditKey, record)
+	return record
+}
+
+// audit records the target of an audited action and its state before and
+// after the change. Either state may be nil.
+func audit(c *gin.Context, targetID string, before, after interface{}) {
+	record := auditRecordOf(c)
+	record.targetID = targetID
+	record.before = before
+	record.after = after
+}
+
+// auditActor sets the actor on routes without a token, such as login.
+func auditActor(c *gin.Context, user models.User) {
+	auditRecordOf(c).actor = &user
+}
+
+// Audited records every request to the route in the audit log once the
+// handler has finished, whether it succeeded or not.
+func (s *HTTPHandler) Audited(action, targetType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		userID, roleID := currentUser(c)
+		entry := models.AuditEntry{
+			ActorID:       uint(userID),
+			ActorUsername: c.GetString(usernameKey),
+			ActorRoleID:   uint(roleID),
+			Action:        action,
+			TargetType:    targetType,
+			TargetID:      c.Param("id"),
+			Succeeded:     c.Writer.Status() < 400,
+			Status:        strconv.Itoa(c.Writer.Status()),
+			IP:            c.ClientIP(),
+			RequestID:     c.GetString(requestIDKey),
+		}
+
+		record := auditRecordOf(c)
+		if record.targetID != "" {
+			entry.TargetID = record.targetID
+		}
+		if record.actor != nil {
+			entry.ActorID = record.actor.UserID
+			entry.ActorUsername = record.actor.Username
+			entry.ActorRoleID = record.actor.RoleID
+		}
+		entry.Before = auditJSON(record.before)
+		entry.After = auditJSON(record.after)
+
+		if err := s.MachineService.RecordAudit(c.Request.Context(), &entry); err != nil {
+			requestLogger(c).Error("Error recording audit entry: " + err.Error())
+		}
+	}
+}
+
+func auditJSON(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		logger.Error("Error encoding audit value: " + err.Error())
+		return ""
+	}
+	return string(data)
+}
+
+type roleRequest struct {
+	RoleID int `json:"role_id" binding:"required"`
+}
+
+type roleResponse struct {
+	UserID uint `json:"user_id"`
+	RoleID uint `json:"role_id"`
+}
+
+func (s *HTTPHandler) ChangeUserRole(c *gin.Context) {
+	id, ok := idParam(c)
+	if !ok {
+		return
+	}
+
+	var request roleRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		requestLogger(c).Error("Error binding json: " + err.Error())
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	previous, err := s.MachineService.GetUserById(c.Request.Context(), id)
+	if err != nil {
+		requestLogger(c).Error("Error getting user: " + err.Error())
+		abortWithError(c, err)
+		return
+	}
+
+	user, err := s.MachineService.ChangeUserRole(c.Request.Context(), id, request.RoleID)
+	if err != nil {
+		requestLogger(c).Error("Error changing role: " + err.Error())
+		abortWithError(c, err)
+		return
+	}
+
+	response := roleResponse{UserID: user.UserID, RoleID: user.RoleID}
+	audit(c, strconv.Itoa(id), roleResponse{UserID: previous.UserID, RoleID: previous.RoleID}, response)
+	c.JSON(200, response)
+}
+
+// UnlockUser lifts the lockout of an account after repeated failed logins
+// and clears its login throttling.
+func (s *HTTPHandler) UnlockUser(c *gin.Context) {
+	id, ok := idParam(c)
+	if !ok {
+		return
+	}
+
+	user, err := s.MachineService.UnlockUser(c.Request.Context(), id)
+	if err != nil {
+		requestLogger(c).Error("Error unlocking user: " + err.Error())
+		abortWithError(c, err)
+		return
+	}
+
+	s.throttle.reset(strings.ToLower(user.Username))
+	audit(c, strconv.Itoa(id), nil, nil)
+	c.Status(204)
+}
+
+// auditFilter reads the filter from the query string: actor_id, action,
+// target_type, target_id, from and to (RFC 3339), limit and offset.
+func auditFilter(c *gin.Context) (models.AuditFilter, bool) {
+	filter := models.AuditFilter{
+		Action:     c.Query("action"),
+		TargetType: c.Query("target_type"),
+		TargetID:   c.Query("target_id"),
+	}
+
+	var err error
+	for name, field := range map[string]*int{"actor_id": &filter.ActorID, "limit": &filter.Limit, "offset": &filter.Offset} {
+		if value := c.Query(name); value != "" && err == nil {
+			*field, err = strconv.Atoi(value)
+		}
+	}
+	for name, field := range map[string]*time.Time{"from": &filter.From, "to": &filter.To} {
+		if value := c.Query(name); value != "" && err == nil {
+			*field, err = time.Parse(time.RFC3339, value)
+		}
+	}
+	if err != nil {
+		requestLogger(c).Error("Error parsing audit filter: " + err.Error())
+		c.AbortWithStatusJSON(400, gin.H{"error": err.Error()})
+		return filter, false
+	}
+	return filter, true
+}
+
+func (s *HTTPHandler) GetAuditEntries(c *gin.Context) {
+	filter, ok := auditFilter(c)
+	if !ok {
+		return
+	}
+
+	entries, err := s.MachineService.GetAuditEntries(c.Request.Context(), filter)
+	if err != nil {
+		requestLogger(c).Error("Error getting audit entries: " + err.Error())
+		abortWithError(c, err)
+		return
+	}
+
+	if entries == nil {
+		entries = []models.AuditEntry{}
+	}
+	c.JSON(200, entries)
+}
+
+var auditCSVHeader = []string{
+	"audit_id", "created_at", "actor_id", "actor_username", "actor_role_id", "action",
+	"target_type", "target_id", "succeeded", "status", "ip", "request_id", "before", "after",
+}
+
+// ExportAuditEntries returns the same entries as GetAuditEntries as CSV.
+// Text cells pass through csvCell, since usernames, targets and the
+// before and after states are chosen by users.
+func (s *HTTPHandler) ExportAuditEntries(c *gin.Context) {
+	filter, ok := auditFilter(c)
+	if !ok {
+		return
+	}
+
+	entries, err := s.MachineService.GetAuditEntries(c.Request.Context(), filter)
+	if err != nil {
+		requestLogger(c).Error("Error getting audit entries: " + err.Error())
+		abortWithError(c, err)
+		return
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="audit.csv"`)
+	c.Status(200)
+
+	writer := csv.NewWriter(c.Writer)
+	writer.Write(auditCSVHeader)
+	for _, entry := range entries {
+		writer.Write([]string{
+			strconv.Itoa(int(entry.AuditID)),
+			entry.CreatedAt.UTC().Format(time.RFC3339),
+			strconv.Itoa(int(entry.ActorID)),
+			csvCell(entry.ActorUsername),
+			strconv.Itoa(int(entry.ActorRoleID)),
+			csvCell(entry.Action),
+			csvCell(entry.TargetType),
+			csvCell(entry.TargetID),
+			strconv.FormatBool(entry.Succeeded),
+			csvCell(entry.Status),
+			csvCell(entry.IP),
+			csvCell(entry.RequestID),
+			csvCell(entry.Before),
+			csvCell(entry.After),
+		})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		requestLogger(c).Error("Error writing audit export: " + err.Error())
+	}
+}
+
+// csvCell prefixes a cell that spreadsheets would read as a formula with an
+// apostrophe, so that opening the export does not run it.
+func csvCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
@@ -0,0 +1,150 @@
+package resource
+
+import (
+	"context"
+	"encoding/csv"
+	"github.com/golang/mock/gomock"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+	"verkaufsautomat/internal/core/domain/resource"
+)
+
+func TestApplication_Audit(t *testing.T) {
+	mockedService, serve := newTestServer(t)
+
+	seller, _ := testTokens.Generate(&resource.User{UserID: 1, RoleID: resource.SellerRoleID, Username: "harry"})
+	buyer, _ := testTokens.Generate(&resource.User{UserID: 2, RoleID: resource.BuyerRoleID, Username: "sally"})
+	admin, _ := testTokens.Generate(&resource.User{UserID: 3, RoleID: resource.AdminRoleID, Username: "root"})
+
+	t.Run("Price change is recorded with before and after", func(t *testing.T) {
+		mockedService.EXPECT().GetProductById(gomock.Any(), 12).Return(resource.Product{ProductID: 12, ProductName: "cocacola", Cost: 65, SellerID: 1}, nil)
+		mockedService.EXPECT().UpdateProductByID(gomock.Any(), 12, gomock.Any()).Return(nil)
+
+		var entry resource.AuditEntry
+		mockedService.EXPECT().RecordAudit(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, recorded *resource.AuditEntry) error {
+			entry = *recorded
+			return nil
+		})
+
+		response := serve("PUT", "/api/v2/products/12", seller, `{"product_name":"cocacola","cost":70,"amount_available":0}`)
+
+		if response.Code != http.StatusOK {
+			t.Fatalf("Expected status code %d, got %d", http.StatusOK, response.Code)
+		}
+		if entry.ActorID != 1 || entry.ActorUsername != "harry" || entry.Action != resource.AuditProductUpdate {
+			t.Errorf("Unexpected actor or action in %+v", entry)
+		}
+		if entry.TargetType != "product" || entry.TargetID != "12" || !entry.Succeeded {
+			t.Errorf("Unexpected target or outcome in %+v", entry)
+		}
+		if !strings.Contains(entry.Before, `"cost":65`) || !strings.Contains(entry.After, `"cost":70`) {
+			t.Errorf("Expected the old and new price, got %q and %q", entry.Before, entry.After)
+		}
+		if entry.IP != "203.0.113.9" || entry.RequestID == "" || entry.RequestID != response.Header().Get(RequestIDHeader) {
+			t.Errorf("Unexpected IP or request ID in %+v", entry)
+		}
+	})
+
+	t.Run("Denied attempts are recorded", func(t *testing.T) {
+		var entry resource.AuditEntry
+		mockedService.EXPECT().RecordAudit(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, recorded *resource.AuditEntry) error {
+			entry = *recorded
+			return nil
+		})
+
+		response := serve("DELETE", "/api/v2/products/12", buyer, "")
+
+		if response.Code != http.StatusForbidden {
+			t.Errorf("Expected status code %d, got %d", http.StatusForbidden, response.Code)
+		}
+		if entry.Succeeded || entry.Status != "403" || entry.ActorID != 2 || entry.TargetID != "12" {
+			t.Errorf("Expected a failed attempt by user 2, got %+v", entry)
+		}
+	})
+
+	t.Run("Only admins can read the audit log", func(t *testing.T) {
+		response := serve("GET", "/api/v2/admin/audit", seller, "")
+
+		if response.Code != http.StatusForbidden {
+			t.Errorf("Expected status code %d, got %d", http.StatusForbidden, response.Code)
+		}
+	})
+
+	t.Run("Audit log is filtered", func(t *testing.T) {
+		mockedService.EXPECT().GetAuditEntries(gomock.Any(), resource.AuditFilter{
+			Action:     resource.AuditProductUpdate,
+			TargetType: "product",
+			TargetID:   "12",
+			From:       time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+		}).Return([]resource.AuditEntry{}, nil)
+
+		response := serve("GET", "/api/v2/admin/audit?action=product.update&target_type=product&target_id=12&from=2022-01-01T00:00:00Z", admin, "")
+
+		if response.Code != http.StatusOK {
+			t.Errorf("Expected status code %d, got %d", http.StatusOK, response.Code)
+		}
+	})
+
+	t.Run("Audit log is exported as CSV", func(t *testing.T) {
+		mockedService.EXPECT().GetAuditEntries(gomock.Any(), gomock.Any()).Return([]resource.AuditEntry{{
+			AuditID:    1,
+			ActorID:    2,
+			Action:     resource.AuditDepositReset,
+			TargetType: "user",
+			TargetID:   "2",
+			Before:     `{"deposit":35}`,
+			After:      `{"deposit":0}`,
+			Succeeded:  true,
+			Status:     "204",
+		}}, nil)
+
+		response := serve("GET", "/api/v2/admin/audit/export", admin, "")
+
+		if !strings.HasPrefix(response.Header().Get("Content-Type"), "text/csv") {
+			t.Errorf("Expected CSV, got %q", response.Header().Get("Content-Type"))
+		}
+		records, err := csv.NewReader(response.Body).ReadAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(records) != 2 || records[1][5] != resource.AuditDepositReset || records[1][12] != `{"deposit":35}` {
+			t.Errorf("Unexpected export %v", records)
+		}
+	})
+
+	t.Run("Cells that spreadsheets read as formulas are neutralised", func(t *testing.T) {
+		mockedService.EXPECT().GetAuditEntries(gomock.Any(), gomock.Any()).Return([]resource.AuditEntry{{
+			AuditID:       1,
+			ActorUsername: "=HYPERLINK(\"http://attacker.example\")",
+			Action:        resource.AuditProductUpdate,
+			TargetType:    "product",
+			TargetID:      "+12",
+			Before:        "-1",
+			After:         "@SUM(A1)",
+			Status:        "200",
+		}}, nil)
+
+		response := serve("GET", "/api/v2/admin/audit/export", admin, "")
+
+		records, err := csv.NewReader(response.Body).ReadAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(records) != 2 {
+			t.Fatalf("Unexpected export %v", records)
+		}
+		for column, want := range map[int]string{
+			3:  "'=HYPERLINK(\"http://attacker.example\")",
+			5:  resource.AuditProductUpdate,
+			7:  "'+12",
+			12: "'-1",
+			13: "'@SUM(A1)",
+		} {
+			if records[1][column] != want {
+				t.Errorf("Expected %s to be %q, got %q", records[0][column], want, records[1][column])
+			}
+		}
+	})
+}
@@ -0,0 +1,105 @@
+package resource
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	models "verkaufsautomat/internal/core/domain/resource"
+)
+
+// GetCatalogue lists the products for anyone, such as the display of the
+// machine, without credentials.
+func (s *HTTPHandler) GetCatalogue(c *gin.Context) {
+	filter, ok := productFilter(c)
+	if !ok {
+		return
+	}
+
+	catalogue, err := s.MachineService.GetCatalogue(c.Request.Context(), filter)
+	if err != nil {
+		requestLogger(c).Error("Error getting catalogue: " + err.Error())
+		abortWithError(c, err)
+		return
+	}
+	s.cacheable(c, catalogue.Products, catalogue.ModifiedAt)
+}
+
+func (s *HTTPHandler) GetCatalogueProduct(c *gin.Context) {
+	id, ok := idParam(c)
+	if !ok {
+		return
+	}
+
+	product, modifiedAt, err := s.MachineService.GetCatalogueProduct(c.Request.Context(), id)
+	if err != nil {
+		requestLogger(c).Error("Error getting catalogue product: " + err.Error())
+		abortWithError(c, err)
+		return
+	}
+	s.cacheable(c, product, modifiedAt)
+}
+
+// GetCatalogueCategories lists the categories for filtering the catalogue.
+// The list has no Last-Modified time, as deleted categories leave no trace.
+func (s *HTTPHandler) GetCatalogueCategories(c *gin.Context) {
+	categories, err := s.MachineService.GetCategories(c.Request.Context())
+	if err != nil {
+		requestLogger(c).Error("Error getting categories: " + err.Error())
+		abortWithError(c, err)
+		return
+	}
+	if categories == nil {
+		categories = []models.Category{}
+	}
+	s.cacheable(c, categories, time.Time{})
+}
+
+// cacheable responds with body, or with 304 if the client's copy is still
+// current. The ETag is a hash of the body, so it changes with anything the
+// client sees. Clients and shared caches may reuse the response for
+// CatalogueMaxAge.
+func (s *HTTPHandler) cacheable(c *gin.Context, body interface{}, modifiedAt time.Time) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		requestLogger(c).Error("Error encoding response: " + err.Error())
+		abortWithError(c, err)
+		return
+	}
+	sum := sha256.Sum256(data)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "public, max-age="+strconv.Itoa(int(s.Config.CatalogueMaxAge/time.Second)))
+	if !modifiedAt.IsZero() {
+		c.Header("Last-Modified", modifiedAt.UTC().Format(http.TimeFormat))
+	}
+	if notModified(c.Request, etag, modifiedAt) {
+		c.Status(304)
+		return
+	}
+	c.Data(200, "application/json; charset=utf-8", data)
+}
+
+// notModified evaluates If-None-Match and, only without it, as RFC 7232
+// asks, If-Modified-Since.
+func notModified(r *http.Request, etag string, modifiedAt time.Time) bool {
+	if match := r.Header.Get("If-None-Match"); match != "" {
+		for _, candidate := range strings.Split(match, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == etag || candidate == "*" {
+				return true
+			}
+		}
+		return false
+	}
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil || modifiedAt.IsZero() {
+		return false
+	}
+	return !modifiedAt.Truncate(time.Second).After(since)
+}
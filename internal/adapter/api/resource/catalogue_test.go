@@ -0,0 +1,150 @@
+package resource
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"verkaufsautomat/internal/config"
+	"verkaufsautomat/internal/core/domain/resource"
+	"verkaufsautomat/internal/core/events"
+	services "verkaufsautomat/internal/core/services/mock"
+)
+
+func TestApplication_Catalogue(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockedService := services.NewMockMachineService(ctrl)
+	acceptTokens(mockedService)
+	handler := NewHTTPHandler(mockedService, events.NewBus(), testTokens, config.Default().HTTP)
+
+	router := gin.Default()
+
+	handler.Routes(router)
+
+	modifiedAt := time.Date(2024, 3, 1, 12, 30, 15, 500, time.UTC)
+	catalogue := resource.Catalogue{
+		Products: []resource.CatalogueProduct{
+			resource.CatalogueProductOf(resource.Product{ProductID: 1, ProductName: "Cola", Cost: 150, AmountAvailable: 3, SellerID: 2, Categories: []resource.Category{{CategoryID: 4, Name: "drinks"}}, Tags: resource.StringList{"fizzy"}}),
+			resource.CatalogueProductOf(resource.Product{ProductID: 2, ProductName: "Mate", Cost: 200, SellerID: 2}),
+		},
+		ModifiedAt: modifiedAt,
+	}
+
+	serve := func(method, path string, headers map[string]string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest(method, path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, req)
+		return response
+	}
+
+	first := func() *httptest.ResponseRecorder {
+		mockedService.EXPECT().GetCatalogue(gomock.Any(), gomock.Any()).Return(catalogue, nil)
+		return serve("GET", "/api/v2/catalogue", nil)
+	}
+
+	t.Run("Anyone can list the catalogue", func(t *testing.T) {
+		response := first()
+
+		if response.Code != http.StatusOK {
+			t.Fatalf("Expected status code %d, got %d", http.StatusOK, response.Code)
+		}
+		expected := `[{"product_id":1,"product_name":"Cola","cost":150,"in_stock":true,"categories":["drinks"],"tags":["fizzy"],"attributes":{"allergens":[]}},` +
+			`{"product_id":2,"product_name":"Mate","cost":200,"in_stock":false,"categories":[],"tags":[],"attributes":{"allergens":[]}}]`
+		if response.Body.String() != expected {
+			t.Errorf("Expected %s, got %s", expected, response.Body.String())
+		}
+		if response.Header().Get("ETag") == "" {
+			t.Error("Expected an ETag")
+		}
+		if lastModified := response.Header().Get("Last-Modified"); lastModified != "Fri, 01 Mar 2024 12:30:15 GMT" {
+			t.Errorf("Unexpected Last-Modified %s", lastModified)
+		}
+		if cacheControl := response.Header().Get("Cache-Control"); cacheControl != "public, max-age=60" {
+			t.Errorf("Unexpected Cache-Control %s", cacheControl)
+		}
+	})
+
+	t.Run("Current copies are not sent again", func(t *testing.T) {
+		etag := first().Header().Get("ETag")
+
+		for _, headers := range []map[string]string{
+			{"If-None-Match": etag},
+			{"If-None-Match": `"other", W/` + etag},
+			{"If-Modified-Since": "Fri, 01 Mar 2024 12:30:15 GMT"},
+		} {
+			mockedService.EXPECT().GetCatalogue(gomock.Any(), gomock.Any()).Return(catalogue, nil)
+
+			response := serve("GET", "/api/v2/catalogue", headers)
+
+			if response.Code != http.StatusNotModified {
+				t.Errorf("Expected status code %d for %v, got %d", http.StatusNotModified, headers, response.Code)
+			}
+			if response.Body.Len() != 0 || response.Header().Get("ETag") != etag {
+				t.Errorf("Expected no body and the ETag for %v", headers)
+			}
+		}
+	})
+
+	t.Run("Stale copies are replaced", func(t *testing.T) {
+		for _, headers := range []map[string]string{
+			{"If-None-Match": `"other"`},
+			{"If-Modified-Since": "Fri, 01 Mar 2024 12:30:14 GMT"},
+			// If-None-Match wins over If-Modified-Since.
+			{"If-None-Match": `"other"`, "If-Modified-Since": "Fri, 01 Mar 2024 12:30:15 GMT"},
+		} {
+			mockedService.EXPECT().GetCatalogue(gomock.Any(), gomock.Any()).Return(catalogue, nil)
+
+			response := serve("GET", "/api/v2/catalogue", headers)
+
+			if response.Code != http.StatusOK {
+				t.Errorf("Expected status code %d for %v, got %d", http.StatusOK, headers, response.Code)
+			}
+		}
+	})
+
+	t.Run("Products", func(t *testing.T) {
+		mockedService.EXPECT().GetCatalogueProduct(gomock.Any(), 1).Return(catalogue.Products[0], modifiedAt, nil)
+
+		response := serve("GET", "/api/v2/catalogue/1", nil)
+
+		if response.Code != http.StatusOK {
+			t.Fatalf("Expected status code %d, got %d", http.StatusOK, response.Code)
+		}
+		if strings.Contains(response.Body.String(), "seller_id") || strings.Contains(response.Body.String(), "amount_available") {
+			t.Errorf("Expected no seller fields, got %s", response.Body.String())
+		}
+
+		mockedService.EXPECT().GetCatalogueProduct(gomock.Any(), 9).Return(resource.CatalogueProduct{}, time.Time{}, resource.ErrProductNotFound)
+
+		if response := serve("GET", "/api/v2/catalogue/9", nil); response.Code != http.StatusNotFound {
+			t.Errorf("Expected status code %d, got %d", http.StatusNotFound, response.Code)
+		}
+		if response := serve("GET", "/api/v2/catalogue/cola", nil); response.Code != http.StatusBadRequest {
+			t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, response.Code)
+		}
+	})
+
+	t.Run("HEAD and other origins", func(t *testing.T) {
+		mockedService.EXPECT().GetCatalogue(gomock.Any(), gomock.Any()).Return(catalogue, nil)
+
+		response := serve("HEAD", "/api/v2/catalogue", map[string]string{"Origin": "https://display.example.com"})
+
+		if response.Code != http.StatusOK {
+			t.Fatalf("Expected status code %d, got %d", http.StatusOK, response.Code)
+		}
+		if origin := response.Header().Get("Access-Control-Allow-Origin"); origin != "*" {
+			t.Errorf("Expected the public CORS policy, got %q", origin)
+		}
+	})
+}
@@ -0,0 +1,167 @@
+package resource
+
+import (
+	"github.com/gin-gonic/gin"
+	"strconv"
+	"strings"
+	models "verkaufsautomat/internal/core/domain/resource"
+)
+
+type categoryRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// productFilter reads the filter from the query string: category, tag and
+// without_allergen, which may be repeated or comma separated, vegan,
+// max_calories, min_volume_ml and max_volume_ml.
+func productFilter(c *gin.Context) (models.ProductFilter, bool) {
+	filter := models.ProductFilter{
+		Category:         strings.ToLower(strings.TrimSpace(c.Query("category"))),
+		Tags:             queryList(c, "tag"),
+		WithoutAllergens: queryList(c, "without_allergen"),
+	}
+	for _, allergen := range filter.WithoutAllergens {
+		if !models.KnownAllergen(allergen) {
+			requestLogger(c).Error("Unknown allergen " + allergen)
+			c.AbortWithStatusJSON(400, gin.H{"error": "unknown allergen " + allergen})
+			return filter, false
+		}
+	}
+
+	var err error
+	if value := c.Query("vegan"); value != "" {
+		var vegan bool
+		vegan, err = strconv.ParseBool(value)
+		filter.Vegan = &vegan
+	}
+	for name, field := range map[string]**int{"max_calories": &filter.MaxCalories, "min_volume_ml": &filter.MinVolumeML, "max_volume_ml": &filter.MaxVolumeML} {
+		if value := c.Query(name); value != "" && err == nil {
+			var number int
+			number, err = strconv.Atoi(value)
+			*field = &number
+		}
+	}
+	if err != nil {
+		requestLogger(c).Error("Error parsing product filter: " + err.Error())
+		c.AbortWithStatusJSON(400, gin.H{"error": err.Error()})
+		return filter, false
+	}
+	return filter, true
+}
+
+// queryList collects the lower-cased values of a query parameter that may
+// be repeated or comma separated.
+func queryList(c *gin.Context, name string) []string {
+	var values []string
+	for _, value := range c.QueryArray(name) {
+		for _, entry := range strings.Split(value, ",") {
+			if entry = strings.ToLower(strings.TrimSpace(entry)); entry != "" {
+				values = append(values, entry)
+			}
+		}
+	}
+	return values
+}
+
+func (s *HTTPHandler) GetCategories(c *gin.Context) {
+	categories, err := s.MachineService.GetCategories(c.Request.Context())
+	if err != nil {
+		requestLogger(c).Error("Error getting categories: " + err.Error())
+		abortWithError(c, err)
+		return
+	}
+
+	if categories == nil {
+		categories = []models.Category{}
+	}
+	c.JSON(200, categories)
+}
+
+func (s *HTTPHandler) GetCategory(c *gin.Context) {
+	id, ok := idParam(c)
+	if !ok {
+		return
+	}
+
+	category, err := s.MachineService.GetCategoryById(c.Request.Context(), id)
+	if err != nil {
+		requestLogger(c).Error("Error getting category: " + err.Error())
+		abortWithError(c, err)
+		return
+	}
+	c.JSON(200, category)
+}
+
+func (s *HTTPHandler) CreateCategory(c *gin.Context) {
+	var request categoryRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		requestLogger(c).Error("Error binding json: " + err.Error())
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	category := models.Category{Name: request.Name}
+	if err := s.MachineService.CreateCategory(c.Request.Context(), &category); err != nil {
+		requestLogger(c).Error("Error creating category: " + err.Error())
+		abortWithError(c, err)
+		return
+	}
+
+	audit(c, strconv.Itoa(int(category.CategoryID)), nil, category)
+	c.Header("Location", "/api/v2/categories/"+strconv.Itoa(int(category.CategoryID)))
+	c.JSON(201, category)
+}
+
+// UpdateCategory renames a category, which applies to all its products.
+func (s *HTTPHandler) UpdateCategory(c *gin.Context) {
+	var request categoryRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		requestLogger(c).Error("Error binding json: " + err.Error())
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	id, ok := idParam(c)
+	if !ok {
+		return
+	}
+
+	previous, err := s.MachineService.GetCategoryById(c.Request.Context(), id)
+	if err != nil {
+		requestLogger(c).Error("Error getting category: " + err.Error())
+		abortWithError(c, err)
+		return
+	}
+	category := previous
+	category.Name = request.Name
+	if err := s.MachineService.UpdateCategory(c.Request.Context(), &category); err != nil {
+		requestLogger(c).Error("Error updating category: " + err.Error())
+		abortWithError(c, err)
+		return
+	}
+
+	audit(c, strconv.Itoa(id), previous, category)
+	c.JSON(200, category)
+}
+
+// DeleteCategory deletes a category that no product is in.
+func (s *HTTPHandler) DeleteCategory(c *gin.Context) {
+	id, ok := idParam(c)
+	if !ok {
+		return
+	}
+
+	previous, err := s.MachineService.GetCategoryById(c.Request.Context(), id)
+	if err != nil {
+		requestLogger(c).Error("Error getting category: " + err.Error())
+		abortWithError(c, err)
+		return
+	}
+	if err := s.MachineService.DeleteCategory(c.Request.Context(), id); err != nil {
+		requestLogger(c).Error("Error deleting category: " + err.Error())
+		abortWithError(c, err)
+		return
+	}
+
+	audit(c, strconv.Itoa(id), previous, nil)
+	c.Status(204)
+}
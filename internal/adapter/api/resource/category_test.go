@@ -0,0 +1,185 @@
+package resource
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	"net/http"
+	"strings"
+	"testing"
+	"verkaufsautomat/internal/core/domain/resource"
+)
+
+func TestApplication_Categories(t *testing.T) {
+	mockedService, serve := newTestServer(t)
+
+	admin, _ := testTokens.Generate(&resource.User{UserID: 3, RoleID: resource.AdminRoleID, Username: "root"})
+	seller, _ := testTokens.Generate(&resource.User{UserID: 2, RoleID: resource.SellerRoleID, Username: "sam"})
+	buyer, _ := testTokens.Generate(&resource.User{UserID: 1, RoleID: resource.BuyerRoleID, Username: "bob"})
+	drinks := resource.Category{CategoryID: 4, Name: "drinks"}
+
+	recordAudit := func() *resource.AuditEntry {
+		entry := &resource.AuditEntry{}
+		mockedService.EXPECT().RecordAudit(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, recorded *resource.AuditEntry) error {
+			*entry = *recorded
+			return nil
+		})
+		return entry
+	}
+
+	t.Run("Admins create categories", func(t *testing.T) {
+		mockedService.EXPECT().CreateCategory(gomock.Any(), &resource.Category{Name: "Drinks"}).DoAndReturn(func(_ context.Context, category *resource.Category) error {
+			*category = drinks
+			return nil
+		})
+		entry := recordAudit()
+
+		response := serve("POST", "/api/v2/categories", admin, `{"name":"Drinks"}`)
+
+		if response.Code != http.StatusCreated {
+			t.Fatalf("Expected status code %d, got %d", http.StatusCreated, response.Code)
+		}
+		if location := response.Header().Get("Location"); location != "/api/v2/categories/4" {
+			t.Errorf("Unexpected Location %s", location)
+		}
+		if entry.Action != resource.AuditCategoryCreate || entry.TargetType != "category" || entry.TargetID != "4" {
+			t.Errorf("Unexpected audit entry %+v", entry)
+		}
+	})
+
+	t.Run("Sellers and buyers cannot", func(t *testing.T) {
+		for _, token := range []string{seller, buyer} {
+			recordAudit()
+			recordAudit()
+
+			if response := serve("POST", "/api/v2/categories", token, `{"name":"drinks"}`); response.Code != http.StatusForbidden {
+				t.Errorf("Expected status code %d, got %d", http.StatusForbidden, response.Code)
+			}
+			if response := serve("DELETE", "/api/v2/categories/4", token, ""); response.Code != http.StatusForbidden {
+				t.Errorf("Expected status code %d, got %d", http.StatusForbidden, response.Code)
+			}
+		}
+	})
+
+	t.Run("Invalid and taken names", func(t *testing.T) {
+		for err, status := range map[error]int{resource.ErrInvalidCategory: http.StatusBadRequest, resource.ErrCategoryExists: http.StatusConflict} {
+			mockedService.EXPECT().CreateCategory(gomock.Any(), gomock.Any()).Return(err)
+			recordAudit()
+
+			if response := serve("POST", "/api/v2/categories", admin, `{"name":"drinks"}`); response.Code != status {
+				t.Errorf("Expected status code %d for %v, got %d", status, err, response.Code)
+			}
+		}
+	})
+
+	t.Run("Rename", func(t *testing.T) {
+		mockedService.EXPECT().GetCategoryById(gomock.Any(), 4).Return(drinks, nil)
+		mockedService.EXPECT().UpdateCategory(gomock.Any(), &resource.Category{CategoryID: 4, Name: "beverages"}).Return(nil)
+		entry := recordAudit()
+
+		response := serve("PUT", "/api/v2/categories/4", admin, `{"name":"beverages"}`)
+
+		if response.Code != http.StatusOK {
+			t.Fatalf("Expected status code %d, got %d", http.StatusOK, response.Code)
+		}
+		if !strings.Contains(entry.Before, "drinks") || !strings.Contains(entry.After, "beverages") {
+			t.Errorf("Unexpected audit entry %+v", entry)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		for _, test := range []struct {
+			err    error
+			status int
+		}{
+			{nil, http.StatusNoContent},
+			{resource.ErrCategoryInUse, http.StatusConflict},
+		} {
+			mockedService.EXPECT().GetCategoryById(gomock.Any(), 4).Return(drinks, nil)
+			mockedService.EXPECT().DeleteCategory(gomock.Any(), 4).Return(test.err)
+			recordAudit()
+
+			if response := serve("DELETE", "/api/v2/categories/4", admin, ""); response.Code != test.status {
+				t.Errorf("Expected status code %d, got %d", test.status, response.Code)
+			}
+		}
+	})
+
+	t.Run("Anyone can list the categories of the catalogue", func(t *testing.T) {
+		mockedService.EXPECT().GetCategories(gomock.Any()).Return([]resource.Category{drinks}, nil)
+
+		response := serve("GET", "/api/v2/catalogue/categories", "", "")
+
+		if response.Code != http.StatusOK {
+			t.Fatalf("Expected status code %d, got %d", http.StatusOK, response.Code)
+		}
+		if response.Body.String() != `[{"category_id":4,"name":"drinks"}]` || response.Header().Get("ETag") == "" {
+			t.Errorf("Unexpected categories %s", response.Body.String())
+		}
+	})
+
+	t.Run("Products are created with categories, tags and attributes", func(t *testing.T) {
+		volume := 330
+		expected := &resource.Product{
+			ProductName:     "Cola",
+			Cost:            150,
+			AmountAvailable: 5,
+			SellerID:        2,
+			Categories:      []resource.Category{{CategoryID: 4}},
+			Tags:            resource.StringList{"Fizzy"},
+			Attributes:      resource.Attributes{VolumeML: &volume, Allergens: resource.StringList{}},
+		}
+		mockedService.EXPECT().CreateProduct(gomock.Any(), expected).Return(nil)
+		recordAudit()
+
+		response := serve("POST", "/api/v2/products", seller, `{"product_name":"Cola","cost":150,"amount_available":5,"category_ids":[4],"tags":["Fizzy"],"attributes":{"volume_ml":330,"allergens":[]}}`)
+
+		if response.Code != http.StatusCreated {
+			t.Errorf("Expected status code %d, got %d", http.StatusCreated, response.Code)
+		}
+
+		mockedService.EXPECT().CreateProduct(gomock.Any(), gomock.Any()).Return(resource.ErrInvalidAttribute)
+		recordAudit()
+
+		response = serve("POST", "/api/v2/products", seller, `{"product_name":"Cola","cost":150,"attributes":{"allergens":["chocolate"]}}`)
+
+		if response.Code != http.StatusBadRequest {
+			t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, response.Code)
+		}
+	})
+}
+
+func TestApplication_ProductFilter(t *testing.T) {
+	mockedService, serve := newTestServer(t)
+
+	buyer, _ := testTokens.Generate(&resource.User{UserID: 1, RoleID: resource.BuyerRoleID, Username: "bob"})
+
+	t.Run("The query selects products", func(t *testing.T) {
+		vegan, calories, volume := true, 200, 500
+		filter := resource.ProductFilter{
+			Category:         "drinks",
+			Tags:             []string{"fizzy", "sugar-free", "cold"},
+			Vegan:            &vegan,
+			MaxCalories:      &calories,
+			MaxVolumeML:      &volume,
+			WithoutAllergens: []string{"milk", "nuts"},
+		}
+		mockedService.EXPECT().SearchProducts(gomock.Any(), filter).Return(nil, nil)
+
+		response := serve("GET", "/api/v2/products?category=Drinks&tag=fizzy,Sugar-Free&tag=cold&vegan=true&max_calories=200&max_volume_ml=500&without_allergen=milk&without_allergen=nuts", buyer, "")
+
+		if response.Code != http.StatusOK {
+			t.Fatalf("Expected status code %d, got %d", http.StatusOK, response.Code)
+		}
+		if response.Body.String() != "[]" {
+			t.Errorf("Expected an empty list, got %s", response.Body.String())
+		}
+	})
+
+	t.Run("Malformed filters", func(t *testing.T) {
+		for _, query := range []string{"vegan=maybe", "max_calories=lots", "without_allergen=chocolate"} {
+			if response := serve("GET", "/api/v2/products?"+query, buyer, ""); response.Code != http.StatusBadRequest {
+				t.Errorf("Expected status code %d for %s, got %d", http.StatusBadRequest, query, response.Code)
+			}
+		}
+	})
+}
@@ -0,0 +1,96 @@
+package resource
+
+import (
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+	"strings"
+)
+
+// originMatcher matches Origin headers against an allow-list of origins.
+// Entries whose host starts with "*." match any subdomain of the rest, on
+// the same scheme and port, and "*" matches every origin.
+type originMatcher struct {
+	any      bool
+	origins  map[string]bool
+	patterns []originPattern
+}
+
+type originPattern struct {
+	// scheme includes "://" and suffix the "." before the domain and the
+	// port, if any.
+	scheme string
+	suffix string
+}
+
+func newOriginMatcher(origins []string) originMatcher {
+	matcher := originMatcher{origins: map[string]bool{}}
+	for _, origin := range origins {
+		origin = strings.ToLower(strings.TrimSpace(origin))
+		if origin == "*" {
+			matcher.any = true
+			continue
+		}
+		if i := strings.Index(origin, "://*."); i >= 0 {
+			matcher.patterns = append(matcher.patterns, originPattern{scheme: origin[:i+3], suffix: origin[i+4:]})
+			continue
+		}
+		matcher.origins[origin] = true
+	}
+	return matcher
+}
+
+func (m originMatcher) allows(origin string) bool {
+	origin = strings.ToLower(origin)
+	if m.any || m.origins[origin] {
+		return true
+	}
+	for _, pattern := range m.patterns {
+		if !strings.HasPrefix(origin, pattern.scheme) || !strings.HasSuffix(origin, pattern.suffix) {
+			continue
+		}
+		subdomain := strings.TrimSuffix(strings.TrimPrefix(origin, pattern.scheme), pattern.suffix)
+		if subdomain != "" && !strings.ContainsAny(subdomain, "/:@") {
+			return true
+		}
+	}
+	return false
+}
+
+// CORS applies the public policy to requests for the public paths and
+// everything below them, and the credentialed policy to all others. Only
+// CORSOrigins may send credentials, while CORSPublicOrigins may only read.
+// Policies are chosen by path rather than route group because preflight
+// requests match no route.
+func (s *HTTPHandler) CORS(public ...string) gin.HandlerFunc {
+	credentialed := cors.New(cors.Config{
+		AllowOriginFunc:  newOriginMatcher(s.Config.CORSOrigins).allows,
+		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "PATCH", "OPTIONS"},
+		AllowHeaders:     []string{"Origin", "Content-Length", "Content-Type", "Authorization", CSRFHeader},
+		ExposeHeaders:    []string{"Content-Length", RequestIDHeader},
+		AllowCredentials: true,
+		MaxAge:           s.Config.CORSMaxAge,
+	})
+
+	publicConfig := cors.Config{
+		AllowMethods:  []string{"GET", "HEAD", "OPTIONS"},
+		AllowHeaders:  []string{"Origin", "Content-Type"},
+		ExposeHeaders: []string{"Content-Length", "ETag", RequestIDHeader},
+		MaxAge:        s.Config.CORSMaxAge,
+	}
+	if origins := newOriginMatcher(s.Config.CORSPublicOrigins); origins.any {
+		publicConfig.AllowAllOrigins = true
+	} else {
+		publicConfig.AllowOriginFunc = origins.allows
+	}
+	publicPolicy := cors.New(publicConfig)
+
+	return func(c *gin.Context) {
+		for _, path := range public {
+			if c.Request.URL.Path == path || strings.HasPrefix(c.Request.URL.Path, path+"/") {
+				publicPolicy(c)
+				return
+			}
+		}
+		credentialed(c)
+	}
+}
@@ -0,0 +1,111 @@
+package resource
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"verkaufsautomat/internal/config"
+	"verkaufsautomat/internal/core/events"
+	services "verkaufsautomat/internal/core/services/mock"
+)
+
+func TestOriginMatcher(t *testing.T) {
+	matcher := newOriginMatcher([]string{"https://shop.example.com", "https://*.example.org", "http://*.local.test:8080"})
+
+	for origin, allowed := range map[string]bool{
+		"https://shop.example.com":         true,
+		"https://SHOP.example.com":         true,
+		"http://shop.example.com":          false,
+		"https://evil.example.com":         false,
+		"https://a.example.org":            true,
+		"https://a.b.example.org":          true,
+		"https://example.org":              false,
+		"https://evilexample.org":          false,
+		"https://a.example.org:8443":       false,
+		"https://evil.com/.example.org":    false,
+		"http://ui.local.test:8080":        true,
+		"http://ui.local.test":             false,
+		"https://shop.example.com.evil.io": false,
+	} {
+		if matcher.allows(origin) != allowed {
+			t.Errorf("Expected %s allowed to be %v", origin, allowed)
+		}
+	}
+
+	if !newOriginMatcher([]string{"*"}).allows("https://anywhere.io") {
+		t.Error("Expected * to allow every origin")
+	}
+}
+
+func TestApplication_CORS(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	settings := config.Default().HTTP
+	settings.CORSOrigins = []string{"https://shop.example.com", "https://*.example.org"}
+	handler := NewHTTPHandler(services.NewMockMachineService(ctrl), events.NewBus(), testTokens, settings)
+
+	router := gin.Default()
+
+	handler.Routes(router)
+
+	preflight := func(path, origin string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest("OPTIONS", path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", "POST")
+		req.Header.Set("Access-Control-Request-Headers", "Content-Type, X-CSRF-Token")
+
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, req)
+		return response
+	}
+
+	t.Run("Allowed origins may send credentials", func(t *testing.T) {
+		for _, origin := range []string{"https://shop.example.com", "https://admin.example.org"} {
+			response := preflight("/api/v2/orders", origin)
+
+			if response.Code != http.StatusNoContent {
+				t.Fatalf("Expected status code %d, got %d", http.StatusNoContent, response.Code)
+			}
+			if got := response.Header().Get("Access-Control-Allow-Origin"); got != origin {
+				t.Errorf("Expected origin %s to be allowed, got %q", origin, got)
+			}
+			if response.Header().Get("Access-Control-Allow-Credentials") != "true" {
+				t.Error("Expected credentials to be allowed")
+			}
+		}
+	})
+
+	t.Run("Other origins are refused", func(t *testing.T) {
+		response := preflight("/api/v1/login", "https://evil.example.com")
+
+		if response.Code != http.StatusForbidden {
+			t.Errorf("Expected status code %d, got %d", http.StatusForbidden, response.Code)
+		}
+	})
+
+	t.Run("Public routes are readable from anywhere without credentials", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/livez", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Origin", "https://evil.example.com")
+
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, req)
+
+		if response.Code != http.StatusOK {
+			t.Fatalf("Expected status code %d, got %d", http.StatusOK, response.Code)
+		}
+		if got := response.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+			t.Errorf("Expected every origin to be allowed, got %q", got)
+		}
+		if response.Header().Get("Access-Control-Allow-Credentials") != "" {
+			t.Error("Expected no credentials on public routes")
+		}
+	})
+}
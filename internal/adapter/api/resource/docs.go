@@ -0,0 +1,20 @@
+package resource
+
+import (
+	_ "embed"
+	"github.com/gin-gonic/gin"
+)
+
+//go:embed openapi.json
+var openAPISpec []byte
+
+//go:embed docs.html
+var docsPage []byte
+
+func (s *HTTPHandler) OpenAPI(c *gin.Context) {
+	c.Data(200, "application/json; charset=utf-8", openAPISpec)
+}
+
+func (s *HTTPHandler) Docs(c *gin.Context) {
+	c.Data(200, "text/html; charset=utf-8", docsPage)
+}
@@ -0,0 +1,128 @@
+package resource
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"time"
+	"verkaufsautomat/internal/core/events"
+)
+
+const eventsHeartbeat = 15 * time.Second
+
+type stockEvent struct {
+	ProductID       uint `json:"product_id"`
+	AmountAvailable int  `json:"amount_available"`
+}
+
+type priceEvent struct {
+	ProductID uint `json:"product_id"`
+	Cost      int  `json:"cost"`
+}
+
+type balanceEvent struct {
+	Deposit int `json:"deposit"`
+}
+
+// Names of the server-sent events. They are part of the public API and do
+// not follow the internal domain event names.
+const (
+	stockChangedEvent   = "stock_changed"
+	priceChangedEvent   = "price_changed"
+	productDeletedEvent = "product_deleted"
+	balanceChangedEvent = "balance_changed"
+)
+
+// eventData returns the name and payload sent for a domain event, or false
+// when the event must not be sent to the given user.
+func eventData(event events.Event, userID int) (string, interface{}, bool) {
+	switch event := event.(type) {
+	case events.ProductCreated:
+		return stockChangedEvent, stockEvent{ProductID: event.Product.ProductID, AmountAvailable: event.Product.AmountAvailable}, true
+	case events.StockChanged:
+		return stockChangedEvent, stockEvent{ProductID: event.Product.ProductID, AmountAvailable: event.Product.AmountAvailable}, true
+	case events.ProductDeleted:
+		return productDeletedEvent, stockEvent{ProductID: event.Product.ProductID}, true
+	case events.PriceChanged:
+		return priceChangedEvent, priceEvent{ProductID: event.Product.ProductID, Cost: event.Product.Cost}, true
+	case events.DepositMade:
+		return balanceChangedEvent, balanceEvent{Deposit: event.Deposit}, event.UserID == userID
+	case events.DepositReset:
+		return balanceChangedEvent, balanceEvent{Deposit: 0}, event.UserID == userID
+	case events.PurchaseCompleted:
+		return balanceChangedEvent, balanceEvent{Deposit: event.RemainingDeposit}, int(event.Order.BuyerID) == userID
+	}
+	return "", nil, false
+}
+
+func writeEvent(c *gin.Context, name string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(c.Writer, "event: %s\ndata: %s\n\n", name, payload); err != nil {
+		return err
+	}
+	c.Writer.Flush()
+	return nil
+}
+
+// StreamEvents streams stock, price and balance changes as server-sent events.
+// Balance changes are only sent to the user they belong to.
+func (s *HTTPHandler) StreamEvents(c *gin.Context) {
+	userID, _ := currentUser(c)
+	subscription, cancel := s.Events.Stream(64,
+		events.ProductCreatedName,
+		events.StockChangedName,
+		events.ProductDeletedName,
+		events.PriceChangedName,
+		events.DepositMadeName,
+		events.DepositResetName,
+		events.PurchaseCompletedName,
+	)
+	defer cancel()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(200)
+
+	user, err := s.MachineService.GetUserById(c.Request.Context(), userID)
+	if err == nil {
+		err = writeEvent(c, balanceChangedEvent, balanceEvent{Deposit: user.Deposit})
+	}
+	if err != nil {
+		requestLogger(c).Error("Error starting event stream: " + err.Error())
+		return
+	}
+
+	heartbeat := time.NewTicker(eventsHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-s.closing:
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(c.Writer, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		case event, ok := <-subscription:
+			if !ok {
+				return
+			}
+			name, data, send := eventData(event, userID)
+			if !send {
+				continue
+			}
+			if err := writeEvent(c, name, data); err != nil {
+				requestLogger(c).Error("Error writing event: " + err.Error())
+				return
+			}
+		}
+	}
+}
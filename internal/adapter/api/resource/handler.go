@@ -2,14 +2,9 @@ package resource
 
 import (
 	"errors"
-	"fmt"
-	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
-	"golang.org/x/crypto/bcrypt"
 	"strconv"
 	"strings"
-	"time"
-	"verkaufsautomat/internal/adapter/repositories/mysql/resource"
 	models "verkaufsautomat/internal/core/domain/resource"
 	"verkaufsautomat/internal/core/logger"
 )
@@ -19,7 +14,7 @@ type HealthCheckResponse struct {
 }
 
 func insertCoin(coin int) (bool, error) {
-	if coin == 5 || coin == 10 || coin == 20 || coin == 50 || coin == 100 {
+	if models.IsValidCoin(coin) {
 		logger.Info("Coin inserted")
 		return true, nil
 	}
@@ -28,46 +23,36 @@ func insertCoin(coin int) (bool, error) {
 }
 
 func (s *HTTPHandler) HealthCheck(c *gin.Context) {
-	logger.Info("HealthCheck called")
+	requestLogger(c).Info("HealthCheck called")
+	if _, ready := s.readiness(c); !ready {
+		c.JSON(503, HealthCheckResponse{Status: "UNAVAILABLE"})
+		return
+	}
 	c.JSON(200, HealthCheckResponse{Status: "OK"})
 }
 
-func TokenValid(c *gin.Context) error {
-	tokenString := c.Request.Header.Get("Authorization")
-	tokenString = strings.Replace(tokenString, "Bearer ", "", 1)
-	token, err := verifyToken(tokenString)
-	if err != nil {
-		logger.Error("Error verifying token: " + err.Error())
-		return err
-	}
-
-	if !isTokenValid(token) {
-		logger.Error("Token is not valid")
-		return err
-	}
+func (s *HTTPHandler) TokenValid(c *gin.Context) error {
+	credential, _ := sessionCredential(c)
+	_, err := s.MachineService.AuthenticateToken(c.Request.Context(), credential)
+	return err
+}
 
-	return nil
+// bearerCredential returns the token or API key from the Authorization
+// header.
+func bearerCredential(c *gin.Context) string {
+	return strings.Replace(c.Request.Header.Get("Authorization"), "Bearer ", "", 1)
 }
 
 func (s *HTTPHandler) Register(c *gin.Context) {
 	var user models.User
 	if err := c.ShouldBindJSON(&user); err != nil {
-		logger.Error("Error binding json: " + err.Error())
+		requestLogger(c).Error("Error binding json: " + err.Error())
 		c.JSON(400, gin.H{"error": err.Error()})
 		return
 	}
 
-	hashPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
-	if err != nil {
-		logger.Error("Error hashing password: " + err.Error())
-		c.JSON(500, gin.H{"error": err.Error()})
-		return
-	}
-
-	user.Password = string(hashPassword)
-
-	if err := s.MachineService.Register(&user); err != nil {
-		logger.Error("Error registering user: " + err.Error())
+	if err := s.MachineService.Register(c.Request.Context(), &user); err != nil {
+		requestLogger(c).Error("Error registering user: " + err.Error())
 		c.JSON(400, gin.H{"error": err.Error()})
 		return
 	}
@@ -76,131 +61,88 @@ func (s *HTTPHandler) Register(c *gin.Context) {
 }
 
 func (s *HTTPHandler) Login(c *gin.Context) {
-	var user models.User
-	if err := c.ShouldBindJSON(&user); err != nil {
-		logger.Error("Error binding json: " + err.Error())
+	var credentials models.User
+	if err := c.ShouldBindJSON(&credentials); err != nil {
+		requestLogger(c).Error("Error binding json: " + err.Error())
 		c.JSON(400, gin.H{"error": err.Error()})
 		return
 	}
 
-	userid, roleid, err := resource.NewMachineRepositoryDB().GetUserIdAndRoleId(user.Username)
-	if err != nil {
-		return
-	}
-
-	user.UserID = userid
-	user.RoleID = roleid
-
-	token, err := generateToken(&user)
-	if err != nil {
-		logger.Error("Error generating token: " + err.Error())
-		c.JSON(500, gin.H{"error": err.Error()})
+	// The user is returned even when the password is wrong, so that failed
+	// attempts on an existing account are attributed to it.
+	user, token, err := s.MachineService.Login(c.Request.Context(), credentials.Username, credentials.Password)
+	auditActor(c, user)
+	if errors.Is(err, models.ErrInvalidCredentials) {
+		requestLogger(c).Warn("Error logging in: " + err.Error())
+		c.JSON(401, gin.H{"error": models.ErrInvalidCredentials.Error()})
 		return
 	}
-
-	c.Header("Authorization", "Bearer "+token)
-
-	c.SetCookie("token", token, 3600, "/", "localhost", false, true)
-
-	if err := s.MachineService.Login(&user); err != nil {
-		logger.Error("Error logging in: " + err.Error())
-		c.JSON(400, gin.H{"error": err.Error()})
+	if errors.Is(err, models.ErrMFARequired) {
+		audit(c, strconv.Itoa(int(user.UserID)), nil, nil)
+		c.JSON(202, mfaChallengeResponse{Message: err.Error(), MFARequired: true, ChallengeToken: token})
 		return
 	}
-
-	c.JSON(200, gin.H{"message": "user logged in", "token": token})
-}
-
-func generateToken(user *models.User) (string, error) {
-
-	t := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"username": user.Username,
-		"user_id":  user.UserID,
-		"role_id":  user.RoleID,
-		"exp":      time.Now().Add(time.Hour * 1).Unix(),
-	})
-
-	tokenString, err := t.SignedString([]byte("secret"))
 	if err != nil {
-		logger.Error("Error signing token: " + err.Error())
-		return "", err
+		requestLogger(c).Error("Error logging in: " + err.Error())
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
 	}
 
-	return tokenString, nil
+	s.startSession(c, user, token, nil)
 }
 
-func verifyToken(tokenString string) (*jwt.Token, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		// validate the alg is what we expect:
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("Unexpected signing method: %v", token.Header["alg"])
-		}
-
-		return []byte("secret"), nil
-	})
-
-	if err != nil {
-		return nil, err
-	}
-
-	return token, nil
+type loginResponse struct {
+	Message   string `json:"message"`
+	Token     string `json:"token"`
+	CSRFToken string `json:"csrf_token"`
+	// RecoveryCodes are only returned when the login completed enrolment
+	// in two-factor authentication.
+	RecoveryCodes []string `json:"recovery_codes,omitempty"`
 }
 
-func isTokenValid(token *jwt.Token) bool {
-	if _, ok := token.Claims.(jwt.Claims); !ok && !token.Valid {
-		return false
-	}
+// startSession hands out the login token, in the Authorization header, the
+// session cookies and the body.
+func (s *HTTPHandler) startSession(c *gin.Context, user models.User, token string, recoveryCodes []string) {
+	csrf := s.Tokens.CSRFToken(token)
+	c.Header("Authorization", "Bearer "+token)
+	s.setSessionCookies(c, token, csrf)
 
-	return true
+	audit(c, strconv.Itoa(int(user.UserID)), nil, nil)
+	c.JSON(200, loginResponse{Message: "user logged in", Token: token, CSRFToken: csrf, RecoveryCodes: recoveryCodes})
 }
 
 func (s *HTTPHandler) CreateProduct(c *gin.Context) {
 	var product models.Product
 	if err := c.ShouldBindJSON(&product); err != nil {
-		logger.Error("Error binding json: " + err.Error())
-		c.JSON(400, gin.H{"error": err.Error()})
-		return
-	}
-
-	tokenString := c.Request.Header.Get("Authorization")
-	if len(strings.Split(tokenString, " ")) != 2 {
-		logger.Error("Error getting token from header")
-		c.JSON(400, gin.H{"error": "Error getting token from header"})
-		return
-	}
-
-	token, err := verifyToken(strings.Split(tokenString, " ")[1])
-	if err != nil {
-		logger.Error("Error verifying token: " + err.Error())
+		requestLogger(c).Error("Error binding json: " + err.Error())
 		c.JSON(400, gin.H{"error": err.Error()})
 		return
 	}
 
-	claims := token.Claims.(jwt.MapClaims)
-	roleID := int(claims["role_id"].(float64))
-	userID := int(claims["user_id"].(float64))
+	userID, roleID := currentUser(c)
 
 	if roleID != 2 {
-		logger.Error("User cannot create product")
+		requestLogger(c).Error("User cannot create product")
 		c.JSON(400, gin.H{"error": "user cannot create product"})
 		return
 	}
 
 	product.SellerID = uint(userID)
 
-	if err := s.MachineService.CreateProduct(&product); err != nil {
-		logger.Error("Error creating product: " + err.Error())
+	if err := s.MachineService.CreateProduct(c.Request.Context(), &product); err != nil {
+		requestLogger(c).Error("Error creating product: " + err.Error())
 		c.JSON(400, gin.H{"error": err.Error()})
 		return
 	}
 
+	audit(c, strconv.Itoa(int(product.ProductID)), nil, product)
 	c.JSON(200, gin.H{"message": "product created"})
 }
 
 func (s *HTTPHandler) GetProducts(c *gin.Context) {
-	products, err := s.MachineService.GetProducts()
+	products, err := s.MachineService.GetProducts(c.Request.Context())
 	if err != nil {
-		logger.Error("Error getting products: " + err.Error())
+		requestLogger(c).Error("Error getting products: " + err.Error())
 		c.JSON(400, gin.H{"error": err.Error()})
 		return
 	}
@@ -212,13 +154,13 @@ func (s *HTTPHandler) GetProduct(c *gin.Context) {
 	id := c.Param("id")
 	atoi, err := strconv.Atoi(id)
 	if err != nil {
-		logger.Error("Error converting id to int: " + err.Error())
+		requestLogger(c).Error("Error converting id to int: " + err.Error())
 		return
 	}
 
-	product, err := s.MachineService.GetProductById(atoi)
+	product, err := s.MachineService.GetProductById(c.Request.Context(), atoi)
 	if err != nil {
-		logger.Error("Error getting product: " + err.Error())
+		requestLogger(c).Error("Error getting product: " + err.Error())
 		c.JSON(400, gin.H{"error": err.Error()})
 		return
 	}
@@ -231,49 +173,46 @@ func (s *HTTPHandler) UpdateProduct(c *gin.Context) {
 	id := c.Param("id")
 	atoi, err := strconv.Atoi(id)
 	if err != nil {
-		logger.Error("Error converting id to int: " + err.Error())
+		requestLogger(c).Error("Error converting id to int: " + err.Error())
 		return
 	}
 
 	var product models.Product
 	if err := c.ShouldBindJSON(&product); err != nil {
-		logger.Error("Error binding json: " + err.Error())
-		c.JSON(400, gin.H{"error": err.Error()})
-		return
-	}
-
-	tokenString := c.Request.Header.Get("Authorization")
-	if len(strings.Split(tokenString, " ")) != 2 {
-		logger.Error("Error getting token from header")
-		c.JSON(400, gin.H{"error": "Error getting token from header"})
-		return
-	}
-
-	token, err := verifyToken(strings.Split(tokenString, " ")[1])
-	if err != nil {
-		logger.Error("Error verifying token: " + err.Error())
+		requestLogger(c).Error("Error binding json: " + err.Error())
 		c.JSON(400, gin.H{"error": err.Error()})
 		return
 	}
 
-	claims := token.Claims.(jwt.MapClaims)
-	roleID := int(claims["role_id"].(float64))
-	userID := int(claims["user_id"].(float64))
+	userID, roleID := currentUser(c)
 
 	if roleID != 2 {
-		logger.Error("User cannot update product")
+		requestLogger(c).Error("User cannot update product")
 		c.JSON(400, gin.H{"error": "user cannot update product"})
 		return
 	}
 
 	product.SellerID = uint(userID)
 
-	if err := s.MachineService.UpdateProductByID(atoi, &product); err != nil {
-		logger.Error("Error updating product: " + err.Error())
+	previous, err := s.MachineService.GetProductById(c.Request.Context(), atoi)
+	if err != nil {
+		requestLogger(c).Error("Error getting product: " + err.Error())
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	// v1 clients do not know categories, tags and attributes, so they are
+	// kept as they are.
+	product.Categories = previous.Categories
+	product.Tags = previous.Tags
+	product.Attributes = previous.Attributes
+
+	if err := s.MachineService.UpdateProductByID(c.Request.Context(), atoi, &product); err != nil {
+		requestLogger(c).Error("Error updating product: " + err.Error())
 		c.JSON(400, gin.H{"error": err.Error()})
 		return
 	}
 
+	audit(c, id, previous, product)
 	c.JSON(200, gin.H{"message": "product updated"})
 }
 
@@ -281,39 +220,32 @@ func (s *HTTPHandler) DeleteProduct(c *gin.Context) {
 	id := c.Param("id")
 	atoi, err := strconv.Atoi(id)
 	if err != nil {
-		logger.Error("Error converting id to int: " + err.Error())
+		requestLogger(c).Error("Error converting id to int: " + err.Error())
 		return
 	}
 
-	tokenString := c.Request.Header.Get("Authorization")
-	if len(strings.Split(tokenString, " ")) != 2 {
-		logger.Error("Error getting token from header")
-		c.JSON(400, gin.H{"error": "Error getting token from header"})
+	_, roleID := currentUser(c)
+
+	if roleID != 2 {
+		requestLogger(c).Error("User cannot delete product")
+		c.JSON(400, gin.H{"error": "user cannot delete product"})
 		return
 	}
 
-	token, err := verifyToken(strings.Split(tokenString, " ")[1])
+	previous, err := s.MachineService.GetProductById(c.Request.Context(), atoi)
 	if err != nil {
-		logger.Error("Error verifying token: " + err.Error())
+		requestLogger(c).Error("Error getting product: " + err.Error())
 		c.JSON(400, gin.H{"error": err.Error()})
 		return
 	}
 
-	claims := token.Claims.(jwt.MapClaims)
-	roleID := int(claims["role_id"].(float64))
-
-	if roleID != 2 {
-		logger.Error("User cannot delete product")
-		c.JSON(400, gin.H{"error": "user cannot delete product"})
-		return
-	}
-
-	if err := s.MachineService.DeleteProductByID(atoi); err != nil {
-		logger.Error("Error deleting product: " + err.Error())
+	if err := s.MachineService.DeleteProductByID(c.Request.Context(), atoi); err != nil {
+		requestLogger(c).Error("Error deleting product: " + err.Error())
 		c.JSON(400, gin.H{"error": err.Error()})
 		return
 	}
 
+	audit(c, id, previous, nil)
 	c.JSON(200, gin.H{"message": "product deleted"})
 }
 
@@ -324,47 +256,35 @@ func (s *HTTPHandler) DepositMoney(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&deposit); err != nil {
-		logger.Error("Error binding json: " + err.Error())
+		requestLogger(c).Error("Error binding json: " + err.Error())
 		c.JSON(400, gin.H{"error": err.Error()})
 		return
 	}
 
 	_, err := insertCoin(deposit.Amount)
 	if err != nil {
-		logger.Error("Error inserting coin: " + err.Error())
-		c.JSON(400, gin.H{"error": err.Error()})
-		return
-	}
-
-	tokenString := c.Request.Header.Get("Authorization")
-	if len(strings.Split(tokenString, " ")) != 2 {
-		logger.Error("Error getting token from header")
-
-	}
-
-	token, err := verifyToken(strings.Split(tokenString, " ")[1])
-	if err != nil {
-		logger.Error("Error verifying token: " + err.Error())
+		requestLogger(c).Error("Error inserting coin: " + err.Error())
 		c.JSON(400, gin.H{"error": err.Error()})
 		return
 	}
 
-	claims := token.Claims.(jwt.MapClaims)
-	roleID := int(claims["role_id"].(float64))
-	userID := int(claims["user_id"].(float64))
+	userID, roleID := currentUser(c)
 
 	if roleID != 1 {
-		logger.Error("User cannot deposit money")
+		requestLogger(c).Error("User cannot deposit money")
 		c.JSON(400, gin.H{"error": "user cannot deposit money"})
 		return
 	}
 
-	if err := s.MachineService.DepositMoney(userID, deposit.Amount); err != nil {
-		logger.Error("Error depositing money: " + err.Error())
+	if err := s.MachineService.DepositMoney(c.Request.Context(), userID, deposit.Amount); err != nil {
+		requestLogger(c).Error("Error depositing money: " + err.Error())
 		c.JSON(400, gin.H{"error": err.Error()})
 		return
 	}
 
+	if _, err := s.auditDeposit(c, userID, deposit.Amount); err != nil {
+		requestLogger(c).Error("Error getting user: " + err.Error())
+	}
 	c.JSON(200, gin.H{"message": "money deposited"})
 
 }
@@ -383,148 +303,57 @@ func (s *HTTPHandler) BuyProduct(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&buyProduct); err != nil {
-		logger.Error("Error binding json: " + err.Error())
+		requestLogger(c).Error("Error binding json: " + err.Error())
 		c.JSON(400, gin.H{"error": err.Error()})
 		return
 	}
 
-	tokenString := c.Request.Header.Get("Authorization")
-	if len(strings.Split(tokenString, " ")) != 2 {
-		logger.Error("Error getting token from header")
-		c.JSON(400, gin.H{"error": "Error getting token from header"})
-		return
-	}
-
-	token, err := verifyToken(strings.Split(tokenString, " ")[1])
-	if err != nil {
-		logger.Error("Error verifying token: " + err.Error())
-		c.JSON(400, gin.H{"error": err.Error()})
-		return
-	}
-
-	claims := token.Claims.(jwt.MapClaims)
-	roleID := int(claims["role_id"].(float64))
-	userID := int(claims["user_id"].(float64))
+	userID, roleID := currentUser(c)
 
 	if roleID != 1 {
-		logger.Error("User cannot buy product")
+		requestLogger(c).Error("User cannot buy product")
 		c.JSON(400, gin.H{"error": "user cannot buy product"})
 		return
 	}
 
-	product, err := s.MachineService.GetProductById(buyProduct.ProductID)
+	order, remaining, err := s.MachineService.BuyProduct(c.Request.Context(), userID, buyProduct.ProductID, buyProduct.Quantity)
 	if err != nil {
-		logger.Error("Error getting product: " + err.Error())
-		c.JSON(400, gin.H{"error": err.Error()})
-		return
-	}
-
-	user, err := s.MachineService.GetUserById(userID)
-	if err != nil {
-		logger.Error("Error getting user: " + err.Error())
-		c.JSON(400, gin.H{"error": err.Error()})
-		return
-	}
-
-	if user.Deposit < product.Cost {
-		logger.Error("User does not have enough money")
-		c.JSON(400, gin.H{"error": "user does not have enough money"})
-		return
-	}
-
-	if product.ProductID == 0 {
-		logger.Error("Product does not exist")
-		c.JSON(400, gin.H{"error": "product does not exist"})
-		return
-	}
-
-	if product.AmountAvailable < buyProduct.Quantity {
-		logger.Error("Product quantity is not enough")
-		c.JSON(400, gin.H{"error": "product quantity is not enough"})
-		return
-	}
-
-	totalPrice := product.Cost * buyProduct.Quantity
-
-	change := user.Deposit - totalPrice
-
-	user.Deposit = change
-	if err := s.MachineService.UpdateUser(user); err != nil {
-		logger.Error("Error updating user: " + err.Error())
-		c.JSON(400, gin.H{"error": err.Error()})
-		return
-	}
-
-	product.AmountAvailable = product.AmountAvailable - buyProduct.Quantity
-
-	if err := s.MachineService.UpdateProductByID(int(product.ProductID), &product); err != nil {
-		logger.Error("Error updating product: " + err.Error())
+		requestLogger(c).Error("Error buying product: " + err.Error())
 		c.JSON(400, gin.H{"error": err.Error()})
 		return
 	}
 
-	response.TotalPrice = totalPrice
-	response.Change = getChange(change)
-	response.Quantity = buyProduct.Quantity
+	audit(c, strconv.Itoa(int(order.OrderID)), nil, order)
+	response.TotalPrice = order.TotalPrice
+	response.Change = getChange(remaining)
+	response.Quantity = order.Quantity
 
 	c.JSON(200, response)
 
 }
 
 func getChange(amount int) []int {
-
-	coins := []int{100, 50, 20, 10, 5}
-	change := []int{}
-
-	for _, coin := range coins {
-		for amount >= coin {
-			amount -= coin
-			change = append(change, coin)
-		}
-	}
-
-	return change
+	return models.Change(amount)
 }
 
 func (s *HTTPHandler) ResetDeposit(context *gin.Context) {
 
-	tokenString := context.Request.Header.Get("Authorization")
-	if len(strings.Split(tokenString, " ")) != 2 {
-		logger.Error("Error getting token from header")
-		context.JSON(400, gin.H{"error": "Error getting token from header"})
-		return
-	}
-
-	token, err := verifyToken(strings.Split(tokenString, " ")[1])
-	if err != nil {
-		logger.Error("Error verifying token: " + err.Error())
-		context.JSON(400, gin.H{"error": err.Error()})
-		return
-	}
-
-	claims := token.Claims.(jwt.MapClaims)
-	roleID := int(claims["role_id"].(float64))
-	userID := int(claims["user_id"].(float64))
+	userID, roleID := currentUser(context)
 
 	if roleID != 1 {
-		logger.Error("User cannot reset deposit")
+		requestLogger(context).Error("User cannot reset deposit")
 		context.JSON(400, gin.H{"error": "user cannot reset deposit"})
 		return
 	}
 
-	user, err := s.MachineService.GetUserById(userID)
+	refunded, err := s.MachineService.ResetDeposit(context.Request.Context(), userID)
 	if err != nil {
-		logger.Error("Error getting user: " + err.Error())
+		requestLogger(context).Error("Error resetting deposit: " + err.Error())
 		context.JSON(400, gin.H{"error": err.Error()})
 		return
 	}
 
-	user.Deposit = 0
-	if err := s.MachineService.UpdateUser(user); err != nil {
-		logger.Error("Error updating user: " + err.Error())
-		context.JSON(400, gin.H{"error": err.Error()})
-		return
-	}
+	audit(context, strconv.Itoa(userID), depositResponse{Deposit: refunded}, depositResponse{})
 
 	context.JSON(200, gin.H{"message": "deposit reset"})
 }
@@ -0,0 +1,386 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"strconv"
+	models "verkaufsautomat/internal/core/domain/resource"
+)
+
+type productRequest struct {
+	ProductName     string            `json:"product_name" binding:"required"`
+	Cost            int               `json:"cost" binding:"required,gt=0"`
+	AmountAvailable int               `json:"amount_available" binding:"gte=0"`
+	CategoryIDs     []uint            `json:"category_ids"`
+	Tags            []string          `json:"tags"`
+	Attributes      models.Attributes `json:"attributes"`
+}
+
+// categories returns the categories named by ID, to be resolved by the
+// service.
+func (r productRequest) categories() []models.Category {
+	categories := []models.Category{}
+	for _, id := range r.CategoryIDs {
+		categories = append(categories, models.Category{CategoryID: id})
+	}
+	return categories
+}
+
+type depositRequest struct {
+	Amount int `json:"amount" binding:"required"`
+}
+
+type orderRequest struct {
+	ProductID int `json:"product_id" binding:"required"`
+	Quantity  int `json:"quantity" binding:"required"`
+}
+
+type depositResponse struct {
+	Deposit int `json:"deposit"`
+}
+
+type orderResponse struct {
+	models.Order
+	Change []int `json:"change"`
+}
+
+// errorStatus maps domain errors onto the HTTP status codes used by v2.
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, models.ErrProductNotFound),
+		errors.Is(err, models.ErrOrderNotFound),
+		errors.Is(err, models.ErrUserNotFound),
+		errors.Is(err, models.ErrWebhookNotFound),
+		errors.Is(err, models.ErrDeliveryNotFound),
+		errors.Is(err, models.ErrAPIKeyNotFound),
+		errors.Is(err, models.ErrCategoryNotFound):
+		return 404
+	case errors.Is(err, models.ErrUserExists),
+		errors.Is(err, models.ErrDeliveryExists),
+		errors.Is(err, models.ErrInsufficientFunds),
+		errors.Is(err, models.ErrInsufficientStock),
+		errors.Is(err, models.ErrDepositOutstanding),
+		errors.Is(err, models.ErrSellerHasProducts),
+		errors.Is(err, models.ErrMFANotEnabled),
+		errors.Is(err, models.ErrMFAAlreadyEnabled),
+		errors.Is(err, models.ErrMFAEnforced),
+		errors.Is(err, models.ErrCategoryExists),
+		errors.Is(err, models.ErrCategoryInUse):
+		return 409
+	case errors.Is(err, models.ErrInvalidQuantity),
+		errors.Is(err, models.ErrInvalidWebhook),
+		errors.Is(err, models.ErrInvalidRole),
+		errors.Is(err, models.ErrPasswordTooShort),
+		errors.Is(err, models.ErrPasswordTooLong),
+		errors.Is(err, models.ErrPasswordBreached),
+		errors.Is(err, models.ErrInvalidResetToken),
+		errors.Is(err, models.ErrInvalidUsername),
+		errors.Is(err, models.ErrInvalidAPIKeyInput),
+		errors.Is(err, models.ErrInvalidMFACode),
+		errors.Is(err, models.ErrInvalidCategory),
+		errors.Is(err, models.ErrInvalidTag),
+		errors.Is(err, models.ErrInvalidAttribute):
+		return 400
+	case errors.Is(err, models.ErrInvalidMFAChallenge):
+		return 401
+	case errors.Is(err, models.ErrRoleNotAllowed):
+		return 403
+	case errors.Is(err, models.ErrResetUnavailable):
+		return 503
+	case errors.Is(err, context.DeadlineExceeded):
+		return 504
+	case errors.Is(err, context.Canceled):
+		return statusClientClosedRequest
+	}
+	return 500
+}
+
+func abortWithError(c *gin.Context, err error) {
+	c.AbortWithStatusJSON(errorStatus(err), gin.H{"error": err.Error()})
+}
+
+// RequireRole rejects requests from users whose role differs from roleID.
+func RequireRole(roleID int, action string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, role := currentUser(c); role != roleID {
+			requestLogger(c).Error("User cannot " + action)
+			c.AbortWithStatusJSON(403, gin.H{"error": "user cannot " + action})
+			return
+		}
+		c.Next()
+	}
+}
+
+func idParam(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		requestLogger(c).Error("Error converting id to int: " + err.Error())
+		c.AbortWithStatusJSON(400, gin.H{"error": "id must be an integer"})
+		return 0, false
+	}
+	return id, true
+}
+
+func (s *HTTPHandler) GetProductsV2(c *gin.Context) {
+	filter, ok := productFilter(c)
+	if !ok {
+		return
+	}
+
+	products, err := s.MachineService.SearchProducts(c.Request.Context(), filter)
+	if err != nil {
+		requestLogger(c).Error("Error getting products: " + err.Error())
+		abortWithError(c, err)
+		return
+	}
+
+	if products == nil {
+		products = []models.Product{}
+	}
+	c.JSON(200, products)
+}
+
+func (s *HTTPHandler) GetProductV2(c *gin.Context) {
+	id, ok := idParam(c)
+	if !ok {
+		return
+	}
+
+	product, err := s.MachineService.GetProductById(c.Request.Context(), id)
+	if err != nil {
+		requestLogger(c).Error("Error getting product: " + err.Error())
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(200, product)
+}
+
+func (s *HTTPHandler) CreateProductV2(c *gin.Context) {
+	var request productRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		requestLogger(c).Error("Error binding json: " + err.Error())
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := currentUser(c)
+	product := models.Product{
+		ProductName:     request.ProductName,
+		Cost:            request.Cost,
+		AmountAvailable: request.AmountAvailable,
+		SellerID:        uint(userID),
+		Categories:      request.categories(),
+		Tags:            request.Tags,
+		Attributes:      request.Attributes,
+	}
+
+	if err := s.MachineService.CreateProduct(c.Request.Context(), &product); err != nil {
+		requestLogger(c).Error("Error creating product: " + err.Error())
+		abortWithError(c, err)
+		return
+	}
+
+	audit(c, strconv.Itoa(int(product.ProductID)), nil, product)
+	c.Header("Location", "/api/v2/products/"+strconv.Itoa(int(product.ProductID)))
+	c.JSON(201, product)
+}
+
+// ownProduct loads the product named in the path and checks that it belongs
+// to the current seller.
+func (s *HTTPHandler) ownProduct(c *gin.Context) (models.Product, bool) {
+	id, ok := idParam(c)
+	if !ok {
+		return models.Product{}, false
+	}
+
+	product, err := s.MachineService.GetProductById(c.Request.Context(), id)
+	if err != nil {
+		requestLogger(c).Error("Error getting product: " + err.Error())
+		abortWithError(c, err)
+		return product, false
+	}
+
+	if userID, _ := currentUser(c); product.SellerID != uint(userID) {
+		requestLogger(c).Error("Product belongs to another seller")
+		c.AbortWithStatusJSON(403, gin.H{"error": "product belongs to another seller"})
+		return product, false
+	}
+
+	return product, true
+}
+
+func (s *HTTPHandler) UpdateProductV2(c *gin.Context) {
+	var request productRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		requestLogger(c).Error("Error binding json: " + err.Error())
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	product, ok := s.ownProduct(c)
+	if !ok {
+		return
+	}
+
+	previous := product
+	product.ProductName = request.ProductName
+	product.Cost = request.Cost
+	product.AmountAvailable = request.AmountAvailable
+	product.Categories = request.categories()
+	product.Tags = request.Tags
+	product.Attributes = request.Attributes
+
+	if err := s.MachineService.UpdateProductByID(c.Request.Context(), int(product.ProductID), &product); err != nil {
+		requestLogger(c).Error("Error updating product: " + err.Error())
+		abortWithError(c, err)
+		return
+	}
+
+	audit(c, strconv.Itoa(int(product.ProductID)), previous, product)
+	c.JSON(200, product)
+}
+
+func (s *HTTPHandler) DeleteProductV2(c *gin.Context) {
+	product, ok := s.ownProduct(c)
+	if !ok {
+		return
+	}
+
+	if err := s.MachineService.DeleteProductByID(c.Request.Context(), int(product.ProductID)); err != nil {
+		requestLogger(c).Error("Error deleting product: " + err.Error())
+		abortWithError(c, err)
+		return
+	}
+
+	audit(c, strconv.Itoa(int(product.ProductID)), product, nil)
+	c.Status(204)
+}
+
+func (s *HTTPHandler) GetDepositV2(c *gin.Context) {
+	userID, _ := currentUser(c)
+	user, err := s.MachineService.GetUserById(c.Request.Context(), userID)
+	if err != nil {
+		requestLogger(c).Error("Error getting user: " + err.Error())
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(200, depositResponse{Deposit: user.Deposit})
+}
+
+func (s *HTTPHandler) DepositV2(c *gin.Context) {
+	var request depositRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		requestLogger(c).Error("Error binding json: " + err.Error())
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, err := insertCoin(request.Amount); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := currentUser(c)
+	if err := s.MachineService.DepositMoney(c.Request.Context(), userID, request.Amount); err != nil {
+		requestLogger(c).Error("Error depositing money: " + err.Error())
+		abortWithError(c, err)
+		return
+	}
+
+	deposit, err := s.auditDeposit(c, userID, request.Amount)
+	if err != nil {
+		requestLogger(c).Error("Error getting user: " + err.Error())
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(200, deposit)
+}
+
+// auditDeposit records a deposit in the audit log and returns the new
+// balance.
+func (s *HTTPHandler) auditDeposit(c *gin.Context, userID, amount int) (depositResponse, error) {
+	user, err := s.MachineService.GetUserById(c.Request.Context(), userID)
+	if err != nil {
+		return depositResponse{}, err
+	}
+
+	deposit := depositResponse{Deposit: user.Deposit}
+	audit(c, strconv.Itoa(userID), depositResponse{Deposit: user.Deposit - amount}, deposit)
+	return deposit, nil
+}
+
+func (s *HTTPHandler) ResetDepositV2(c *gin.Context) {
+	userID, _ := currentUser(c)
+	refunded, err := s.MachineService.ResetDeposit(c.Request.Context(), userID)
+	if err != nil {
+		requestLogger(c).Error("Error resetting deposit: " + err.Error())
+		abortWithError(c, err)
+		return
+	}
+
+	audit(c, strconv.Itoa(userID), depositResponse{Deposit: refunded}, depositResponse{})
+	c.Status(204)
+}
+
+func (s *HTTPHandler) CreateOrderV2(c *gin.Context) {
+	var request orderRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		requestLogger(c).Error("Error binding json: " + err.Error())
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := currentUser(c)
+	order, remaining, err := s.MachineService.BuyProduct(c.Request.Context(), userID, request.ProductID, request.Quantity)
+	if err != nil {
+		requestLogger(c).Error("Error buying product: " + err.Error())
+		abortWithError(c, err)
+		return
+	}
+
+	audit(c, strconv.Itoa(int(order.OrderID)), nil, order)
+	c.Header("Location", "/api/v2/orders/"+strconv.Itoa(int(order.OrderID)))
+	c.JSON(201, orderResponse{Order: order, Change: getChange(remaining)})
+}
+
+func (s *HTTPHandler) GetOrdersV2(c *gin.Context) {
+	userID, _ := currentUser(c)
+	orders, err := s.MachineService.GetOrdersByBuyer(c.Request.Context(), userID)
+	if err != nil {
+		requestLogger(c).Error("Error getting orders: " + err.Error())
+		abortWithError(c, err)
+		return
+	}
+
+	if orders == nil {
+		orders = []models.Order{}
+	}
+	c.JSON(200, orders)
+}
+
+func (s *HTTPHandler) GetOrderV2(c *gin.Context) {
+	id, ok := idParam(c)
+	if !ok {
+		return
+	}
+
+	order, err := s.MachineService.GetOrderById(c.Request.Context(), id)
+	if err != nil {
+		requestLogger(c).Error("Error getting order: " + err.Error())
+		abortWithError(c, err)
+		return
+	}
+
+	// Other buyers' orders are reported as missing rather than forbidden so
+	// that order IDs cannot be probed.
+	if userID, _ := currentUser(c); order.BuyerID != uint(userID) {
+		abortWithError(c, models.ErrOrderNotFound)
+		return
+	}
+
+	c.JSON(200, order)
+}
@@ -0,0 +1,157 @@
+package resource
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"verkaufsautomat/internal/core/domain/resource"
+	"verkaufsautomat/internal/core/events"
+)
+
+func TestApplication_V2(t *testing.T) {
+	bus := events.NewBus()
+	mockedService, handler, router := newTestRouter(t, bus)
+	mockedService.EXPECT().RecordAudit(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	serve := serveRouter(t, router)
+
+	seller, _ := testTokens.Generate(&resource.User{UserID: 1, RoleID: resource.SellerRoleID, Username: "harry"})
+	buyer, _ := testTokens.Generate(&resource.User{UserID: 2, RoleID: resource.BuyerRoleID, Username: "sally"})
+
+	t.Run("Create product", func(t *testing.T) {
+		mockedService.EXPECT().CreateProduct(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, product *resource.Product) error {
+			product.ProductID = 7
+			return nil
+		})
+
+		response := serve("POST", "/api/v2/products", seller, `{"product_name":"cocacola","cost":100,"amount_available":10}`)
+
+		if response.Code != http.StatusCreated {
+			t.Errorf("Expected status code %d, got %d", http.StatusCreated, response.Code)
+		}
+		if location := response.Header().Get("Location"); location != "/api/v2/products/7" {
+			t.Errorf("Expected Location /api/v2/products/7, got %q", location)
+		}
+	})
+
+	t.Run("Buyer cannot create product", func(t *testing.T) {
+		response := serve("POST", "/api/v2/products", buyer, `{"product_name":"cocacola","cost":100}`)
+
+		if response.Code != http.StatusForbidden {
+			t.Errorf("Expected status code %d, got %d", http.StatusForbidden, response.Code)
+		}
+	})
+
+	t.Run("Missing product", func(t *testing.T) {
+		mockedService.EXPECT().GetProductById(gomock.Any(), 99).Return(resource.Product{}, resource.ErrProductNotFound)
+
+		response := serve("GET", "/api/v2/products/99", buyer, "")
+
+		if response.Code != http.StatusNotFound {
+			t.Errorf("Expected status code %d, got %d", http.StatusNotFound, response.Code)
+		}
+	})
+
+	t.Run("Order without enough deposit", func(t *testing.T) {
+		mockedService.EXPECT().BuyProduct(gomock.Any(), 2, 7, 3).Return(resource.Order{}, 0, resource.ErrInsufficientFunds)
+
+		response := serve("POST", "/api/v2/orders", buyer, `{"product_id":7,"quantity":3}`)
+
+		if response.Code != http.StatusConflict {
+			t.Errorf("Expected status code %d, got %d", http.StatusConflict, response.Code)
+		}
+	})
+
+	t.Run("Reset deposit", func(t *testing.T) {
+		mockedService.EXPECT().ResetDeposit(gomock.Any(), 2).Return(35, nil)
+
+		response := serve("DELETE", "/api/v2/me/deposit", buyer, "")
+
+		if response.Code != http.StatusNoContent {
+			t.Errorf("Expected status code %d, got %d", http.StatusNoContent, response.Code)
+		}
+	})
+
+	t.Run("v1 routes are deprecated", func(t *testing.T) {
+		mockedService.EXPECT().GetProducts(gomock.Any()).Return([]resource.Product{}, nil)
+
+		response := serve("GET", "/auth/get_products", buyer, "")
+
+		if response.Header().Get("Deprecation") != "true" {
+			t.Error("Expected a Deprecation header on a v1 route")
+		}
+		if !strings.Contains(response.Header().Get("Link"), "</api/v2/products>") {
+			t.Errorf("Expected a Link to the v2 route, got %q", response.Header().Get("Link"))
+		}
+	})
+
+	t.Run("Event stream", func(t *testing.T) {
+		subscribed := make(chan struct{})
+		mockedService.EXPECT().GetUserById(gomock.Any(), 2).DoAndReturn(func(_ context.Context, id int) (resource.User, error) {
+			close(subscribed)
+			return resource.User{UserID: 2, Deposit: 35}, nil
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		req, err := http.NewRequestWithContext(ctx, "GET", "/api/v2/events", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+buyer)
+
+		response := httptest.NewRecorder()
+		done := make(chan struct{})
+		go func() {
+			router.ServeHTTP(response, req)
+			close(done)
+		}()
+
+		<-subscribed
+		bus.Publish(events.StockChanged{Product: resource.Product{ProductID: 7, AmountAvailable: 2}, PreviousAmount: 3})
+		bus.Publish(events.DepositMade{UserID: 1, Amount: 100, Deposit: 500})
+		bus.Publish(events.DepositMade{UserID: 2, Amount: 5, Deposit: 40})
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+		<-done
+
+		body := response.Body.String()
+		for _, expected := range []string{
+			"event: balance_changed\ndata: {\"deposit\":35}",
+			"event: stock_changed\ndata: {\"product_id\":7,\"amount_available\":2}",
+			"event: balance_changed\ndata: {\"deposit\":40}",
+		} {
+			if !strings.Contains(body, expected) {
+				t.Errorf("Expected %q in event stream %q", expected, body)
+			}
+		}
+		if strings.Contains(body, "500") {
+			t.Error("Balance of another user was streamed")
+		}
+	})
+
+	t.Run("Event streams end on shutdown", func(t *testing.T) {
+		mockedService.EXPECT().GetUserById(gomock.Any(), 2).Return(resource.User{UserID: 2}, nil)
+
+		req, err := http.NewRequest("GET", "/api/v2/events", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+buyer)
+
+		done := make(chan struct{})
+		go func() {
+			router.ServeHTTP(httptest.NewRecorder(), req)
+			close(done)
+		}()
+		handler.CloseStreams()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Error("Expected the stream to end")
+		}
+	})
+}
@@ -0,0 +1,42 @@
+package resource
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	models "verkaufsautomat/internal/core/domain/resource"
+)
+
+type readinessResponse struct {
+	Status string                    `json:"status"`
+	Checks []models.DependencyStatus `json:"checks"`
+}
+
+// Livez reports that the process is up and serving. It checks nothing else,
+// so that an unavailable database does not get the server restarted.
+func (s *HTTPHandler) Livez(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Readyz reports whether the server can handle requests: the database
+// answers and its schema is at the expected version. It responds with 503
+// and the failing checks otherwise.
+func (s *HTTPHandler) Readyz(c *gin.Context) {
+	checks, ready := s.readiness(c)
+	if !ready {
+		c.JSON(http.StatusServiceUnavailable, readinessResponse{Status: "unavailable", Checks: checks})
+		return
+	}
+	c.JSON(http.StatusOK, readinessResponse{Status: "ok", Checks: checks})
+}
+
+func (s *HTTPHandler) readiness(c *gin.Context) ([]models.DependencyStatus, bool) {
+	checks := s.MachineService.HealthCheck(c.Request.Context())
+	ready := true
+	for _, check := range checks {
+		if !check.Healthy {
+			requestLogger(c).Warn("Dependency " + check.Name + " is unhealthy: " + check.Error)
+			ready = false
+		}
+	}
+	return checks, ready
+}
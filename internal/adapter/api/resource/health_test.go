@@ -0,0 +1,98 @@
+package resource
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"verkaufsautomat/internal/config"
+	"verkaufsautomat/internal/core/domain/resource"
+	"verkaufsautomat/internal/core/events"
+	services "verkaufsautomat/internal/core/services/mock"
+)
+
+func TestApplication_Probes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockedService := services.NewMockMachineService(ctrl)
+	acceptTokens(mockedService)
+	handler := NewHTTPHandler(mockedService, events.NewBus(), testTokens, config.Default().HTTP)
+
+	router := gin.Default()
+
+	handler.Routes(router)
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest("GET", path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, req)
+		return response
+	}
+
+	healthy := []resource.DependencyStatus{
+		{Name: "database", Healthy: true, LatencyMS: 0.4},
+		{Name: "migrations", Healthy: true, LatencyMS: 0.9, Detail: "schema is at version 1, expected 1"},
+	}
+	unhealthy := []resource.DependencyStatus{
+		{Name: "database", Healthy: false, LatencyMS: 2000, Error: "dial tcp: connection refused"},
+		healthy[1],
+	}
+
+	t.Run("Liveness does not check dependencies", func(t *testing.T) {
+		response := serve("/livez")
+
+		if response.Code != http.StatusOK {
+			t.Errorf("Expected status code %d, got %d", http.StatusOK, response.Code)
+		}
+	})
+
+	t.Run("Ready when every dependency is healthy", func(t *testing.T) {
+		mockedService.EXPECT().HealthCheck(gomock.Any()).Return(healthy)
+
+		response := serve("/readyz")
+
+		if response.Code != http.StatusOK {
+			t.Errorf("Expected status code %d, got %d", http.StatusOK, response.Code)
+		}
+		var body readinessResponse
+		if err := json.Unmarshal(response.Body.Bytes(), &body); err != nil {
+			t.Fatal(err)
+		}
+		if body.Status != "ok" || len(body.Checks) != 2 || body.Checks[1].Detail != healthy[1].Detail {
+			t.Errorf("Unexpected readiness %+v", body)
+		}
+	})
+
+	t.Run("Not ready when a dependency is unhealthy", func(t *testing.T) {
+		mockedService.EXPECT().HealthCheck(gomock.Any()).Return(unhealthy)
+
+		response := serve("/readyz")
+
+		if response.Code != http.StatusServiceUnavailable {
+			t.Errorf("Expected status code %d, got %d", http.StatusServiceUnavailable, response.Code)
+		}
+		var body readinessResponse
+		if err := json.Unmarshal(response.Body.Bytes(), &body); err != nil {
+			t.Fatal(err)
+		}
+		if body.Status != "unavailable" || body.Checks[0].Error == "" {
+			t.Errorf("Unexpected readiness %+v", body)
+		}
+	})
+
+	t.Run("The v1 health check follows readiness", func(t *testing.T) {
+		mockedService.EXPECT().HealthCheck(gomock.Any()).Return(unhealthy)
+
+		response := serve("/api/v1/healthcheck")
+
+		if response.Code != http.StatusServiceUnavailable {
+			t.Errorf("Expected status code %d, got %d", http.StatusServiceUnavailable, response.Code)
+		}
+	})
+}
@@ -0,0 +1,24 @@
+package resource
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// BodyLimit rejects request bodies larger than MaxBodyBytes. Requests that
+// announce a larger body get 413 right away; others fail to bind once the
+// limit is reached.
+func (s *HTTPHandler) BodyLimit() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if s.Config.MaxBodyBytes <= 0 || c.Request.Body == nil {
+			c.Next()
+			return
+		}
+		if c.Request.ContentLength > s.Config.MaxBodyBytes {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"error": "request body too large"})
+			return
+		}
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, s.Config.MaxBodyBytes)
+		c.Next()
+	}
+}
@@ -0,0 +1,58 @@
+package resource
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"verkaufsautomat/internal/config"
+	"verkaufsautomat/internal/core/events"
+	services "verkaufsautomat/internal/core/services/mock"
+)
+
+func TestApplication_BodyLimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockedService := services.NewMockMachineService(ctrl)
+	acceptTokens(mockedService)
+	cfg := config.Default().HTTP
+	cfg.MaxBodyBytes = 64
+	handler := NewHTTPHandler(mockedService, events.NewBus(), testTokens, cfg)
+
+	router := gin.Default()
+
+	handler.Routes(router)
+
+	body := `{"username":"sally","password":"` + strings.Repeat("x", 100) + `","role_id":1}`
+
+	t.Run("Bodies announced as too large are rejected", func(t *testing.T) {
+		req, err := http.NewRequest("POST", "/api/v1/register", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, req)
+
+		if response.Code != http.StatusRequestEntityTooLarge {
+			t.Errorf("Expected status code %d, got %d", http.StatusRequestEntityTooLarge, response.Code)
+		}
+	})
+
+	t.Run("Bodies of unknown length stop at the limit", func(t *testing.T) {
+		req, err := http.NewRequest("POST", "/api/v1/register", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.ContentLength = -1
+
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, req)
+
+		if response.Code != http.StatusBadRequest {
+			t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, response.Code)
+		}
+	})
+}
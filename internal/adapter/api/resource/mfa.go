@@ -0,0 +1,195 @@
+package resource
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"strconv"
+	models "verkaufsautomat/internal/core/domain/resource"
+)
+
+type mfaChallengeResponse struct {
+	Message        string `json:"message"`
+	MFARequired    bool   `json:"mfa_required"`
+	ChallengeToken string `json:"challenge_token"`
+}
+
+type mfaLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	// Code is a TOTP code or a recovery code.
+	Code string `json:"code" binding:"required"`
+}
+
+type mfaEnrollRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+}
+
+type mfaCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type mfaDisableRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+type recoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// VerifyMFA is the second login step, which trades the challenge token
+// from Login and a code for the login token.
+func (s *HTTPHandler) VerifyMFA(c *gin.Context) {
+	var request mfaLoginRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		requestLogger(c).Error("Error binding json: " + err.Error())
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, token, recoveryCodes, err := s.MachineService.VerifyMFA(c.Request.Context(), request.ChallengeToken, request.Code)
+	if user.UserID != 0 {
+		auditActor(c, user)
+	}
+	if errors.Is(err, models.ErrInvalidMFAChallenge) {
+		requestLogger(c).Warn("Error verifying second factor: " + err.Error())
+		c.JSON(401, gin.H{"error": err.Error()})
+		return
+	}
+	// Locked accounts get the same answer as wrong codes.
+	if errors.Is(err, models.ErrInvalidMFACode) || errors.Is(err, models.ErrInvalidCredentials) {
+		requestLogger(c).Warn("Error verifying second factor: " + err.Error())
+		c.JSON(401, gin.H{"error": models.ErrInvalidMFACode.Error()})
+		return
+	}
+	if errors.Is(err, models.ErrMFANotEnabled) {
+		requestLogger(c).Warn("Error verifying second factor: " + err.Error())
+		c.JSON(409, gin.H{"error": "enrol in two-factor authentication first"})
+		return
+	}
+	if err != nil {
+		requestLogger(c).Error("Error verifying second factor: " + err.Error())
+		abortWithError(c, err)
+		return
+	}
+
+	s.startSession(c, user, token, recoveryCodes)
+}
+
+// EnrollMFAWithChallenge starts enrolment for users whose role requires a
+// second factor and who do not have one yet, so they cannot log in.
+func (s *HTTPHandler) EnrollMFAWithChallenge(c *gin.Context) {
+	var request mfaEnrollRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		requestLogger(c).Error("Error binding json: " + err.Error())
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	_, enrollment, err := s.MachineService.EnrollMFAWithChallenge(c.Request.Context(), request.ChallengeToken)
+	if errors.Is(err, models.ErrInvalidMFAChallenge) {
+		requestLogger(c).Warn("Error enrolling second factor: " + err.Error())
+		c.JSON(401, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		requestLogger(c).Error("Error enrolling second factor: " + err.Error())
+		abortWithError(c, err)
+		return
+	}
+	c.JSON(200, enrollment)
+}
+
+// EnrollMFA starts enrolment for the current user. The second factor is
+// enabled by ConfirmMFA.
+func (s *HTTPHandler) EnrollMFA(c *gin.Context) {
+	userID, _ := currentUser(c)
+	enrollment, err := s.MachineService.EnrollMFA(c.Request.Context(), userID)
+	if err != nil {
+		requestLogger(c).Error("Error enrolling second factor: " + err.Error())
+		abortWithError(c, err)
+		return
+	}
+	c.JSON(200, enrollment)
+}
+
+// ConfirmMFA enables the second factor of the current user with a first
+// code and returns the recovery codes.
+func (s *HTTPHandler) ConfirmMFA(c *gin.Context) {
+	var request mfaCodeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		requestLogger(c).Error("Error binding json: " + err.Error())
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := currentUser(c)
+	audit(c, strconv.Itoa(userID), nil, nil)
+	recoveryCodes, err := s.MachineService.ConfirmMFA(c.Request.Context(), userID, request.Code)
+	if err != nil {
+		requestLogger(c).Error("Error confirming second factor: " + err.Error())
+		abortWithError(c, err)
+		return
+	}
+	c.JSON(200, recoveryCodesResponse{RecoveryCodes: recoveryCodes})
+}
+
+// DisableMFA removes the second factor of the current user, who has to
+// give the password again.
+func (s *HTTPHandler) DisableMFA(c *gin.Context) {
+	var request mfaDisableRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		requestLogger(c).Error("Error binding json: " + err.Error())
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := currentUser(c)
+	audit(c, strconv.Itoa(userID), nil, nil)
+	err := s.MachineService.DisableMFA(c.Request.Context(), userID, request.Password)
+	if errors.Is(err, models.ErrInvalidCredentials) {
+		requestLogger(c).Warn("Error disabling second factor: " + err.Error())
+		c.JSON(403, gin.H{"error": "password is incorrect"})
+		return
+	}
+	if err != nil {
+		requestLogger(c).Error("Error disabling second factor: " + err.Error())
+		abortWithError(c, err)
+		return
+	}
+	c.Status(204)
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of the current user.
+func (s *HTTPHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	var request mfaCodeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		requestLogger(c).Error("Error binding json: " + err.Error())
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := currentUser(c)
+	audit(c, strconv.Itoa(userID), nil, nil)
+	recoveryCodes, err := s.MachineService.RegenerateRecoveryCodes(c.Request.Context(), userID, request.Code)
+	if err != nil {
+		requestLogger(c).Error("Error regenerating recovery codes: " + err.Error())
+		abortWithError(c, err)
+		return
+	}
+	c.JSON(200, recoveryCodesResponse{RecoveryCodes: recoveryCodes})
+}
+
+// ResetMFA lets admins remove the second factor of a user who lost it.
+func (s *HTTPHandler) ResetMFA(c *gin.Context) {
+	id, ok := idParam(c)
+	if !ok {
+		return
+	}
+
+	if _, err := s.MachineService.ResetMFA(c.Request.Context(), id); err != nil {
+		requestLogger(c).Error("Error resetting second factor: " + err.Error())
+		abortWithError(c, err)
+		return
+	}
+	audit(c, strconv.Itoa(id), nil, nil)
+	c.Status(204)
+}
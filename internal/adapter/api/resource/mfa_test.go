@@ -0,0 +1,214 @@
+package resource
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/golang/mock/gomock"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+	"verkaufsautomat/internal/core/domain/resource"
+)
+
+func TestApplication_MFA(t *testing.T) {
+	mockedService, serve := newTestServer(t)
+
+	seller := resource.User{UserID: 4, RoleID: resource.SellerRoleID, Username: "sam"}
+	sellerToken, _ := testTokens.Generate(&seller)
+	admin, _ := testTokens.Generate(&resource.User{UserID: 1, RoleID: resource.AdminRoleID, Username: "root"})
+	challenge, _ := testTokens.GenerateChallenge(&seller, time.Minute)
+	enrollment := resource.MFAEnrollment{Secret: "JBSWY3DPEHPK3PXP", URI: "otpauth://totp/Verkaufsautomat:sam?secret=JBSWY3DPEHPK3PXP"}
+
+	recordAudit := func() *resource.AuditEntry {
+		entry := &resource.AuditEntry{}
+		mockedService.EXPECT().RecordAudit(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, recorded *resource.AuditEntry) error {
+			*entry = *recorded
+			return nil
+		})
+		return entry
+	}
+
+	t.Run("Login asks for the second factor", func(t *testing.T) {
+		mockedService.EXPECT().Login(gomock.Any(), "sam", "secret").Return(seller, challenge, resource.ErrMFARequired)
+		entry := recordAudit()
+
+		response := serve("POST", "/api/v1/login", "", `{"username":"sam","password":"secret"}`)
+
+		if response.Code != http.StatusAccepted {
+			t.Fatalf("Expected status code %d, got %d", http.StatusAccepted, response.Code)
+		}
+		var body mfaChallengeResponse
+		if err := json.Unmarshal(response.Body.Bytes(), &body); err != nil {
+			t.Fatal(err)
+		}
+		if !body.MFARequired || body.ChallengeToken != challenge {
+			t.Errorf("Expected a challenge, got %+v", body)
+		}
+		if response.Header().Get("Authorization") != "" || len(response.Result().Cookies()) != 0 {
+			t.Error("Expected no login token before the second factor")
+		}
+		if entry.ActorID != 4 || entry.Action != resource.AuditLogin {
+			t.Errorf("Unexpected audit entry %+v", entry)
+		}
+	})
+
+	t.Run("Challenge tokens are no login tokens", func(t *testing.T) {
+		response := serve("GET", "/auth/me", challenge, "")
+
+		if response.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status code %d, got %d", http.StatusUnauthorized, response.Code)
+		}
+	})
+
+	t.Run("The code completes the login", func(t *testing.T) {
+		mockedService.EXPECT().VerifyMFA(gomock.Any(), challenge, "123456").Return(seller, sellerToken, nil, nil)
+		entry := recordAudit()
+
+		response := serve("POST", "/api/v1/login/mfa", "", `{"challenge_token":"`+challenge+`","code":"123456"}`)
+
+		if response.Code != http.StatusOK {
+			t.Fatalf("Expected status code %d, got %d", http.StatusOK, response.Code)
+		}
+		var body loginResponse
+		if err := json.Unmarshal(response.Body.Bytes(), &body); err != nil {
+			t.Fatal(err)
+		}
+		if body.Token != sellerToken || body.CSRFToken == "" || body.RecoveryCodes != nil {
+			t.Errorf("Unexpected login %+v", body)
+		}
+		if entry.ActorID != 4 || entry.Action != resource.AuditLoginMFA || !entry.Succeeded {
+			t.Errorf("Unexpected audit entry %+v", entry)
+		}
+	})
+
+	t.Run("Enrolling during login returns the recovery codes", func(t *testing.T) {
+		mockedService.EXPECT().EnrollMFAWithChallenge(gomock.Any(), challenge).Return(seller, enrollment, nil)
+
+		response := serve("POST", "/api/v1/login/mfa/enroll", "", `{"challenge_token":"`+challenge+`"}`)
+
+		if response.Code != http.StatusOK {
+			t.Fatalf("Expected status code %d, got %d", http.StatusOK, response.Code)
+		}
+		if !strings.Contains(response.Body.String(), enrollment.URI) {
+			t.Errorf("Expected the provisioning URI, got %s", response.Body.String())
+		}
+
+		mockedService.EXPECT().VerifyMFA(gomock.Any(), challenge, "654321").Return(seller, sellerToken, []string{"abcde-fghij"}, nil)
+		recordAudit()
+
+		response = serve("POST", "/api/v1/login/mfa", "", `{"challenge_token":"`+challenge+`","code":"654321"}`)
+
+		if response.Code != http.StatusOK {
+			t.Fatalf("Expected status code %d, got %d", http.StatusOK, response.Code)
+		}
+		if !strings.Contains(response.Body.String(), "abcde-fghij") {
+			t.Errorf("Expected the recovery codes, got %s", response.Body.String())
+		}
+	})
+
+	t.Run("Wrong codes and locked accounts look the same", func(t *testing.T) {
+		for _, err := range []error{resource.ErrInvalidMFACode, resource.ErrInvalidCredentials} {
+			mockedService.EXPECT().VerifyMFA(gomock.Any(), challenge, "000000").Return(seller, "", nil, err)
+			recordAudit()
+
+			response := serve("POST", "/api/v1/login/mfa", "", `{"challenge_token":"`+challenge+`","code":"000000"}`)
+
+			if response.Code != http.StatusUnauthorized {
+				t.Errorf("Expected status code %d, got %d", http.StatusUnauthorized, response.Code)
+			}
+			if !strings.Contains(response.Body.String(), resource.ErrInvalidMFACode.Error()) {
+				t.Errorf("Expected the invalid code error, got %s", response.Body.String())
+			}
+		}
+	})
+
+	t.Run("Users who must enrol are told so", func(t *testing.T) {
+		mockedService.EXPECT().VerifyMFA(gomock.Any(), challenge, "123456").Return(seller, "", nil, resource.ErrMFANotEnabled)
+		recordAudit()
+
+		response := serve("POST", "/api/v1/login/mfa", "", `{"challenge_token":"`+challenge+`","code":"123456"}`)
+
+		if response.Code != http.StatusConflict {
+			t.Errorf("Expected status code %d, got %d", http.StatusConflict, response.Code)
+		}
+	})
+
+	t.Run("Enrol and confirm while logged in", func(t *testing.T) {
+		mockedService.EXPECT().EnrollMFA(gomock.Any(), 4).Return(enrollment, nil)
+
+		response := serve("POST", "/auth/me/mfa", sellerToken, "")
+
+		if response.Code != http.StatusOK {
+			t.Fatalf("Expected status code %d, got %d", http.StatusOK, response.Code)
+		}
+
+		mockedService.EXPECT().ConfirmMFA(gomock.Any(), 4, "123456").Return([]string{"abcde-fghij"}, nil)
+		entry := recordAudit()
+
+		response = serve("POST", "/auth/me/mfa/confirm", sellerToken, `{"code":"123456"}`)
+
+		if response.Code != http.StatusOK {
+			t.Fatalf("Expected status code %d, got %d", http.StatusOK, response.Code)
+		}
+		var body recoveryCodesResponse
+		if err := json.Unmarshal(response.Body.Bytes(), &body); err != nil {
+			t.Fatal(err)
+		}
+		if len(body.RecoveryCodes) != 1 {
+			t.Errorf("Expected the recovery codes, got %+v", body)
+		}
+		if entry.Action != resource.AuditMFAEnable || entry.TargetID != "4" {
+			t.Errorf("Unexpected audit entry %+v", entry)
+		}
+	})
+
+	t.Run("Wrong confirmation code", func(t *testing.T) {
+		mockedService.EXPECT().ConfirmMFA(gomock.Any(), 4, "000000").Return(nil, resource.ErrInvalidMFACode)
+		recordAudit()
+
+		response := serve("POST", "/auth/me/mfa/confirm", sellerToken, `{"code":"000000"}`)
+
+		if response.Code != http.StatusBadRequest {
+			t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, response.Code)
+		}
+	})
+
+	t.Run("Disable", func(t *testing.T) {
+		for _, test := range []struct {
+			err    error
+			status int
+		}{
+			{nil, http.StatusNoContent},
+			{resource.ErrInvalidCredentials, http.StatusForbidden},
+			{resource.ErrMFAEnforced, http.StatusConflict},
+		} {
+			mockedService.EXPECT().DisableMFA(gomock.Any(), 4, "secret").Return(test.err)
+			recordAudit()
+
+			response := serve("DELETE", "/auth/me/mfa", sellerToken, `{"password":"secret"}`)
+
+			if response.Code != test.status {
+				t.Errorf("Expected status code %d, got %d", test.status, response.Code)
+			}
+		}
+	})
+
+	t.Run("Admins reset second factors", func(t *testing.T) {
+		mockedService.EXPECT().ResetMFA(gomock.Any(), 4).Return(seller, nil)
+		entry := recordAudit()
+
+		response := serve("DELETE", "/api/v2/admin/users/4/mfa", admin, "")
+
+		if response.Code != http.StatusNoContent {
+			t.Fatalf("Expected status code %d, got %d", http.StatusNoContent, response.Code)
+		}
+		if entry.Action != resource.AuditMFAReset || entry.TargetID != "4" {
+			t.Errorf("Unexpected audit entry %+v", entry)
+		}
+
+		if response := serve("DELETE", "/api/v2/admin/users/4/mfa", sellerToken, ""); response.Code != http.StatusForbidden {
+			t.Errorf("Expected status code %d, got %d", http.StatusForbidden, response.Code)
+		}
+	})
+}
@@ -0,0 +1,98 @@
+package resource
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"verkaufsautomat/internal/config"
+	"verkaufsautomat/internal/core/events"
+	services "verkaufsautomat/internal/core/services/mock"
+)
+
+type openAPIDocument struct {
+	OpenAPI string                                `json:"openapi"`
+	Paths   map[string]map[string]json.RawMessage `json:"paths"`
+}
+
+// specPath converts a gin route path such as /auth/get_product/:id into its
+// OpenAPI form /auth/get_product/{id}.
+func specPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func TestApplication_OpenAPI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockedService := services.NewMockMachineService(ctrl)
+	acceptTokens(mockedService)
+	handler := NewHTTPHandler(mockedService, events.NewBus(), testTokens, config.Default().HTTP)
+
+	router := gin.Default()
+
+	handler.Routes(router)
+
+	var spec openAPIDocument
+	if err := json.Unmarshal(openAPISpec, &spec); err != nil {
+		t.Fatalf("openapi.json is not valid JSON: %v", err)
+	}
+
+	t.Run("Every route is documented", func(t *testing.T) {
+		for _, route := range router.Routes() {
+			operations, ok := spec.Paths[specPath(route.Path)]
+			if !ok {
+				t.Errorf("route %s %s has no path in openapi.json", route.Method, route.Path)
+				continue
+			}
+			if _, ok := operations[strings.ToLower(route.Method)]; !ok {
+				t.Errorf("route %s %s has no operation in openapi.json", route.Method, route.Path)
+			}
+		}
+	})
+
+	t.Run("Every documented operation is routed", func(t *testing.T) {
+		routed := map[string]bool{}
+		for _, route := range router.Routes() {
+			routed[strings.ToLower(route.Method)+" "+specPath(route.Path)] = true
+		}
+		for path, operations := range spec.Paths {
+			for method := range operations {
+				if method == "parameters" {
+					continue
+				}
+				if !routed[method+" "+path] {
+					t.Errorf("openapi.json documents %s %s but no such route exists", strings.ToUpper(method), path)
+				}
+			}
+		}
+	})
+
+	t.Run("Spec is served", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/api/v1/openapi.json", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, req)
+
+		if response.Code != http.StatusOK {
+			t.Errorf("Expected status code %d, got %d", http.StatusOK, response.Code)
+		}
+
+		var served openAPIDocument
+		if err := json.Unmarshal(response.Body.Bytes(), &served); err != nil || !strings.HasPrefix(served.OpenAPI, "3.") {
+			t.Errorf("Expected an OpenAPI 3 document, got %q", response.Body.String())
+		}
+	})
+
+}
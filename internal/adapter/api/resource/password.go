@@ -0,0 +1,94 @@
+package resource
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"strconv"
+	"strings"
+	models "verkaufsautomat/internal/core/domain/resource"
+)
+
+type passwordChangeRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
+}
+
+type passwordResetRequest struct {
+	Username string `json:"username" binding:"required"`
+}
+
+type passwordResetConfirmation struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+// ChangePassword sets a new password for the current user, who has to
+// give the current one again.
+func (s *HTTPHandler) ChangePassword(c *gin.Context) {
+	var request passwordChangeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		requestLogger(c).Error("Error binding json: " + err.Error())
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := currentUser(c)
+	audit(c, strconv.Itoa(userID), nil, nil)
+	err := s.MachineService.ChangePassword(c.Request.Context(), userID, request.CurrentPassword, request.NewPassword)
+	if errors.Is(err, models.ErrInvalidCredentials) {
+		requestLogger(c).Warn("Error changing password: " + err.Error())
+		c.JSON(403, gin.H{"error": "current password is incorrect"})
+		return
+	}
+	if err != nil {
+		requestLogger(c).Error("Error changing password: " + err.Error())
+		abortWithError(c, err)
+		return
+	}
+
+	c.Status(204)
+}
+
+// RequestPasswordReset sends a reset token to the user. The response is
+// the same whether the user exists or not.
+func (s *HTTPHandler) RequestPasswordReset(c *gin.Context) {
+	var request passwordResetRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		requestLogger(c).Error("Error binding json: " + err.Error())
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	auditActor(c, models.User{Username: request.Username})
+	if err := s.MachineService.RequestPasswordReset(c.Request.Context(), request.Username); err != nil {
+		requestLogger(c).Error("Error requesting password reset: " + err.Error())
+		if errors.Is(err, models.ErrResetUnavailable) {
+			abortWithError(c, err)
+			return
+		}
+	}
+	c.JSON(202, gin.H{"message": "if the user exists, a reset token has been sent"})
+}
+
+// ResetPassword sets a new password with a reset token. It lifts a
+// lockout and the login throttling of the user.
+func (s *HTTPHandler) ResetPassword(c *gin.Context) {
+	var request passwordResetConfirmation
+	if err := c.ShouldBindJSON(&request); err != nil {
+		requestLogger(c).Error("Error binding json: " + err.Error())
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := s.MachineService.ResetPassword(c.Request.Context(), request.Token, request.NewPassword)
+	if err != nil {
+		requestLogger(c).Error("Error resetting password: " + err.Error())
+		abortWithError(c, err)
+		return
+	}
+
+	s.throttle.reset(strings.ToLower(user.Username))
+	auditActor(c, user)
+	audit(c, strconv.Itoa(int(user.UserID)), nil, nil)
+	c.Status(204)
+}
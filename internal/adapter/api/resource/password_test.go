@@ -0,0 +1,126 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"net/http"
+	"strings"
+	"testing"
+	"verkaufsautomat/internal/core/domain/resource"
+)
+
+func TestApplication_Passwords(t *testing.T) {
+	mockedService, serve := newTestServer(t)
+
+	buyer, _ := testTokens.Generate(&resource.User{UserID: 2, RoleID: resource.BuyerRoleID, Username: "sally"})
+
+	recordAudit := func() *resource.AuditEntry {
+		entry := &resource.AuditEntry{}
+		mockedService.EXPECT().RecordAudit(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, recorded *resource.AuditEntry) error {
+			*entry = *recorded
+			return nil
+		})
+		return entry
+	}
+
+	t.Run("Password is changed for the current user", func(t *testing.T) {
+		entry := recordAudit()
+		mockedService.EXPECT().ChangePassword(gomock.Any(), 2, "old secret", "new secret").Return(nil)
+
+		response := serve("PUT", "/auth/me/password", buyer, `{"current_password":"old secret","new_password":"new secret"}`)
+
+		if response.Code != http.StatusNoContent {
+			t.Fatalf("Expected status code %d, got %d", http.StatusNoContent, response.Code)
+		}
+		if entry.Action != resource.AuditPasswordChange || entry.ActorID != 2 || entry.TargetID != "2" || !entry.Succeeded {
+			t.Errorf("Unexpected audit entry %+v", entry)
+		}
+	})
+
+	t.Run("Wrong current password is refused", func(t *testing.T) {
+		recordAudit()
+		mockedService.EXPECT().ChangePassword(gomock.Any(), 2, "guess", "new secret").Return(resource.ErrInvalidCredentials)
+
+		response := serve("PUT", "/auth/me/password", buyer, `{"current_password":"guess","new_password":"new secret"}`)
+
+		if response.Code != http.StatusForbidden {
+			t.Errorf("Expected status code %d, got %d", http.StatusForbidden, response.Code)
+		}
+	})
+
+	t.Run("Passwords the policy refuses are rejected", func(t *testing.T) {
+		recordAudit()
+		mockedService.EXPECT().ChangePassword(gomock.Any(), 2, "old secret", "short").Return(fmt.Errorf("%w: use at least 8 characters", resource.ErrPasswordTooShort))
+
+		response := serve("PUT", "/auth/me/password", buyer, `{"current_password":"old secret","new_password":"short"}`)
+
+		if response.Code != http.StatusBadRequest {
+			t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, response.Code)
+		}
+		if !strings.Contains(response.Body.String(), "at least 8 characters") {
+			t.Errorf("Expected the policy in the error, got %s", response.Body.String())
+		}
+	})
+
+	t.Run("Changing the password needs a token", func(t *testing.T) {
+		response := serve("PUT", "/auth/me/password", "", `{"current_password":"old secret","new_password":"new secret"}`)
+
+		if response.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status code %d, got %d", http.StatusUnauthorized, response.Code)
+		}
+	})
+
+	t.Run("Reset requests look the same for unknown users and failures", func(t *testing.T) {
+		recordAudit()
+		recordAudit()
+		mockedService.EXPECT().RequestPasswordReset(gomock.Any(), "nobody").Return(nil)
+		mockedService.EXPECT().RequestPasswordReset(gomock.Any(), "sally").Return(context.DeadlineExceeded)
+
+		unknown := serve("POST", "/api/v1/password/reset", "", `{"username":"nobody"}`)
+		failed := serve("POST", "/api/v1/password/reset", "", `{"username":"sally"}`)
+
+		if unknown.Code != http.StatusAccepted || failed.Code != http.StatusAccepted {
+			t.Fatalf("Expected status code %d, got %d and %d", http.StatusAccepted, unknown.Code, failed.Code)
+		}
+		if unknown.Body.String() != failed.Body.String() {
+			t.Errorf("Expected identical responses, got %s and %s", unknown.Body.String(), failed.Body.String())
+		}
+	})
+
+	t.Run("Reset requests are refused without a notifier", func(t *testing.T) {
+		recordAudit()
+		mockedService.EXPECT().RequestPasswordReset(gomock.Any(), "sally").Return(resource.ErrResetUnavailable)
+
+		response := serve("POST", "/api/v1/password/reset", "", `{"username":"sally"}`)
+
+		if response.Code != http.StatusServiceUnavailable {
+			t.Errorf("Expected status code %d, got %d", http.StatusServiceUnavailable, response.Code)
+		}
+	})
+
+	t.Run("Reset token sets a new password", func(t *testing.T) {
+		entry := recordAudit()
+		mockedService.EXPECT().ResetPassword(gomock.Any(), "reset-token", "new secret").Return(resource.User{UserID: 2, Username: "sally", RoleID: resource.BuyerRoleID}, nil)
+
+		response := serve("POST", "/api/v1/password/reset/confirm", "", `{"token":"reset-token","new_password":"new secret"}`)
+
+		if response.Code != http.StatusNoContent {
+			t.Fatalf("Expected status code %d, got %d", http.StatusNoContent, response.Code)
+		}
+		if entry.Action != resource.AuditPasswordReset || entry.ActorUsername != "sally" || entry.TargetID != "2" {
+			t.Errorf("Unexpected audit entry %+v", entry)
+		}
+	})
+
+	t.Run("Invalid reset tokens are rejected", func(t *testing.T) {
+		recordAudit()
+		mockedService.EXPECT().ResetPassword(gomock.Any(), "used-token", "new secret").Return(resource.User{}, resource.ErrInvalidResetToken)
+
+		response := serve("POST", "/api/v1/password/reset/confirm", "", `{"token":"used-token","new_password":"new secret"}`)
+
+		if response.Code != http.StatusBadRequest {
+			t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, response.Code)
+		}
+	})
+}
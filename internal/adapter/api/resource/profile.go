@@ -0,0 +1,92 @@
+package resource
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"strconv"
+	models "verkaufsautomat/internal/core/domain/resource"
+)
+
+type profileUpdate struct {
+	Username string `json:"username" binding:"required"`
+}
+
+type accountDeletion struct {
+	Password string `json:"password" binding:"required"`
+	// Refund pays out an outstanding deposit. Without it, accounts with a
+	// deposit are not deleted.
+	Refund bool `json:"refund"`
+}
+
+type accountDeletionResponse struct {
+	Message  string `json:"message"`
+	Refunded int    `json:"refunded"`
+}
+
+// GetProfile returns the current user's account, role, deposit and
+// permissions.
+func (s *HTTPHandler) GetProfile(c *gin.Context) {
+	userID, _ := currentUser(c)
+	profile, err := s.MachineService.GetProfile(c.Request.Context(), userID)
+	if err != nil {
+		requestLogger(c).Error("Error getting profile: " + err.Error())
+		abortWithError(c, err)
+		return
+	}
+	c.JSON(200, profile)
+}
+
+func (s *HTTPHandler) UpdateProfile(c *gin.Context) {
+	var request profileUpdate
+	if err := c.ShouldBindJSON(&request); err != nil {
+		requestLogger(c).Error("Error binding json: " + err.Error())
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := currentUser(c)
+	previous, err := s.MachineService.GetProfile(c.Request.Context(), userID)
+	if err != nil {
+		requestLogger(c).Error("Error getting profile: " + err.Error())
+		abortWithError(c, err)
+		return
+	}
+
+	profile, err := s.MachineService.UpdateProfile(c.Request.Context(), userID, request.Username)
+	if err != nil {
+		requestLogger(c).Error("Error updating profile: " + err.Error())
+		abortWithError(c, err)
+		return
+	}
+
+	audit(c, strconv.Itoa(userID), profileUpdate{Username: previous.Username}, profileUpdate{Username: profile.Username})
+	c.JSON(200, profile)
+}
+
+// DeleteAccount deletes the current user, who has to give the password
+// again, and clears the session cookies.
+func (s *HTTPHandler) DeleteAccount(c *gin.Context) {
+	var request accountDeletion
+	if err := c.ShouldBindJSON(&request); err != nil {
+		requestLogger(c).Error("Error binding json: " + err.Error())
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := currentUser(c)
+	audit(c, strconv.Itoa(userID), nil, nil)
+	refunded, err := s.MachineService.DeleteAccount(c.Request.Context(), userID, request.Password, request.Refund)
+	if errors.Is(err, models.ErrInvalidCredentials) {
+		requestLogger(c).Warn("Error deleting account: " + err.Error())
+		c.JSON(403, gin.H{"error": "password is incorrect"})
+		return
+	}
+	if err != nil {
+		requestLogger(c).Error("Error deleting account: " + err.Error())
+		abortWithError(c, err)
+		return
+	}
+
+	s.clearSessionCookies(c)
+	c.JSON(200, accountDeletionResponse{Message: "account deleted", Refunded: refunded})
+}
@@ -0,0 +1,122 @@
+package resource
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/golang/mock/gomock"
+	"net/http"
+	"strings"
+	"testing"
+	"verkaufsautomat/internal/core/domain/resource"
+)
+
+func TestApplication_Profile(t *testing.T) {
+	mockedService, serve := newTestServer(t)
+
+	buyer, _ := testTokens.Generate(&resource.User{UserID: 2, RoleID: resource.BuyerRoleID, Username: "sally"})
+	profile := resource.Profile{UserID: 2, Username: "sally", RoleID: resource.BuyerRoleID, Role: "buyer", Deposit: 150, Permissions: []string{"buy_product", "deposit_money"}}
+
+	recordAudit := func() *resource.AuditEntry {
+		entry := &resource.AuditEntry{}
+		mockedService.EXPECT().RecordAudit(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, recorded *resource.AuditEntry) error {
+			*entry = *recorded
+			return nil
+		})
+		return entry
+	}
+
+	t.Run("Profile shows the deposit and permissions", func(t *testing.T) {
+		mockedService.EXPECT().GetProfile(gomock.Any(), 2).Return(profile, nil)
+
+		response := serve("GET", "/auth/me", buyer, "")
+
+		if response.Code != http.StatusOK {
+			t.Fatalf("Expected status code %d, got %d", http.StatusOK, response.Code)
+		}
+		var body resource.Profile
+		if err := json.Unmarshal(response.Body.Bytes(), &body); err != nil {
+			t.Fatal(err)
+		}
+		if body.Deposit != 150 || body.Role != "buyer" || len(body.Permissions) != 2 {
+			t.Errorf("Unexpected profile %+v", body)
+		}
+		if strings.Contains(response.Body.String(), "password") {
+			t.Errorf("Expected no password in %s", response.Body.String())
+		}
+	})
+
+	t.Run("Profile update is recorded with before and after", func(t *testing.T) {
+		entry := recordAudit()
+		renamed := profile
+		renamed.Username = "sally2"
+		mockedService.EXPECT().GetProfile(gomock.Any(), 2).Return(profile, nil)
+		mockedService.EXPECT().UpdateProfile(gomock.Any(), 2, "sally2").Return(renamed, nil)
+
+		response := serve("PATCH", "/auth/me", buyer, `{"username":"sally2"}`)
+
+		if response.Code != http.StatusOK {
+			t.Fatalf("Expected status code %d, got %d", http.StatusOK, response.Code)
+		}
+		if entry.Action != resource.AuditProfileUpdate || entry.Before != `{"username":"sally"}` || entry.After != `{"username":"sally2"}` {
+			t.Errorf("Unexpected audit entry %+v", entry)
+		}
+	})
+
+	t.Run("Taken usernames conflict", func(t *testing.T) {
+		recordAudit()
+		mockedService.EXPECT().GetProfile(gomock.Any(), 2).Return(profile, nil)
+		mockedService.EXPECT().UpdateProfile(gomock.Any(), 2, "harry").Return(resource.Profile{}, resource.ErrUserExists)
+
+		response := serve("PATCH", "/auth/me", buyer, `{"username":"harry"}`)
+
+		if response.Code != http.StatusConflict {
+			t.Errorf("Expected status code %d, got %d", http.StatusConflict, response.Code)
+		}
+	})
+
+	t.Run("Accounts with a deposit are kept without a refund", func(t *testing.T) {
+		recordAudit()
+		mockedService.EXPECT().DeleteAccount(gomock.Any(), 2, "secret", false).Return(0, resource.ErrDepositOutstanding)
+
+		response := serve("DELETE", "/auth/me", buyer, `{"password":"secret"}`)
+
+		if response.Code != http.StatusConflict {
+			t.Errorf("Expected status code %d, got %d", http.StatusConflict, response.Code)
+		}
+	})
+
+	t.Run("Wrong password does not delete the account", func(t *testing.T) {
+		recordAudit()
+		mockedService.EXPECT().DeleteAccount(gomock.Any(), 2, "guess", true).Return(0, resource.ErrInvalidCredentials)
+
+		response := serve("DELETE", "/auth/me", buyer, `{"password":"guess","refund":true}`)
+
+		if response.Code != http.StatusForbidden {
+			t.Errorf("Expected status code %d, got %d", http.StatusForbidden, response.Code)
+		}
+	})
+
+	t.Run("Account is deleted with a refund", func(t *testing.T) {
+		entry := recordAudit()
+		mockedService.EXPECT().DeleteAccount(gomock.Any(), 2, "secret", true).Return(150, nil)
+
+		response := serve("DELETE", "/auth/me", buyer, `{"password":"secret","refund":true}`)
+
+		if response.Code != http.StatusOK {
+			t.Fatalf("Expected status code %d, got %d", http.StatusOK, response.Code)
+		}
+		var body accountDeletionResponse
+		if err := json.Unmarshal(response.Body.Bytes(), &body); err != nil {
+			t.Fatal(err)
+		}
+		if body.Refunded != 150 {
+			t.Errorf("Expected 150 refunded, got %d", body.Refunded)
+		}
+		if cookie := response.Header().Get("Set-Cookie"); !strings.Contains(cookie, "token=;") || !strings.Contains(cookie, "Max-Age=0") {
+			t.Errorf("Expected the token cookie to be cleared, got %q", cookie)
+		}
+		if entry.Action != resource.AuditAccountDelete || entry.TargetID != "2" || !entry.Succeeded {
+			t.Errorf("Unexpected audit entry %+v", entry)
+		}
+	})
+}
@@ -1,36 +1,107 @@
 package resource
 
 import (
-	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
-	"time"
+	models "verkaufsautomat/internal/core/domain/resource"
+	"verkaufsautomat/internal/core/logger"
 )
 
+// publicPaths need no credentials and get the public CORS policy.
+var publicPaths = []string{"/livez", "/readyz", "/api/v1/healthcheck", "/api/v1/openapi.json", "/api/v1/docs", "/api/v2/catalogue"}
+
 func (s *HTTPHandler) Routes(router *gin.Engine) {
+	// X-Forwarded-For is only believed from the configured proxies, as the
+	// login limits and the audit log rely on the client IP.
+	if err := router.SetTrustedProxies(s.Config.TrustedProxies); err != nil {
+		logger.Error("Error setting trusted proxies, trusting none: " + err.Error())
+		router.SetTrustedProxies(nil)
+	}
+
+	router.Use(RequestID())
+	router.Use(AccessLog())
+	router.Use(s.Deadline())
+	router.Use(s.BodyLimit())
+	router.Use(s.CORS(publicPaths...))
 
-	router.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"*", "http://localhost:8080"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "PATCH", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Length", "Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
-		AllowCredentials: true,
-		MaxAge:           12 * time.Hour,
-	}))
+	router.GET("/livez", s.Livez)
+	router.GET("/readyz", s.Readyz)
 
 	apirouter := router.Group("api/v1")
 	apirouter.GET("/healthcheck", s.HealthCheck)
+	apirouter.GET("/openapi.json", s.OpenAPI)
+	apirouter.GET("/docs", s.Docs)
 	apirouter.POST("/register", s.Register)
-	apirouter.POST("/login", s.Login)
+	apirouter.POST("/login", s.Audited(models.AuditLogin, "user"), s.LoginThrottle(), s.Login)
+	apirouter.POST("/login/mfa", s.Audited(models.AuditLoginMFA, "user"), s.LoginThrottle(), s.VerifyMFA)
+	apirouter.POST("/login/mfa/enroll", s.LoginThrottle(), s.EnrollMFAWithChallenge)
+	apirouter.POST("/logout", s.Logout)
+	apirouter.POST("/password/reset", s.Audited(models.AuditPasswordResetRequest, "user"), s.LoginThrottle(), s.RequestPasswordReset)
+	apirouter.POST("/password/reset/confirm", s.Audited(models.AuditPasswordReset, "user"), s.LoginThrottle(), s.ResetPassword)
 
 	auth := router.Group("/auth")
 	auth.Use(s.AuthMiddleware())
-	auth.POST("/create_product", s.CreateProduct)
-	auth.GET("/get_products", s.GetProducts)
-	auth.GET("/get_product/:id", s.GetProduct)
-	auth.PUT("/update_product/:id", s.UpdateProduct)
-	auth.DELETE("/delete_product/:id", s.DeleteProduct)
-	auth.PATCH("deposit_money", s.DepositMoney)
-	auth.POST("/buy_product", s.BuyProduct)
-	auth.PATCH("reset_deposit", s.ResetDeposit)
+	auth.POST("/create_product", Deprecated("/api/v2/products"), s.Audited(models.AuditProductCreate, "product"), s.CreateProduct)
+	auth.GET("/get_products", Deprecated("/api/v2/products"), s.GetProducts)
+	auth.GET("/get_product/:id", Deprecated("/api/v2/products/{id}"), s.GetProduct)
+	auth.PUT("/update_product/:id", Deprecated("/api/v2/products/{id}"), s.Audited(models.AuditProductUpdate, "product"), s.UpdateProduct)
+	auth.DELETE("/delete_product/:id", Deprecated("/api/v2/products/{id}"), s.Audited(models.AuditProductDelete, "product"), s.DeleteProduct)
+	auth.PATCH("deposit_money", Deprecated("/api/v2/me/deposit"), s.Audited(models.AuditDeposit, "user"), s.DepositMoney)
+	auth.POST("/buy_product", Deprecated("/api/v2/orders"), s.Audited(models.AuditPurchase, "order"), s.BuyProduct)
+	auth.PATCH("reset_deposit", Deprecated("/api/v2/me/deposit"), s.Audited(models.AuditDepositReset, "user"), s.ResetDeposit)
+	auth.GET("/me", s.GetProfile)
+	auth.PATCH("/me", s.Audited(models.AuditProfileUpdate, "user"), s.UpdateProfile)
+	auth.DELETE("/me", s.Audited(models.AuditAccountDelete, "user"), s.DeleteAccount)
+	auth.PUT("/me/password", s.Audited(models.AuditPasswordChange, "user"), s.ChangePassword)
+	auth.POST("/me/mfa", s.EnrollMFA)
+	auth.POST("/me/mfa/confirm", s.Audited(models.AuditMFAEnable, "user"), s.ConfirmMFA)
+	auth.DELETE("/me/mfa", s.Audited(models.AuditMFADisable, "user"), s.DisableMFA)
+	auth.POST("/me/mfa/recovery-codes", s.Audited(models.AuditMFARecoveryCodes, "user"), s.RegenerateRecoveryCodes)
+
+	catalogue := router.Group("/api/v2/catalogue")
+	catalogue.GET("", s.GetCatalogue)
+	catalogue.HEAD("", s.GetCatalogue)
+	catalogue.GET("/categories", s.GetCatalogueCategories)
+	catalogue.HEAD("/categories", s.GetCatalogueCategories)
+	catalogue.GET("/:id", s.GetCatalogueProduct)
+	catalogue.HEAD("/:id", s.GetCatalogueProduct)
+
+	v2 := router.Group("/api/v2")
+	v2.Use(s.AuthMiddleware())
+	v2.GET("/products", s.GetProductsV2)
+	v2.GET("/products/:id", s.GetProductV2)
+	v2.POST("/products", s.Audited(models.AuditProductCreate, "product"), RequireRole(models.SellerRoleID, "create product"), s.CreateProductV2)
+	v2.PUT("/products/:id", s.Audited(models.AuditProductUpdate, "product"), RequireRole(models.SellerRoleID, "update product"), s.UpdateProductV2)
+	v2.DELETE("/products/:id", s.Audited(models.AuditProductDelete, "product"), RequireRole(models.SellerRoleID, "delete product"), s.DeleteProductV2)
+	v2.GET("/categories", s.GetCategories)
+	v2.GET("/categories/:id", s.GetCategory)
+	v2.POST("/categories", s.Audited(models.AuditCategoryCreate, "category"), RequireRole(models.AdminRoleID, "create category"), s.CreateCategory)
+	v2.PUT("/categories/:id", s.Audited(models.AuditCategoryUpdate, "category"), RequireRole(models.AdminRoleID, "update category"), s.UpdateCategory)
+	v2.DELETE("/categories/:id", s.Audited(models.AuditCategoryDelete, "category"), RequireRole(models.AdminRoleID, "delete category"), s.DeleteCategory)
+	v2.GET("/me/deposit", RequireRole(models.BuyerRoleID, "view deposit"), s.GetDepositV2)
+	v2.POST("/me/deposit", s.Audited(models.AuditDeposit, "user"), RequireRole(models.BuyerRoleID, "deposit money"), s.DepositV2)
+	v2.DELETE("/me/deposit", s.Audited(models.AuditDepositReset, "user"), RequireRole(models.BuyerRoleID, "reset deposit"), s.ResetDepositV2)
+	v2.GET("/orders", RequireRole(models.BuyerRoleID, "view orders"), s.GetOrdersV2)
+	v2.GET("/orders/:id", RequireRole(models.BuyerRoleID, "view orders"), s.GetOrderV2)
+	v2.POST("/orders", s.Audited(models.AuditPurchase, "order"), RequireRole(models.BuyerRoleID, "buy product"), s.CreateOrderV2)
+	v2.GET("/events", s.StreamEvents)
+
+	webhooks := v2.Group("/webhooks")
+	webhooks.Use(RequireRole(models.SellerRoleID, "manage webhooks"))
+	webhooks.POST("", s.CreateWebhook)
+	webhooks.GET("", s.GetWebhooks)
+	webhooks.DELETE("/:id", s.DeleteWebhook)
+	webhooks.GET("/:id/deliveries", s.GetWebhookDeliveries)
+	webhooks.POST("/:id/deliveries/:delivery_id/replay", s.ReplayWebhookDelivery)
+
+	admin := v2.Group("/admin")
+	admin.Use(RequireRole(models.AdminRoleID, "administer"))
+	admin.PUT("/users/:id/role", s.Audited(models.AuditRoleChange, "user"), s.ChangeUserRole)
+	admin.POST("/users/:id/unlock", s.Audited(models.AuditUserUnlock, "user"), s.UnlockUser)
+	admin.DELETE("/users/:id/mfa", s.Audited(models.AuditMFAReset, "user"), s.ResetMFA)
+	admin.GET("/api-keys", s.GetAPIKeys)
+	admin.POST("/api-keys", s.Audited(models.AuditAPIKeyCreate, "api_key"), s.CreateAPIKey)
+	admin.DELETE("/api-keys/:id", s.Audited(models.AuditAPIKeyRevoke, "api_key"), s.RevokeAPIKey)
+	admin.GET("/audit", s.GetAuditEntries)
+	admin.GET("/audit/export", s.ExportAuditEntries)
 	router.NoRoute(func(c *gin.Context) { c.JSON(404, "no route") })
 }
@@ -1,29 +1,198 @@
 package resource
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"strconv"
+	"sync"
+	"time"
+	"verkaufsautomat/internal/config"
+	models "verkaufsautomat/internal/core/domain/resource"
+	"verkaufsautomat/internal/core/logger"
+	"verkaufsautomat/internal/core/token"
 	ports "verkaufsautomat/internal/ports/resource"
 )
 
+const (
+	userIDKey    = "user_id"
+	roleIDKey    = "role_id"
+	usernameKey  = "username"
+	requestIDKey = "request_id"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+var tracer = otel.Tracer("verkaufsautomat/internal/adapter/api/resource")
+
 type HTTPHandler struct {
 	MachineService ports.MachineService
+	Events         ports.EventSubscriber
+	Tokens         *token.Issuer
+	Config         config.HTTP
+	// DefaultTimeout is the deadline of routes without an entry in
+	// Timeouts. Zero means no deadline.
+	DefaultTimeout time.Duration
+	Timeouts       map[string]time.Duration
+
+	closing   chan struct{}
+	closeOnce sync.Once
+	throttle  *loginThrottle
+}
+
+// CloseStreams ends open event streams, which would otherwise keep a
+// graceful shutdown waiting until it times out.
+func (s *HTTPHandler) CloseStreams() {
+	s.closeOnce.Do(func() { close(s.closing) })
 }
 
+// AuthMiddleware accepts a login token or an API key as bearer credential,
+// or the session cookie set by Login. Requests authenticated by cookie that
+// change anything must also send the session's CSRF token in CSRFHeader.
 func (s *HTTPHandler) AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		err := TokenValid(c)
+		credential, fromCookie := sessionCredential(c)
+		if !fromCookie && models.IsAPIKey(credential) {
+			s.authenticateAPIKey(c, credential)
+			return
+		}
+
+		ctx, span := tracer.Start(c.Request.Context(), "AuthMiddleware.VerifyToken")
+		user, err := s.MachineService.AuthenticateToken(ctx, credential)
 		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+		if errors.Is(err, models.ErrInvalidToken) {
+			requestLogger(c).Warn("Error verifying token: " + err.Error())
 			c.JSON(401, "Unauthorized")
 			c.Abort()
 			return
 		}
+		if err != nil {
+			requestLogger(c).Error("Error verifying token: " + err.Error())
+			abortWithError(c, err)
+			return
+		}
+		if fromCookie && !safeMethod(c.Request.Method) && !s.validCSRF(c, credential) {
+			requestLogger(c).Warn("Missing or invalid CSRF token")
+			c.AbortWithStatusJSON(403, gin.H{"error": "missing or invalid CSRF token"})
+			return
+		}
+		c.Set(userIDKey, int(user.UserID))
+		c.Set(roleIDKey, int(user.RoleID))
+		c.Set(usernameKey, user.Username)
+		c.Request = c.Request.WithContext(logger.NewContext(c.Request.Context(), logger.Fields{"user_id": int(user.UserID)}))
+		c.Next()
+	}
+}
+
+// RequestID tags every request with an ID, taken from the X-Request-ID header
+// when the client or a proxy sent a usable one, and echoes it in the response.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		c.Set(requestIDKey, id)
+		c.Header(RequestIDHeader, id)
+		fields := logger.Fields{"request_id": id}
+		if span := trace.SpanContextFromContext(c.Request.Context()); span.IsValid() {
+			fields["trace_id"] = span.TraceID().String()
+		}
+		c.Request = c.Request.WithContext(logger.NewContext(c.Request.Context(), fields))
 		c.Next()
 	}
 }
 
-func NewHTTPHandler(MachineService ports.MachineService) *HTTPHandler {
+// AccessLog writes one entry per request once it has been handled, tagged
+// with the request and user IDs.
+func AccessLog() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		entry := requestLogger(c).With(logger.Fields{
+			"method":     c.Request.Method,
+			"path":       c.Request.URL.Path,
+			"status":     c.Writer.Status(),
+			"latency_ms": time.Since(start).Milliseconds(),
+			"ip":         c.ClientIP(),
+		})
+		message := c.Request.Method + " " + c.Request.URL.Path + " " + strconv.Itoa(c.Writer.Status())
+		switch {
+		case c.Writer.Status() >= 500:
+			entry.Error(message)
+		case c.Writer.Status() >= 400:
+			entry.Warn(message)
+		default:
+			entry.Info(message)
+		}
+	}
+}
+
+// requestLogger returns a logger carrying the request and user IDs.
+func requestLogger(c *gin.Context) logger.Logger {
+	return logger.Ctx(c.Request.Context())
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for _, r := range id {
+		if r < '!' || r > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// Deprecated marks a v1 route as superseded by the given v2 route so that
+// clients can discover the replacement before v1 is removed.
+func Deprecated(successor string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Deprecation", "true")
+		c.Header("Link", "<"+successor+">; rel=\"successor-version\"")
+		c.Next()
+	}
+}
+
+// currentUser returns the user and role IDs stored by AuthMiddleware.
+func currentUser(c *gin.Context) (int, int) {
+	return c.GetInt(userIDKey), c.GetInt(roleIDKey)
+}
+
+func NewHTTPHandler(MachineService ports.MachineService, Events ports.EventSubscriber, Tokens *token.Issuer, Config config.HTTP) *HTTPHandler {
 	handler := &HTTPHandler{
 		MachineService: MachineService,
+		Events:         Events,
+		Tokens:         Tokens,
+		Config:         Config,
+		DefaultTimeout: Config.RequestTimeout,
+		closing:        make(chan struct{}),
+		throttle:       newLoginThrottle(Config.Login),
+		Timeouts: map[string]time.Duration{
+			// The event stream stays open for as long as the client listens.
+			"GET /api/v2/events": 0,
+			// Probes are polled often; a stuck database should fail them fast.
+			"GET /readyz": 2 * time.Second,
+		},
+	}
+	for route, timeout := range Config.RouteTimeouts {
+		handler.Timeouts[route] = timeout
 	}
 	return handler
 }
@@ -0,0 +1,105 @@
+package resource
+
+import (
+	"crypto/subtle"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	models "verkaufsautomat/internal/core/domain/resource"
+)
+
+const (
+	// sessionCookie holds the login token of browser clients. It is
+	// HttpOnly, so scripts cannot read it.
+	sessionCookie = "token"
+	// csrfCookie holds the CSRF token, which scripts read and send back in
+	// CSRFHeader. Another site can make the browser send the cookie but
+	// cannot read it, so it cannot fill in the header. The token is derived
+	// from the login token, so it is only valid for its own session.
+	csrfCookie = "csrf_token"
+	CSRFHeader = "X-CSRF-Token"
+)
+
+// sessionCredential returns the bearer credential, or the session cookie of
+// a browser client when there is none.
+func sessionCredential(c *gin.Context) (credential string, fromCookie bool) {
+	if credential := bearerCredential(c); credential != "" {
+		return credential, false
+	}
+	token, err := c.Cookie(sessionCookie)
+	if err != nil || token == "" {
+		return "", false
+	}
+	return token, true
+}
+
+// validCSRF reports whether the request carries the CSRF token of the
+// session with the login token in CSRFHeader.
+func (s *HTTPHandler) validCSRF(c *gin.Context, token string) bool {
+	csrf := s.Tokens.CSRFToken(token)
+	return subtle.ConstantTimeCompare([]byte(csrf), []byte(c.GetHeader(CSRFHeader))) == 1
+}
+
+// safeMethod reports whether the method only reads, so cookie sessions need
+// no CSRF token for it.
+func safeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+// setSessionCookies starts a cookie session with the login token.
+func (s *HTTPHandler) setSessionCookies(c *gin.Context, token, csrf string) {
+	maxAge := int(s.Tokens.Expiry().Seconds())
+	s.setCookie(c, sessionCookie, token, maxAge, true)
+	s.setCookie(c, csrfCookie, csrf, maxAge, false)
+}
+
+func (s *HTTPHandler) clearSessionCookies(c *gin.Context) {
+	s.setCookie(c, sessionCookie, "", -1, true)
+	s.setCookie(c, csrfCookie, "", -1, false)
+}
+
+func (s *HTTPHandler) setCookie(c *gin.Context, name, value string, maxAge int, httpOnly bool) {
+	c.SetSameSite(sameSiteMode(s.Config.CookieSameSite))
+	c.SetCookie(name, value, maxAge, "/", s.Config.CookieDomain, s.Config.CookieSecure, httpOnly)
+}
+
+func sameSiteMode(mode string) http.SameSite {
+	switch mode {
+	case "strict":
+		return http.SameSiteStrictMode
+	case "none":
+		return http.SameSiteNoneMode
+	default:
+		return http.SameSiteLaxMode
+	}
+}
+
+// Logout revokes the login token of the request, as bearer token or session
+// cookie, and with it the user's other login tokens, and clears the session
+// cookies. Cookie sessions must send their CSRF token, so that other sites
+// cannot log users out. Requests with an invalid or no token only get the
+// cookies cleared.
+func (s *HTTPHandler) Logout(c *gin.Context) {
+	if credential, fromCookie := sessionCredential(c); credential != "" && !models.IsAPIKey(credential) {
+		user, err := s.MachineService.AuthenticateToken(c.Request.Context(), credential)
+		if err != nil && !errors.Is(err, models.ErrInvalidToken) {
+			requestLogger(c).Error("Error verifying token: " + err.Error())
+			abortWithError(c, err)
+			return
+		}
+		if err == nil {
+			if fromCookie && !s.validCSRF(c, credential) {
+				requestLogger(c).Warn("Missing or invalid CSRF token")
+				c.AbortWithStatusJSON(403, gin.H{"error": "missing or invalid CSRF token"})
+				return
+			}
+			if err := s.MachineService.Logout(c.Request.Context(), int(user.UserID)); err != nil {
+				requestLogger(c).Error("Error revoking tokens: " + err.Error())
+				abortWithError(c, err)
+				return
+			}
+		}
+	}
+	s.clearSessionCookies(c)
+	c.Status(204)
+}
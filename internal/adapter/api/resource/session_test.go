@@ -0,0 +1,225 @@
+package resource
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"verkaufsautomat/internal/config"
+	"verkaufsautomat/internal/core/domain/resource"
+	"verkaufsautomat/internal/core/events"
+	services "verkaufsautomat/internal/core/services/mock"
+)
+
+func TestApplication_Session(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockedService := services.NewMockMachineService(ctrl)
+	acceptTokens(mockedService)
+	settings := config.Default().HTTP
+	settings.CookieSameSite = "strict"
+	handler := NewHTTPHandler(mockedService, events.NewBus(), testTokens, settings)
+
+	router := gin.Default()
+
+	handler.Routes(router)
+
+	mockedService.EXPECT().RecordAudit(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	user := resource.User{UserID: 2, RoleID: resource.BuyerRoleID, Username: "sally"}
+	token, _ := testTokens.Generate(&user)
+	profile := resource.Profile{UserID: 2, Username: "sally", RoleID: resource.BuyerRoleID, Role: "buyer"}
+
+	serve := func(method, path, body string, cookies []*http.Cookie, headers map[string]string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest(method, path, strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		for _, cookie := range cookies {
+			req.AddCookie(cookie)
+		}
+
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, req)
+		return response
+	}
+
+	csrf := testTokens.CSRFToken(token)
+	session := []*http.Cookie{{Name: "token", Value: token}, {Name: "csrf_token", Value: csrf}}
+
+	t.Run("Login starts a cookie session", func(t *testing.T) {
+		mockedService.EXPECT().Login(gomock.Any(), "sally", "secret").Return(user, token, nil)
+
+		response := serve("POST", "/api/v1/login", `{"username":"sally","password":"secret"}`, nil, nil)
+
+		if response.Code != http.StatusOK {
+			t.Fatalf("Expected status code %d, got %d", http.StatusOK, response.Code)
+		}
+		var body struct {
+			Token     string `json:"token"`
+			CSRFToken string `json:"csrf_token"`
+		}
+		if err := json.Unmarshal(response.Body.Bytes(), &body); err != nil {
+			t.Fatal(err)
+		}
+		cookies := map[string]*http.Cookie{}
+		for _, cookie := range response.Result().Cookies() {
+			cookies[cookie.Name] = cookie
+		}
+		if cookie := cookies["token"]; cookie == nil || cookie.Value != token || !cookie.HttpOnly || cookie.SameSite != http.SameSiteStrictMode {
+			t.Errorf("Expected an HttpOnly, SameSite=Strict token cookie, got %+v", cookie)
+		}
+		if cookie := cookies["csrf_token"]; cookie == nil || cookie.Value != csrf || cookie.Value != body.CSRFToken || cookie.HttpOnly {
+			t.Errorf("Expected a readable csrf_token cookie matching %q, got %+v", body.CSRFToken, cookie)
+		}
+	})
+
+	t.Run("The session cookie authenticates reads", func(t *testing.T) {
+		mockedService.EXPECT().GetProfile(gomock.Any(), 2).Return(profile, nil)
+
+		response := serve("GET", "/auth/me", "", session, nil)
+
+		if response.Code != http.StatusOK {
+			t.Errorf("Expected status code %d, got %d", http.StatusOK, response.Code)
+		}
+	})
+
+	t.Run("Changes need the CSRF token", func(t *testing.T) {
+		other, _ := testTokens.Generate(&resource.User{UserID: 3, RoleID: resource.BuyerRoleID, Username: "harry"})
+		for name, headers := range map[string]map[string]string{
+			"missing":       nil,
+			"wrong":         {CSRFHeader: "forged"},
+			"other session": {CSRFHeader: testTokens.CSRFToken(other)},
+		} {
+			response := serve("PATCH", "/auth/me", `{"username":"sally2"}`, session, headers)
+
+			if response.Code != http.StatusForbidden {
+				t.Errorf("%s: Expected status code %d, got %d", name, http.StatusForbidden, response.Code)
+			}
+		}
+	})
+
+	t.Run("Changes with the CSRF token pass", func(t *testing.T) {
+		mockedService.EXPECT().GetProfile(gomock.Any(), 2).Return(profile, nil)
+		mockedService.EXPECT().UpdateProfile(gomock.Any(), 2, "sally2").Return(profile, nil)
+
+		response := serve("PATCH", "/auth/me", `{"username":"sally2"}`, session, map[string]string{CSRFHeader: csrf})
+
+		if response.Code != http.StatusOK {
+			t.Errorf("Expected status code %d, got %d", http.StatusOK, response.Code)
+		}
+	})
+
+	t.Run("Bearer tokens need no CSRF token", func(t *testing.T) {
+		mockedService.EXPECT().GetProfile(gomock.Any(), 2).Return(profile, nil)
+		mockedService.EXPECT().UpdateProfile(gomock.Any(), 2, "sally2").Return(profile, nil)
+
+		response := serve("PATCH", "/auth/me", `{"username":"sally2"}`, session, map[string]string{"Authorization": "Bearer " + token})
+
+		if response.Code != http.StatusOK {
+			t.Errorf("Expected status code %d, got %d", http.StatusOK, response.Code)
+		}
+	})
+
+	t.Run("Invalid session cookie", func(t *testing.T) {
+		response := serve("GET", "/auth/me", "", []*http.Cookie{{Name: "token", Value: "invalid"}}, nil)
+
+		if response.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status code %d, got %d", http.StatusUnauthorized, response.Code)
+		}
+	})
+
+	t.Run("Logout revokes the tokens and clears the session cookies", func(t *testing.T) {
+		mockedService.EXPECT().Logout(gomock.Any(), 2).Return(nil)
+
+		response := serve("POST", "/api/v1/logout", "", session, map[string]string{CSRFHeader: csrf})
+
+		if response.Code != http.StatusNoContent {
+			t.Fatalf("Expected status code %d, got %d", http.StatusNoContent, response.Code)
+		}
+		cleared := map[string]bool{}
+		for _, cookie := range response.Result().Cookies() {
+			cleared[cookie.Name] = cookie.Value == "" && cookie.MaxAge < 0
+		}
+		if !cleared["token"] || !cleared["csrf_token"] {
+			t.Errorf("Expected both session cookies to be cleared, got %v", response.Header()["Set-Cookie"])
+		}
+	})
+	t.Run("Logout of a cookie session needs the CSRF token", func(t *testing.T) {
+		response := serve("POST", "/api/v1/logout", "", session, nil)
+
+		if response.Code != http.StatusForbidden {
+			t.Errorf("Expected status code %d, got %d", http.StatusForbidden, response.Code)
+		}
+	})
+
+	t.Run("Logout revokes bearer tokens", func(t *testing.T) {
+		mockedService.EXPECT().Logout(gomock.Any(), 2).Return(nil)
+
+		response := serve("POST", "/api/v1/logout", "", nil, map[string]string{"Authorization": "Bearer " + token})
+
+		if response.Code != http.StatusNoContent {
+			t.Errorf("Expected status code %d, got %d", http.StatusNoContent, response.Code)
+		}
+	})
+
+	t.Run("Logout with an invalid token only clears the cookies", func(t *testing.T) {
+		response := serve("POST", "/api/v1/logout", "", []*http.Cookie{{Name: "token", Value: "invalid"}}, nil)
+
+		if response.Code != http.StatusNoContent {
+			t.Errorf("Expected status code %d, got %d", http.StatusNoContent, response.Code)
+		}
+	})
+}
+
+func TestApplication_RevokedToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockedService := services.NewMockMachineService(ctrl)
+	handler := NewHTTPHandler(mockedService, events.NewBus(), testTokens, config.Default().HTTP)
+
+	router := gin.Default()
+
+	handler.Routes(router)
+
+	token, _ := testTokens.Generate(&resource.User{UserID: 2, RoleID: resource.AdminRoleID, Username: "sally"})
+	get := func() *httptest.ResponseRecorder {
+		req, err := http.NewRequest("GET", "/api/v2/me/deposit", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, req)
+		return response
+	}
+
+	t.Run("Tokens of deleted users or from before a password or role change are refused", func(t *testing.T) {
+		mockedService.EXPECT().AuthenticateToken(gomock.Any(), token).Return(resource.User{}, resource.ErrInvalidToken)
+
+		if response := get(); response.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status code %d, got %d", http.StatusUnauthorized, response.Code)
+		}
+	})
+
+	t.Run("The role is taken from the user, not the token", func(t *testing.T) {
+		user := resource.User{UserID: 2, RoleID: resource.BuyerRoleID, Username: "sally"}
+		mockedService.EXPECT().AuthenticateToken(gomock.Any(), token).Return(user, nil)
+		mockedService.EXPECT().GetUserById(gomock.Any(), 2).Return(resource.User{UserID: 2, RoleID: resource.BuyerRoleID, Deposit: 35}, nil)
+
+		response := get()
+		if response.Code != http.StatusOK {
+			t.Fatalf("Expected status code %d, got %d", http.StatusOK, response.Code)
+		}
+		if response.Body.String() != `{"deposit":35}` {
+			t.Errorf("Unexpected body %s", response.Body.String())
+		}
+	})
+}
@@ -0,0 +1,209 @@
+package resource
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"verkaufsautomat/internal/config"
+)
+
+// failureMemory is how long failed logins count towards the delay of the
+// next attempt.
+const failureMemory = 15 * time.Minute
+
+// loginThrottle counts login attempts per client IP and per username in
+// fixed windows, and failed logins per username for progressive delays.
+// State is kept in memory, so every instance throttles on its own.
+type loginThrottle struct {
+	limits config.LoginLimits
+	now    func() time.Time
+
+	mu       sync.Mutex
+	windows  map[string]*attemptWindow
+	failures map[string]*failedLogins
+	pruned   time.Time
+}
+
+type attemptWindow struct {
+	start time.Time
+	count int
+}
+
+type failedLogins struct {
+	count int
+	last  time.Time
+}
+
+func newLoginThrottle(limits config.LoginLimits) *loginThrottle {
+	return &loginThrottle{
+		limits:   limits,
+		now:      time.Now,
+		windows:  map[string]*attemptWindow{},
+		failures: map[string]*failedLogins{},
+	}
+}
+
+// allow counts an attempt and reports how long to wait if the IP or the
+// username used up its window.
+func (t *loginThrottle) allow(ip, username string) (time.Duration, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	t.prune(now)
+	wait, ok := t.count("ip:"+ip, t.limits.IPLimit, now)
+	if username != "" {
+		if userWait, userOK := t.count("user:"+username, t.limits.UsernameLimit, now); !userOK {
+			ok = false
+			if userWait > wait {
+				wait = userWait
+			}
+		}
+	}
+	return wait, ok
+}
+
+func (t *loginThrottle) count(key string, limit int, now time.Time) (time.Duration, bool) {
+	window, ok := t.windows[key]
+	if !ok || now.Sub(window.start) >= t.limits.Window {
+		window = &attemptWindow{start: now}
+		t.windows[key] = window
+	}
+	if window.count >= limit {
+		return window.start.Add(t.limits.Window).Sub(now), false
+	}
+	window.count++
+	return 0, true
+}
+
+// delay returns how long the next attempt for username is held back: Delay
+// after one failure, doubling with each further one up to MaxDelay.
+func (t *loginThrottle) delay(username string) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	failures, ok := t.failures[username]
+	if !ok || t.now().Sub(failures.last) >= failureMemory || t.limits.Delay <= 0 {
+		return 0
+	}
+	delay := t.limits.Delay
+	for i := 1; i < failures.count && delay < t.limits.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > t.limits.MaxDelay {
+		delay = t.limits.MaxDelay
+	}
+	return delay
+}
+
+func (t *loginThrottle) fail(username string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	failures, ok := t.failures[username]
+	if !ok || now.Sub(failures.last) >= failureMemory {
+		failures = &failedLogins{}
+		t.failures[username] = failures
+	}
+	failures.count++
+	failures.last = now
+}
+
+// reset forgets the failures and attempts of username, after a successful
+// login or when an admin unlocks the account.
+func (t *loginThrottle) reset(username string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.failures, username)
+	delete(t.windows, "user:"+username)
+}
+
+// prune drops expired windows and failures, at most once per window.
+func (t *loginThrottle) prune(now time.Time) {
+	if now.Sub(t.pruned) < t.limits.Window {
+		return
+	}
+	t.pruned = now
+	for key, window := range t.windows {
+		if now.Sub(window.start) >= t.limits.Window {
+			delete(t.windows, key)
+		}
+	}
+	for username, failures := range t.failures {
+		if now.Sub(failures.last) >= failureMemory {
+			delete(t.failures, username)
+		}
+	}
+}
+
+// LoginThrottle limits login attempts per client IP and per username,
+// answering 429 with Retry-After once a limit is reached, and delays
+// attempts for usernames that failed recently. Unknown usernames are
+// throttled just like existing ones.
+func (s *HTTPHandler) LoginThrottle() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		username := s.loginUsername(c)
+		if wait, ok := s.throttle.allow(c.ClientIP(), username); !ok {
+			requestLogger(c).Warn("Login attempts throttled")
+			c.Header("Retry-After", strconv.Itoa(int((wait+time.Second-1)/time.Second)))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "too many login attempts, try again later"})
+			return
+		}
+
+		if delay := s.throttle.delay(username); delay > 0 {
+			timer := time.NewTimer(delay)
+			select {
+			case <-timer.C:
+			case <-c.Request.Context().Done():
+				timer.Stop()
+				c.Abort()
+				return
+			}
+		}
+
+		c.Next()
+
+		switch c.Writer.Status() {
+		case http.StatusOK:
+			s.throttle.reset(username)
+		case http.StatusUnauthorized:
+			if username != "" {
+				s.throttle.fail(username)
+			}
+		}
+	}
+}
+
+// loginUsername reads the username from the login request, or from the
+// challenge token of the second login step, and puts the body back for the
+// handler.
+func (s *HTTPHandler) loginUsername(c *gin.Context) string {
+	if c.Request.Body == nil {
+		return ""
+	}
+	body, err := io.ReadAll(c.Request.Body)
+	c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), c.Request.Body))
+	if err != nil {
+		return ""
+	}
+
+	var credentials struct {
+		Username       string `json:"username"`
+		ChallengeToken string `json:"challenge_token"`
+	}
+	json.Unmarshal(body, &credentials)
+	if credentials.Username == "" && credentials.ChallengeToken != "" {
+		if claims, err := s.Tokens.ParseChallenge(credentials.ChallengeToken); err == nil {
+			credentials.Username = claims.Username
+		}
+	}
+	return strings.ToLower(strings.TrimSpace(credentials.Username))
+}
@@ -0,0 +1,149 @@
+package resource
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"verkaufsautomat/internal/config"
+	"verkaufsautomat/internal/core/domain/resource"
+	"verkaufsautomat/internal/core/events"
+	services "verkaufsautomat/internal/core/services/mock"
+)
+
+func TestApplication_LoginThrottle(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockedService := services.NewMockMachineService(ctrl)
+	acceptTokens(mockedService)
+	mockedService.EXPECT().RecordAudit(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	cfg := config.Default().HTTP
+	cfg.Login = config.LoginLimits{Window: time.Minute, IPLimit: 4, UsernameLimit: 2, Delay: time.Millisecond, MaxDelay: 2 * time.Millisecond}
+	handler := NewHTTPHandler(mockedService, events.NewBus(), testTokens, cfg)
+
+	router := gin.Default()
+
+	handler.Routes(router)
+
+	admin, _ := testTokens.Generate(&resource.User{UserID: 3, RoleID: resource.AdminRoleID, Username: "root"})
+
+	login := func(ip, username string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest("POST", "/api/v1/login", strings.NewReader(`{"username":"`+username+`","password":"guess"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.RemoteAddr = ip + ":4321"
+
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, req)
+		return response
+	}
+
+	t.Run("Unknown users and wrong passwords get the same response", func(t *testing.T) {
+		mockedService.EXPECT().Login(gomock.Any(), gomock.Any(), "guess").Return(resource.User{}, "", resource.ErrInvalidCredentials).Times(2)
+
+		unknown := login("10.0.0.1", "nobody")
+		wrong := login("10.0.0.1", "sally")
+
+		for _, response := range []*httptest.ResponseRecorder{unknown, wrong} {
+			if response.Code != http.StatusUnauthorized {
+				t.Errorf("Expected status code %d, got %d", http.StatusUnauthorized, response.Code)
+			}
+		}
+		if unknown.Body.String() != wrong.Body.String() {
+			t.Errorf("Expected identical responses, got %s and %s", unknown.Body.String(), wrong.Body.String())
+		}
+	})
+
+	t.Run("Usernames are limited across IPs", func(t *testing.T) {
+		mockedService.EXPECT().Login(gomock.Any(), gomock.Any(), "guess").Return(resource.User{}, "", resource.ErrInvalidCredentials)
+
+		if response := login("10.0.0.2", "sally"); response.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status code %d, got %d", http.StatusUnauthorized, response.Code)
+		}
+		response := login("10.0.0.3", "SALLY")
+		if response.Code != http.StatusTooManyRequests {
+			t.Errorf("Expected status code %d, got %d", http.StatusTooManyRequests, response.Code)
+		}
+		if response.Header().Get("Retry-After") == "" {
+			t.Error("Expected a Retry-After header")
+		}
+	})
+
+	t.Run("IPs are limited across usernames", func(t *testing.T) {
+		mockedService.EXPECT().Login(gomock.Any(), gomock.Any(), "guess").Return(resource.User{}, "", resource.ErrInvalidCredentials).Times(2)
+
+		login("10.0.0.1", "harry")
+		login("10.0.0.1", "root")
+
+		if response := login("10.0.0.1", "tom"); response.Code != http.StatusTooManyRequests {
+			t.Errorf("Expected status code %d, got %d", http.StatusTooManyRequests, response.Code)
+		}
+	})
+
+	t.Run("Forwarded IPs from untrusted peers are ignored", func(t *testing.T) {
+		req, err := http.NewRequest("POST", "/api/v1/login", strings.NewReader(`{"username":"dick","password":"guess"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Forwarded-For", "192.0.2.77")
+		req.RemoteAddr = "10.0.0.1:4321"
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, req)
+
+		if response.Code != http.StatusTooManyRequests {
+			t.Errorf("Expected status code %d, got %d", http.StatusTooManyRequests, response.Code)
+		}
+	})
+
+	t.Run("Admins can unlock accounts", func(t *testing.T) {
+		mockedService.EXPECT().UnlockUser(gomock.Any(), 2).Return(resource.User{UserID: 2, Username: "Sally"}, nil)
+		mockedService.EXPECT().Login(gomock.Any(), "sally", "guess").Return(resource.User{UserID: 2, Username: "sally", RoleID: resource.BuyerRoleID}, "signed token", nil)
+
+		req, err := http.NewRequest("POST", "/api/v2/admin/users/2/unlock", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+admin)
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, req)
+
+		if response.Code != http.StatusNoContent {
+			t.Errorf("Expected status code %d, got %d", http.StatusNoContent, response.Code)
+		}
+		response = login("10.0.0.4", "sally")
+		if response.Code != http.StatusOK {
+			t.Errorf("Expected status code %d, got %d", http.StatusOK, response.Code)
+		}
+		var body map[string]string
+		json.Unmarshal(response.Body.Bytes(), &body)
+		if body["token"] != "signed token" || response.Header().Get("Authorization") != "Bearer signed token" {
+			t.Error("Expected the token issued by the service")
+		}
+	})
+}
+
+func TestLoginThrottle_Delay(t *testing.T) {
+	throttle := newLoginThrottle(config.LoginLimits{Window: time.Minute, IPLimit: 100, UsernameLimit: 100, Delay: 250 * time.Millisecond, MaxDelay: time.Second})
+	now := time.Now()
+	throttle.now = func() time.Time { return now }
+
+	expected := []time.Duration{0, 250 * time.Millisecond, 500 * time.Millisecond, time.Second, time.Second}
+	for failures, delay := range expected {
+		if actual := throttle.delay("sally"); actual != delay {
+			t.Errorf("Expected a delay of %v after %d failures, got %v", delay, failures, actual)
+		}
+		throttle.fail("sally")
+	}
+
+	now = now.Add(failureMemory)
+	if delay := throttle.delay("sally"); delay != 0 {
+		t.Errorf("Expected old failures to be forgotten, got a delay of %v", delay)
+	}
+}
@@ -0,0 +1,33 @@
+package resource
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+)
+
+// statusClientClosedRequest is logged when the client went away before the
+// response was ready. Nothing is sent, as nobody is listening.
+const statusClientClosedRequest = 499
+
+// Deadline bounds the time a request may take. The limit is looked up in
+// Timeouts by method and route pattern, such as "POST /api/v2/orders", and
+// falls back to DefaultTimeout; zero means no deadline. Service and
+// database calls are cancelled once it passes, just as they are when the
+// client disconnects.
+func (s *HTTPHandler) Deadline() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		timeout, ok := s.Timeouts[c.Request.Method+" "+c.FullPath()]
+		if !ok {
+			timeout = s.DefaultTimeout
+		}
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
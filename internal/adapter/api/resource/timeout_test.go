@@ -0,0 +1,71 @@
+package resource
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"verkaufsautomat/internal/config"
+	"verkaufsautomat/internal/core/domain/resource"
+	"verkaufsautomat/internal/core/events"
+	services "verkaufsautomat/internal/core/services/mock"
+)
+
+func TestApplication_Deadline(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockedService := services.NewMockMachineService(ctrl)
+	acceptTokens(mockedService)
+	handler := NewHTTPHandler(mockedService, events.NewBus(), testTokens, config.Default().HTTP)
+	handler.DefaultTimeout = 0
+	handler.Timeouts["GET /api/v2/products"] = 20 * time.Millisecond
+
+	router := gin.Default()
+
+	handler.Routes(router)
+
+	buyer, _ := testTokens.Generate(&resource.User{UserID: 2, RoleID: resource.BuyerRoleID, Username: "sally"})
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest("GET", path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+buyer)
+
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, req)
+		return response
+	}
+
+	t.Run("Slow requests are cancelled at the route deadline", func(t *testing.T) {
+		mockedService.EXPECT().SearchProducts(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, _ resource.ProductFilter) ([]resource.Product, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+
+		response := serve("/api/v2/products")
+
+		if response.Code != http.StatusGatewayTimeout {
+			t.Errorf("Expected status code %d, got %d", http.StatusGatewayTimeout, response.Code)
+		}
+	})
+
+	t.Run("Routes without a timeout have no deadline", func(t *testing.T) {
+		mockedService.EXPECT().GetProductById(gomock.Any(), 12).DoAndReturn(func(ctx context.Context, id int) (resource.Product, error) {
+			if _, ok := ctx.Deadline(); ok {
+				t.Error("Expected no deadline")
+			}
+			return resource.Product{ProductID: 12}, nil
+		})
+
+		response := serve("/api/v2/products/12")
+
+		if response.Code != http.StatusOK {
+			t.Errorf("Expected status code %d, got %d", http.StatusOK, response.Code)
+		}
+	})
+}
@@ -0,0 +1,166 @@
+package resource
+
+import (
+	"github.com/gin-gonic/gin"
+	"strconv"
+	"strings"
+	models "verkaufsautomat/internal/core/domain/resource"
+)
+
+type webhookRequest struct {
+	URL               string   `json:"url" binding:"required"`
+	Secret            string   `json:"secret"`
+	EventTypes        []string `json:"event_types"`
+	LowStockThreshold int      `json:"low_stock_threshold"`
+}
+
+type webhookResponse struct {
+	WebhookID         uint     `json:"webhook_id"`
+	URL               string   `json:"url"`
+	Secret            string   `json:"secret,omitempty"`
+	EventTypes        []string `json:"event_types"`
+	LowStockThreshold int      `json:"low_stock_threshold"`
+}
+
+// toWebhookResponse hides the secret unless it is being returned to the
+// seller who just created the webhook.
+func toWebhookResponse(webhook models.Webhook, withSecret bool) webhookResponse {
+	response := webhookResponse{
+		WebhookID:         webhook.WebhookID,
+		URL:               webhook.URL,
+		EventTypes:        strings.Split(webhook.EventTypes, ","),
+		LowStockThreshold: webhook.LowStockThreshold,
+	}
+	if withSecret {
+		response.Secret = webhook.Secret
+	}
+	return response
+}
+
+func (s *HTTPHandler) CreateWebhook(c *gin.Context) {
+	var request webhookRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		requestLogger(c).Error("Error binding json: " + err.Error())
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := currentUser(c)
+	webhook := models.Webhook{
+		SellerID:          uint(userID),
+		URL:               request.URL,
+		Secret:            request.Secret,
+		EventTypes:        strings.Join(request.EventTypes, ","),
+		LowStockThreshold: request.LowStockThreshold,
+	}
+
+	if err := s.MachineService.CreateWebhook(c.Request.Context(), &webhook); err != nil {
+		requestLogger(c).Error("Error creating webhook: " + err.Error())
+		abortWithError(c, err)
+		return
+	}
+
+	c.Header("Location", "/api/v2/webhooks/"+strconv.Itoa(int(webhook.WebhookID)))
+	c.JSON(201, toWebhookResponse(webhook, true))
+}
+
+func (s *HTTPHandler) GetWebhooks(c *gin.Context) {
+	userID, _ := currentUser(c)
+	webhooks, err := s.MachineService.GetWebhooksBySeller(c.Request.Context(), userID)
+	if err != nil {
+		requestLogger(c).Error("Error getting webhooks: " + err.Error())
+		abortWithError(c, err)
+		return
+	}
+
+	response := []webhookResponse{}
+	for _, webhook := range webhooks {
+		response = append(response, toWebhookResponse(webhook, false))
+	}
+	c.JSON(200, response)
+}
+
+// ownWebhook loads the webhook named in the path. Webhooks of other sellers
+// are reported as missing.
+func (s *HTTPHandler) ownWebhook(c *gin.Context) (models.Webhook, bool) {
+	id, ok := idParam(c)
+	if !ok {
+		return models.Webhook{}, false
+	}
+
+	webhook, err := s.MachineService.GetWebhookById(c.Request.Context(), id)
+	if err == nil {
+		if userID, _ := currentUser(c); webhook.SellerID != uint(userID) {
+			err = models.ErrWebhookNotFound
+		}
+	}
+	if err != nil {
+		requestLogger(c).Error("Error getting webhook: " + err.Error())
+		abortWithError(c, err)
+		return webhook, false
+	}
+
+	return webhook, true
+}
+
+func (s *HTTPHandler) DeleteWebhook(c *gin.Context) {
+	webhook, ok := s.ownWebhook(c)
+	if !ok {
+		return
+	}
+
+	if err := s.MachineService.DeleteWebhookByID(c.Request.Context(), int(webhook.WebhookID)); err != nil {
+		requestLogger(c).Error("Error deleting webhook: " + err.Error())
+		abortWithError(c, err)
+		return
+	}
+
+	c.Status(204)
+}
+
+func (s *HTTPHandler) GetWebhookDeliveries(c *gin.Context) {
+	webhook, ok := s.ownWebhook(c)
+	if !ok {
+		return
+	}
+
+	deliveries, err := s.MachineService.GetWebhookDeliveries(c.Request.Context(), int(webhook.WebhookID))
+	if err != nil {
+		requestLogger(c).Error("Error getting webhook deliveries: " + err.Error())
+		abortWithError(c, err)
+		return
+	}
+
+	if deliveries == nil {
+		deliveries = []models.WebhookDelivery{}
+	}
+	c.JSON(200, deliveries)
+}
+
+func (s *HTTPHandler) ReplayWebhookDelivery(c *gin.Context) {
+	webhook, ok := s.ownWebhook(c)
+	if !ok {
+		return
+	}
+
+	deliveryID, err := strconv.Atoi(c.Param("delivery_id"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "delivery_id must be an integer"})
+		return
+	}
+
+	delivery, err := s.MachineService.GetWebhookDeliveryById(c.Request.Context(), deliveryID)
+	if err == nil && delivery.WebhookID != webhook.WebhookID {
+		err = models.ErrDeliveryNotFound
+	}
+	if err == nil {
+		delivery, err = s.MachineService.ReplayWebhookDelivery(c.Request.Context(), deliveryID)
+	}
+	if err != nil {
+		requestLogger(c).Error("Error replaying webhook delivery: " + err.Error())
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(202, delivery)
+}
@@ -0,0 +1,399 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             v3.21.12
+// source: machine.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// VendingMachineClient is the client API for VendingMachine service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type VendingMachineClient interface {
+	ListProducts(ctx context.Context, in *ListProductsRequest, opts ...grpc.CallOption) (*ListProductsResponse, error)
+	GetProduct(ctx context.Context, in *GetProductRequest, opts ...grpc.CallOption) (*Product, error)
+	// GetBalance returns the current buyer's deposit.
+	GetBalance(ctx context.Context, in *GetBalanceRequest, opts ...grpc.CallOption) (*Balance, error)
+	// Deposit credits a coin to the current buyer.
+	Deposit(ctx context.Context, in *DepositRequest, opts ...grpc.CallOption) (*Balance, error)
+	// ResetDeposit sets the current buyer's deposit to zero.
+	ResetDeposit(ctx context.Context, in *ResetDepositRequest, opts ...grpc.CallOption) (*Balance, error)
+	// ReportCoin records a coin event from the coin acceptor. Accepted coins
+	// are credited to the current buyer, rejected coins are only logged.
+	ReportCoin(ctx context.Context, in *CoinEvent, opts ...grpc.CallOption) (*CoinEventResult, error)
+	Purchase(ctx context.Context, in *PurchaseRequest, opts ...grpc.CallOption) (*PurchaseResponse, error)
+	// WatchStock sends the current stock of the requested products and then
+	// an update whenever it changes.
+	WatchStock(ctx context.Context, in *WatchStockRequest, opts ...grpc.CallOption) (VendingMachine_WatchStockClient, error)
+}
+
+type vendingMachineClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewVendingMachineClient(cc grpc.ClientConnInterface) VendingMachineClient {
+	return &vendingMachineClient{cc}
+}
+
+func (c *vendingMachineClient) ListProducts(ctx context.Context, in *ListProductsRequest, opts ...grpc.CallOption) (*ListProductsResponse, error) {
+	out := new(ListProductsResponse)
+	err := c.cc.Invoke(ctx, "/verkaufsautomat.v1.VendingMachine/ListProducts", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vendingMachineClient) GetProduct(ctx context.Context, in *GetProductRequest, opts ...grpc.CallOption) (*Product, error) {
+	out := new(Product)
+	err := c.cc.Invoke(ctx, "/verkaufsautomat.v1.VendingMachine/GetProduct", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vendingMachineClient) GetBalance(ctx context.Context, in *GetBalanceRequest, opts ...grpc.CallOption) (*Balance, error) {
+	out := new(Balance)
+	err := c.cc.Invoke(ctx, "/verkaufsautomat.v1.VendingMachine/GetBalance", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vendingMachineClient) Deposit(ctx context.Context, in *DepositRequest, opts ...grpc.CallOption) (*Balance, error) {
+	out := new(Balance)
+	err := c.cc.Invoke(ctx, "/verkaufsautomat.v1.VendingMachine/Deposit", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vendingMachineClient) ResetDeposit(ctx context.Context, in *ResetDepositRequest, opts ...grpc.CallOption) (*Balance, error) {
+	out := new(Balance)
+	err := c.cc.Invoke(ctx, "/verkaufsautomat.v1.VendingMachine/ResetDeposit", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vendingMachineClient) ReportCoin(ctx context.Context, in *CoinEvent, opts ...grpc.CallOption) (*CoinEventResult, error) {
+	out := new(CoinEventResult)
+	err := c.cc.Invoke(ctx, "/verkaufsautomat.v1.VendingMachine/ReportCoin", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vendingMachineClient) Purchase(ctx context.Context, in *PurchaseRequest, opts ...grpc.CallOption) (*PurchaseResponse, error) {
+	out := new(PurchaseResponse)
+	err := c.cc.Invoke(ctx, "/verkaufsautomat.v1.VendingMachine/Purchase", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vendingMachineClient) WatchStock(ctx context.Context, in *WatchStockRequest, opts ...grpc.CallOption) (VendingMachine_WatchStockClient, error) {
+	stream, err := c.cc.NewStream(ctx, &VendingMachine_ServiceDesc.Streams[0], "/verkaufsautomat.v1.VendingMachine/WatchStock", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &vendingMachineWatchStockClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type VendingMachine_WatchStockClient interface {
+	Recv() (*StockUpdate, error)
+	grpc.ClientStream
+}
+
+type vendingMachineWatchStockClient struct {
+	grpc.ClientStream
+}
+
+func (x *vendingMachineWatchStockClient) Recv() (*StockUpdate, error) {
+	m := new(StockUpdate)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// VendingMachineServer is the server API for VendingMachine service.
+// All implementations must embed UnimplementedVendingMachineServer
+// for forward compatibility
+type VendingMachineServer interface {
+	ListProducts(context.Context, *ListProductsRequest) (*ListProductsResponse, error)
+	GetProduct(context.Context, *GetProductRequest) (*Product, error)
+	// GetBalance returns the current buyer's deposit.
+	GetBalance(context.Context, *GetBalanceRequest) (*Balance, error)
+	// Deposit credits a coin to the current buyer.
+	Deposit(context.Context, *DepositRequest) (*Balance, error)
+	// ResetDeposit sets the current buyer's deposit to zero.
+	ResetDeposit(context.Context, *ResetDepositRequest) (*Balance, error)
+	// ReportCoin records a coin event from the coin acceptor. Accepted coins
+	// are credited to the current buyer, rejected coins are only logged.
+	ReportCoin(context.Context, *CoinEvent) (*CoinEventResult, error)
+	Purchase(context.Context, *PurchaseRequest) (*PurchaseResponse, error)
+	// WatchStock sends the current stock of the requested products and then
+	// an update whenever it changes.
+	WatchStock(*WatchStockRequest, VendingMachine_WatchStockServer) error
+	mustEmbedUnimplementedVendingMachineServer()
+}
+
+// UnimplementedVendingMachineServer must be embedded to have forward compatible implementations.
+type UnimplementedVendingMachineServer struct {
+}
+
+func (UnimplementedVendingMachineServer) ListProducts(context.Context, *ListProductsRequest) (*ListProductsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListProducts not implemented")
+}
+func (UnimplementedVendingMachineServer) GetProduct(context.Context, *GetProductRequest) (*Product, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetProduct not implemented")
+}
+func (UnimplementedVendingMachineServer) GetBalance(context.Context, *GetBalanceRequest) (*Balance, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBalance not implemented")
+}
+func (UnimplementedVendingMachineServer) Deposit(context.Context, *DepositRequest) (*Balance, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Deposit not implemented")
+}
+func (UnimplementedVendingMachineServer) ResetDeposit(context.Context, *ResetDepositRequest) (*Balance, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResetDeposit not implemented")
+}
+func (UnimplementedVendingMachineServer) ReportCoin(context.Context, *CoinEvent) (*CoinEventResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReportCoin not implemented")
+}
+func (UnimplementedVendingMachineServer) Purchase(context.Context, *PurchaseRequest) (*PurchaseResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Purchase not implemented")
+}
+func (UnimplementedVendingMachineServer) WatchStock(*WatchStockRequest, VendingMachine_WatchStockServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchStock not implemented")
+}
+func (UnimplementedVendingMachineServer) mustEmbedUnimplementedVendingMachineServer() {}
+
+// UnsafeVendingMachineServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to VendingMachineServer will
+// result in compilation errors.
+type UnsafeVendingMachineServer interface {
+	mustEmbedUnimplementedVendingMachineServer()
+}
+
+func RegisterVendingMachineServer(s grpc.ServiceRegistrar, srv VendingMachineServer) {
+	s.RegisterService(&VendingMachine_ServiceDesc, srv)
+}
+
+func _VendingMachine_ListProducts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListProductsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VendingMachineServer).ListProducts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/verkaufsautomat.v1.VendingMachine/ListProducts",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VendingMachineServer).ListProducts(ctx, req.(*ListProductsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VendingMachine_GetProduct_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetProductRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VendingMachineServer).GetProduct(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/verkaufsautomat.v1.VendingMachine/GetProduct",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VendingMachineServer).GetProduct(ctx, req.(*GetProductRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VendingMachine_GetBalance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBalanceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VendingMachineServer).GetBalance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/verkaufsautomat.v1.VendingMachine/GetBalance",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VendingMachineServer).GetBalance(ctx, req.(*GetBalanceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VendingMachine_Deposit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DepositRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VendingMachineServer).Deposit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/verkaufsautomat.v1.VendingMachine/Deposit",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VendingMachineServer).Deposit(ctx, req.(*DepositRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VendingMachine_ResetDeposit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResetDepositRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VendingMachineServer).ResetDeposit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/verkaufsautomat.v1.VendingMachine/ResetDeposit",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VendingMachineServer).ResetDeposit(ctx, req.(*ResetDepositRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VendingMachine_ReportCoin_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CoinEvent)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VendingMachineServer).ReportCoin(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/verkaufsautomat.v1.VendingMachine/ReportCoin",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VendingMachineServer).ReportCoin(ctx, req.(*CoinEvent))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VendingMachine_Purchase_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PurchaseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VendingMachineServer).Purchase(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/verkaufsautomat.v1.VendingMachine/Purchase",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VendingMachineServer).Purchase(ctx, req.(*PurchaseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VendingMachine_WatchStock_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchStockRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(VendingMachineServer).WatchStock(m, &vendingMachineWatchStockServer{stream})
+}
+
+type VendingMachine_WatchStockServer interface {
+	Send(*StockUpdate) error
+	grpc.ServerStream
+}
+
+type vendingMachineWatchStockServer struct {
+	grpc.ServerStream
+}
+
+func (x *vendingMachineWatchStockServer) Send(m *StockUpdate) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// VendingMachine_ServiceDesc is the grpc.ServiceDesc for VendingMachine service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var VendingMachine_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "verkaufsautomat.v1.VendingMachine",
+	HandlerType: (*VendingMachineServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListProducts",
+			Handler:    _VendingMachine_ListProducts_Handler,
+		},
+		{
+			MethodName: "GetProduct",
+			Handler:    _VendingMachine_GetProduct_Handler,
+		},
+		{
+			MethodName: "GetBalance",
+			Handler:    _VendingMachine_GetBalance_Handler,
+		},
+		{
+			MethodName: "Deposit",
+			Handler:    _VendingMachine_Deposit_Handler,
+		},
+		{
+			MethodName: "ResetDeposit",
+			Handler:    _VendingMachine_ResetDeposit_Handler,
+		},
+		{
+			MethodName: "ReportCoin",
+			Handler:    _VendingMachine_ReportCoin_Handler,
+		},
+		{
+			MethodName: "Purchase",
+			Handler:    _VendingMachine_Purchase_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchStock",
+			Handler:       _VendingMachine_WatchStock_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "machine.proto",
+}
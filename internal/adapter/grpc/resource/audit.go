@@ -0,0 +1,98 @@
+package resource
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"net"
+	"strconv"
+	"verkaufsautomat/internal/adapter/grpc/pb"
+	models "verkaufsautomat/internal/core/domain/resource"
+	"verkaufsautomat/internal/core/logger"
+)
+
+// auditedMethods maps the RPCs that move money or stock onto audit actions.
+var auditedMethods = map[string]string{
+	"/verkaufsautomat.v1.VendingMachine/Deposit":      models.AuditDeposit,
+	"/verkaufsautomat.v1.VendingMachine/ReportCoin":   models.AuditDeposit,
+	"/verkaufsautomat.v1.VendingMachine/ResetDeposit": models.AuditDepositReset,
+	"/verkaufsautomat.v1.VendingMachine/Purchase":     models.AuditPurchase,
+}
+
+// UnaryAuditInterceptor records calls of audited methods in the audit log,
+// whether they succeeded or not. It must run after UnaryAuthInterceptor.
+func (s *GRPCServer) UnaryAuditInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	action, ok := auditedMethods[info.FullMethod]
+	if !ok {
+		return handler(ctx, req)
+	}
+
+	resp, err := handler(ctx, req)
+
+	claims := currentUser(ctx)
+	entry := models.AuditEntry{
+		ActorID:       uint(claims.UserID),
+		ActorUsername: claims.Username,
+		ActorRoleID:   uint(claims.RoleID),
+		Action:        action,
+		TargetType:    "user",
+		TargetID:      strconv.Itoa(claims.UserID),
+		Succeeded:     err == nil,
+		Status:        status.Code(err).String(),
+		IP:            peerIP(ctx),
+		RequestID:     requestID(ctx),
+	}
+	if purchase, ok := resp.(*pb.PurchaseResponse); ok && err == nil {
+		entry.TargetType = "order"
+		entry.TargetID = strconv.Itoa(int(purchase.OrderId))
+	}
+	if err == nil {
+		if after, err := json.Marshal(resp); err == nil {
+			entry.After = string(after)
+		}
+	}
+
+	if err := s.MachineService.RecordAudit(ctx, &entry); err != nil {
+		logger.Ctx(ctx).Error("Error recording audit entry: " + err.Error())
+	}
+	return resp, err
+}
+
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+type requestIDKey struct{}
+
+// withRequestID tags the call with the "x-request-id" metadata entry, or a
+// new ID when the caller did not send one, and adds it to the log fields.
+func withRequestID(ctx context.Context) context.Context {
+	var id string
+	if md, ok := metadata.FromIncomingContext(ctx); ok && len(md.Get("x-request-id")) > 0 {
+		id = md.Get("x-request-id")[0]
+	} else {
+		bytes := make([]byte, 16)
+		rand.Read(bytes)
+		id = hex.EncodeToString(bytes)
+	}
+	ctx = context.WithValue(ctx, requestIDKey{}, id)
+	return logger.NewContext(ctx, logger.Fields{"request_id": id})
+}
+
+func requestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
@@ -0,0 +1,119 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"strings"
+	models "verkaufsautomat/internal/core/domain/resource"
+	"verkaufsautomat/internal/core/logger"
+	"verkaufsautomat/internal/core/token"
+)
+
+type claimsKey struct{}
+
+// methodScopes maps the RPCs onto the scope an API key needs to call them.
+var methodScopes = map[string]string{
+	"/verkaufsautomat.v1.VendingMachine/ListProducts": models.ScopeProductsRead,
+	"/verkaufsautomat.v1.VendingMachine/GetProduct":   models.ScopeProductsRead,
+	"/verkaufsautomat.v1.VendingMachine/WatchStock":   models.ScopeProductsRead,
+	"/verkaufsautomat.v1.VendingMachine/GetBalance":   models.ScopeDeposits,
+	"/verkaufsautomat.v1.VendingMachine/Deposit":      models.ScopeDeposits,
+	"/verkaufsautomat.v1.VendingMachine/ResetDeposit": models.ScopeDeposits,
+	"/verkaufsautomat.v1.VendingMachine/ReportCoin":   models.ScopeDeposits,
+	"/verkaufsautomat.v1.VendingMachine/Purchase":     models.ScopeOrdersWrite,
+}
+
+// authenticate reads the bearer token or API key from the "authorization"
+// metadata entry and stores its claims and the request ID in the returned
+// context. API keys need the scope of the method.
+func (s *GRPCServer) authenticate(ctx context.Context, method string) (context.Context, error) {
+	ctx = withRequestID(ctx)
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok || len(md.Get("authorization")) == 0 {
+		return nil, status.Error(codes.Unauthenticated, "missing authorization metadata")
+	}
+
+	tokenString := strings.TrimPrefix(md.Get("authorization")[0], "Bearer ")
+	if models.IsAPIKey(tokenString) {
+		return s.authenticateAPIKey(ctx, tokenString, method)
+	}
+	user, err := s.MachineService.AuthenticateToken(ctx, tokenString)
+	if errors.Is(err, models.ErrInvalidToken) {
+		logger.Ctx(ctx).Warn("Error verifying token: " + err.Error())
+		return nil, status.Error(codes.Unauthenticated, "invalid token")
+	}
+	if err != nil {
+		logger.Ctx(ctx).Error("Error verifying token: " + err.Error())
+		return nil, toStatus(err)
+	}
+
+	claims := token.Claims{UserID: int(user.UserID), RoleID: int(user.RoleID), Username: user.Username, TokenVersion: user.TokenVersion}
+	ctx = logger.NewContext(ctx, logger.Fields{"user_id": claims.UserID})
+	return context.WithValue(ctx, claimsKey{}, claims), nil
+}
+
+func (s *GRPCServer) authenticateAPIKey(ctx context.Context, credential, method string) (context.Context, error) {
+	key, user, err := s.MachineService.AuthenticateAPIKey(ctx, credential)
+	if errors.Is(err, models.ErrInvalidAPIKey) {
+		logger.Ctx(ctx).Warn("Error verifying api key: " + err.Error())
+		return nil, status.Error(codes.Unauthenticated, "invalid api key")
+	}
+	if err != nil {
+		logger.Ctx(ctx).Error("Error verifying api key: " + err.Error())
+		return nil, toStatus(err)
+	}
+	if scope, ok := methodScopes[method]; !ok || !key.HasScope(scope) {
+		logger.Ctx(ctx).With(logger.Fields{"api_key_id": key.APIKeyID}).Warn("Api key lacks the scope for " + method)
+		return nil, status.Error(codes.PermissionDenied, "api key lacks the scope for this method")
+	}
+
+	claims := token.Claims{UserID: int(user.UserID), RoleID: int(user.RoleID), Username: user.Username}
+	ctx = logger.NewContext(ctx, logger.Fields{"user_id": claims.UserID, "api_key_id": int(key.APIKeyID)})
+	return context.WithValue(ctx, claimsKey{}, claims), nil
+}
+
+func currentUser(ctx context.Context) token.Claims {
+	claims, _ := ctx.Value(claimsKey{}).(token.Claims)
+	return claims
+}
+
+func requireRole(ctx context.Context, roleID int, action string) error {
+	if currentUser(ctx).RoleID != roleID {
+		logger.Ctx(ctx).Error("User cannot " + action)
+		return status.Error(codes.PermissionDenied, "user cannot "+action)
+	}
+	return nil
+}
+
+// UnaryAuthInterceptor rejects unary calls without a valid token or API
+// key.
+func (s *GRPCServer) UnaryAuthInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := s.authenticate(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// StreamAuthInterceptor rejects streaming calls without a valid token or
+// API key.
+func (s *GRPCServer) StreamAuthInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := s.authenticate(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+}
+
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,244 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"verkaufsautomat/internal/adapter/grpc/pb"
+	models "verkaufsautomat/internal/core/domain/resource"
+	"verkaufsautomat/internal/core/events"
+	"verkaufsautomat/internal/core/logger"
+	"verkaufsautomat/internal/core/token"
+	ports "verkaufsautomat/internal/ports/resource"
+)
+
+type GRPCServer struct {
+	pb.UnimplementedVendingMachineServer
+	MachineService ports.MachineService
+	Events         ports.EventSubscriber
+	Tokens         *token.Issuer
+}
+
+func NewGRPCServer(MachineService ports.MachineService, Events ports.EventSubscriber, Tokens *token.Issuer) *GRPCServer {
+	return &GRPCServer{
+		MachineService: MachineService,
+		Events:         Events,
+		Tokens:         Tokens,
+	}
+}
+
+// NewServer returns a grpc.Server with authentication, auditing and the
+// vending machine service registered.
+func (s *GRPCServer) NewServer() *grpc.Server {
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(s.UnaryAuthInterceptor, s.UnaryAuditInterceptor),
+		grpc.StreamInterceptor(s.StreamAuthInterceptor),
+	)
+	pb.RegisterVendingMachineServer(server, s)
+	return server
+}
+
+// toStatus maps domain errors onto gRPC status codes.
+func toStatus(err error) error {
+	switch {
+	case errors.Is(err, models.ErrProductNotFound),
+		errors.Is(err, models.ErrUserNotFound),
+		errors.Is(err, models.ErrOrderNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, models.ErrInsufficientFunds),
+		errors.Is(err, models.ErrInsufficientStock):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, models.ErrInvalidQuantity):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+		return status.FromContextError(err).Err()
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+func toProduct(product models.Product) *pb.Product {
+	return &pb.Product{
+		ProductId:       uint32(product.ProductID),
+		ProductName:     product.ProductName,
+		Cost:            int32(product.Cost),
+		AmountAvailable: int32(product.AmountAvailable),
+		SellerId:        uint32(product.SellerID),
+	}
+}
+
+func toCoins(amounts []int) []int32 {
+	coins := make([]int32, len(amounts))
+	for i, amount := range amounts {
+		coins[i] = int32(amount)
+	}
+	return coins
+}
+
+func (s *GRPCServer) ListProducts(ctx context.Context, req *pb.ListProductsRequest) (*pb.ListProductsResponse, error) {
+	products, err := s.MachineService.GetProducts(ctx)
+	if err != nil {
+		logger.Ctx(ctx).Error("Error getting products: " + err.Error())
+		return nil, toStatus(err)
+	}
+
+	response := &pb.ListProductsResponse{}
+	for _, product := range products {
+		response.Products = append(response.Products, toProduct(product))
+	}
+	return response, nil
+}
+
+func (s *GRPCServer) GetProduct(ctx context.Context, req *pb.GetProductRequest) (*pb.Product, error) {
+	product, err := s.MachineService.GetProductById(ctx, int(req.ProductId))
+	if err != nil {
+		logger.Ctx(ctx).Error("Error getting product: " + err.Error())
+		return nil, toStatus(err)
+	}
+	return toProduct(product), nil
+}
+
+func (s *GRPCServer) balance(ctx context.Context) (*pb.Balance, error) {
+	user, err := s.MachineService.GetUserById(ctx, currentUser(ctx).UserID)
+	if err != nil {
+		logger.Ctx(ctx).Error("Error getting user: " + err.Error())
+		return nil, toStatus(err)
+	}
+	return &pb.Balance{Deposit: int32(user.Deposit)}, nil
+}
+
+func (s *GRPCServer) GetBalance(ctx context.Context, req *pb.GetBalanceRequest) (*pb.Balance, error) {
+	if err := requireRole(ctx, models.BuyerRoleID, "view deposit"); err != nil {
+		return nil, err
+	}
+	return s.balance(ctx)
+}
+
+func (s *GRPCServer) Deposit(ctx context.Context, req *pb.DepositRequest) (*pb.Balance, error) {
+	if err := requireRole(ctx, models.BuyerRoleID, "deposit money"); err != nil {
+		return nil, err
+	}
+	if !models.IsValidCoin(int(req.Amount)) {
+		logger.Ctx(ctx).Error("Invalid coin")
+		return nil, status.Error(codes.InvalidArgument, "invalid coin")
+	}
+
+	if err := s.MachineService.DepositMoney(ctx, currentUser(ctx).UserID, int(req.Amount)); err != nil {
+		logger.Ctx(ctx).Error("Error depositing money: " + err.Error())
+		return nil, toStatus(err)
+	}
+	return s.balance(ctx)
+}
+
+func (s *GRPCServer) ResetDeposit(ctx context.Context, req *pb.ResetDepositRequest) (*pb.Balance, error) {
+	if err := requireRole(ctx, models.BuyerRoleID, "reset deposit"); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.MachineService.ResetDeposit(ctx, currentUser(ctx).UserID); err != nil {
+		logger.Ctx(ctx).Error("Error resetting deposit: " + err.Error())
+		return nil, toStatus(err)
+	}
+	return &pb.Balance{}, nil
+}
+
+func (s *GRPCServer) ReportCoin(ctx context.Context, req *pb.CoinEvent) (*pb.CoinEventResult, error) {
+	if err := requireRole(ctx, models.BuyerRoleID, "deposit money"); err != nil {
+		return nil, err
+	}
+
+	if req.Kind != pb.CoinEvent_KIND_ACCEPTED || !models.IsValidCoin(int(req.Value)) {
+		logger.Ctx(ctx).Info("Coin rejected by machine " + req.MachineId)
+		balance, err := s.balance(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return &pb.CoinEventResult{Credited: false, Balance: balance}, nil
+	}
+
+	balance, err := s.Deposit(ctx, &pb.DepositRequest{Amount: req.Value})
+	if err != nil {
+		return nil, err
+	}
+	return &pb.CoinEventResult{Credited: true, Balance: balance}, nil
+}
+
+func (s *GRPCServer) Purchase(ctx context.Context, req *pb.PurchaseRequest) (*pb.PurchaseResponse, error) {
+	if err := requireRole(ctx, models.BuyerRoleID, "buy product"); err != nil {
+		return nil, err
+	}
+
+	order, remaining, err := s.MachineService.BuyProduct(ctx, currentUser(ctx).UserID, int(req.ProductId), int(req.Quantity))
+	if err != nil {
+		logger.Ctx(ctx).Error("Error buying product: " + err.Error())
+		return nil, toStatus(err)
+	}
+
+	return &pb.PurchaseResponse{
+		OrderId:    uint32(order.OrderID),
+		ProductId:  uint32(order.ProductID),
+		Quantity:   int32(order.Quantity),
+		TotalPrice: int32(order.TotalPrice),
+		Change:     toCoins(models.Change(remaining)),
+		Balance:    &pb.Balance{Deposit: int32(remaining)},
+	}, nil
+}
+
+func (s *GRPCServer) WatchStock(req *pb.WatchStockRequest, stream pb.VendingMachine_WatchStockServer) error {
+	watched := map[uint32]bool{}
+	for _, id := range req.ProductIds {
+		watched[id] = true
+	}
+	isWatched := func(id uint32) bool {
+		return len(watched) == 0 || watched[id]
+	}
+
+	// Subscribe before taking the snapshot so that no change is missed.
+	subscription, cancel := s.Events.Stream(64, events.ProductCreatedName, events.StockChangedName, events.ProductDeletedName)
+	defer cancel()
+
+	products, err := s.MachineService.GetProducts(stream.Context())
+	if err != nil {
+		logger.Ctx(stream.Context()).Error("Error getting products: " + err.Error())
+		return toStatus(err)
+	}
+	for _, product := range products {
+		if !isWatched(uint32(product.ProductID)) {
+			continue
+		}
+		if err := stream.Send(&pb.StockUpdate{Product: toProduct(product)}); err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case event, ok := <-subscription:
+			if !ok {
+				return nil
+			}
+			update := stockUpdate(event)
+			if update == nil || !isWatched(update.Product.ProductId) {
+				continue
+			}
+			if err := stream.Send(update); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func stockUpdate(event events.Event) *pb.StockUpdate {
+	switch event := event.(type) {
+	case events.ProductCreated:
+		return &pb.StockUpdate{Product: toProduct(event.Product)}
+	case events.StockChanged:
+		return &pb.StockUpdate{Product: toProduct(event.Product)}
+	case events.ProductDeleted:
+		return &pb.StockUpdate{Product: toProduct(event.Product), Removed: true}
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+// Package httpserver runs the HTTP API with connection limits, optional TLS
+// and graceful shutdown.
+package httpserver
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"verkaufsautomat/internal/config"
+)
+
+type Server struct {
+	server       *http.Server
+	certificates *CertificateReloader
+	config       config.HTTP
+}
+
+// New returns a server for handler. With TLS configured the certificate is
+// loaded here, so that a bad certificate stops startup.
+func New(cfg config.HTTP, handler http.Handler) (*Server, error) {
+	s := &Server{
+		server: &http.Server{
+			Addr:              ":" + cfg.Port,
+			Handler:           handler,
+			ReadTimeout:       cfg.ReadTimeout,
+			ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+			WriteTimeout:      cfg.WriteTimeout,
+			IdleTimeout:       cfg.IdleTimeout,
+			MaxHeaderBytes:    cfg.MaxHeaderBytes,
+		},
+		config: cfg,
+	}
+	if cfg.TLSCertFile != "" {
+		certificates, err := NewCertificateReloader(cfg.TLSCertFile, cfg.TLSKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		s.certificates = certificates
+		s.server.TLSConfig = &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: certificates.GetCertificate,
+		}
+	}
+	return s, nil
+}
+
+// RegisterOnShutdown registers a function to call when Shutdown starts, for
+// example to end long-lived responses that would otherwise hold it up.
+func (s *Server) RegisterOnShutdown(f func()) {
+	s.server.RegisterOnShutdown(f)
+}
+
+// ListenAndServe listens on the configured port.
+func (s *Server) ListenAndServe(ctx context.Context) error {
+	listener, err := net.Listen("tcp", s.server.Addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(ctx, listener)
+}
+
+// Serve accepts connections until Shutdown is called, when it returns nil.
+// While serving TLS the certificate files are watched until ctx is done.
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	var err error
+	if s.certificates != nil {
+		go s.certificates.Watch(ctx, s.config.TLSReloadInterval)
+		err = s.server.ServeTLS(listener, "", "")
+	} else {
+		err = s.server.Serve(listener)
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// Shutdown stops accepting connections and waits for in-flight requests to
+// finish, up to the configured shutdown timeout. Connections still busy
+// after that are closed.
+func (s *Server) Shutdown(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, s.config.ShutdownTimeout)
+	defer cancel()
+
+	err := s.server.Shutdown(ctx)
+	if errors.Is(err, context.DeadlineExceeded) {
+		s.server.Close()
+	}
+	return err
+}
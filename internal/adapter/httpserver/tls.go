@@ -0,0 +1,101 @@
+package httpserver
+
+import (
+	"context"
+	"crypto/tls"
+	"os"
+	"sync"
+	"time"
+	"verkaufsautomat/internal/core/logger"
+)
+
+// CertificateReloader serves a certificate from files that may be replaced
+// while the server runs, as they are when a certificate is renewed.
+type CertificateReloader struct {
+	certFile string
+	keyFile  string
+
+	mu          sync.RWMutex
+	certificate *tls.Certificate
+	modified    time.Time
+}
+
+func NewCertificateReloader(certFile, keyFile string) (*CertificateReloader, error) {
+	r := &CertificateReloader{certFile: certFile, keyFile: keyFile}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload reads the certificate and key. The previous certificate stays in
+// use when they cannot be loaded.
+func (r *CertificateReloader) Reload() error {
+	modified, err := r.lastModified()
+	if err != nil {
+		return err
+	}
+	certificate, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.certificate = &certificate
+	r.modified = modified
+	r.mu.Unlock()
+	return nil
+}
+
+// GetCertificate is used as tls.Config.GetCertificate.
+func (r *CertificateReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.certificate, nil
+}
+
+// Watch reloads the certificate whenever one of the files changed, checking
+// every interval until ctx is done.
+func (r *CertificateReloader) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			modified, err := r.lastModified()
+			if err != nil {
+				logger.Error("Error checking TLS certificate: " + err.Error())
+				continue
+			}
+			r.mu.RLock()
+			changed := modified.After(r.modified)
+			r.mu.RUnlock()
+			if !changed {
+				continue
+			}
+			if err := r.Reload(); err != nil {
+				logger.Error("Error reloading TLS certificate: " + err.Error())
+				continue
+			}
+			logger.Info("Reloaded TLS certificate")
+		}
+	}
+}
+
+// lastModified returns the later modification time of the two files.
+func (r *CertificateReloader) lastModified() (time.Time, error) {
+	var latest time.Time
+	for _, file := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(file)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
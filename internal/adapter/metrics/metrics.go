@@ -0,0 +1,199 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+	"strconv"
+	"time"
+	"verkaufsautomat/internal/core/domain/resource"
+	"verkaufsautomat/internal/core/events"
+	"verkaufsautomat/internal/core/logger"
+)
+
+const namespace = "verkaufsautomat"
+
+// Metrics holds the Prometheus registry and the HTTP and business metrics
+// exported on /metrics.
+type Metrics struct {
+	Registry *prometheus.Registry
+
+	requests        *prometheus.CounterVec
+	duration        *prometheus.HistogramVec
+	purchases       *prometheus.CounterVec
+	itemsSold       *prometheus.CounterVec
+	revenue         *prometheus.CounterVec
+	failedPurchases *prometheus.CounterVec
+	deposits        *prometheus.CounterVec
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		Registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests by method, route and status code.",
+		}, []string{"method", "route", "status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by method and route.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+		purchases: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "purchases_total",
+			Help:      "Completed purchases by product and seller.",
+		}, []string{"product_id", "seller_id"}),
+		itemsSold: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "items_sold_total",
+			Help:      "Items sold by product and seller.",
+		}, []string{"product_id", "seller_id"}),
+		revenue: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "revenue_cents_total",
+			Help:      "Revenue in cents by product and seller.",
+		}, []string{"product_id", "seller_id"}),
+		failedPurchases: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "purchases_failed_total",
+			Help:      "Rejected purchases by reason.",
+		}, []string{"reason"}),
+		deposits: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "deposits_total",
+			Help:      "Deposited coins by denomination in cents.",
+		}, []string{"coin"}),
+	}
+	m.Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests, m.duration, m.purchases, m.itemsSold, m.revenue, m.failedPurchases, m.deposits,
+	)
+	return m
+}
+
+// Handler serves the registry in the Prometheus text format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.Registry, promhttp.HandlerOpts{})
+}
+
+// Middleware counts requests and observes their latency. Routes are labelled
+// with their pattern, such as /api/v2/products/:id, to keep the number of
+// series bounded.
+func (m *Metrics) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		m.requests.WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).Inc()
+		m.duration.WithLabelValues(c.Request.Method, route).Observe(time.Since(start).Seconds())
+	}
+}
+
+// Subscribe updates the business metrics from the domain events on the bus.
+func (m *Metrics) Subscribe(bus *events.Bus) func() {
+	return bus.Subscribe(m.Observe, events.PurchaseCompletedName, events.PurchaseFailedName, events.DepositMadeName)
+}
+
+func (m *Metrics) Observe(event events.Event) {
+	switch event := event.(type) {
+	case events.PurchaseCompleted:
+		productID := strconv.Itoa(int(event.Product.ProductID))
+		sellerID := strconv.Itoa(int(event.Product.SellerID))
+		m.purchases.WithLabelValues(productID, sellerID).Inc()
+		m.itemsSold.WithLabelValues(productID, sellerID).Add(float64(event.Order.Quantity))
+		m.revenue.WithLabelValues(productID, sellerID).Add(float64(event.Order.TotalPrice))
+	case events.PurchaseFailed:
+		m.failedPurchases.WithLabelValues(failureReason(event.Err)).Inc()
+	case events.DepositMade:
+		m.deposits.WithLabelValues(strconv.Itoa(event.Amount)).Inc()
+	}
+}
+
+func failureReason(err error) string {
+	switch {
+	case errors.Is(err, resource.ErrInsufficientFunds):
+		return "insufficient_funds"
+	case errors.Is(err, resource.ErrInsufficientStock):
+		return "insufficient_stock"
+	case errors.Is(err, resource.ErrProductNotFound):
+		return "product_not_found"
+	case errors.Is(err, resource.ErrUserNotFound):
+		return "user_not_found"
+	case errors.Is(err, resource.ErrInvalidQuantity):
+		return "invalid_quantity"
+	default:
+		return "error"
+	}
+}
+
+// Inventory is the part of the service the stock and coin float gauges are
+// read from.
+type Inventory interface {
+	GetProducts(ctx context.Context) ([]resource.Product, error)
+	GetTotalDeposit(ctx context.Context) (int, error)
+}
+
+// RegisterInventory exports stock levels and the coin float, read from the
+// inventory on every scrape so that they are correct after a restart.
+func (m *Metrics) RegisterInventory(inventory Inventory) error {
+	return m.Registry.Register(inventoryCollector{inventory: inventory})
+}
+
+// RegisterDB exports the connection pool statistics of db.
+func (m *Metrics) RegisterDB(db *sql.DB) error {
+	return m.Registry.Register(collectors.NewDBStatsCollector(db, namespace))
+}
+
+var (
+	stockDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "stock_available"),
+		"Items available by product and seller.",
+		[]string{"product_id", "product_name", "seller_id"}, nil,
+	)
+	coinFloatDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "coin_float_cents"),
+		"Money deposited by buyers and not spent, which the machine must be able to pay out.",
+		nil, nil,
+	)
+)
+
+type inventoryCollector struct {
+	inventory Inventory
+}
+
+func (i inventoryCollector) Describe(descs chan<- *prometheus.Desc) {
+	descs <- stockDesc
+	descs <- coinFloatDesc
+}
+
+func (i inventoryCollector) Collect(metrics chan<- prometheus.Metric) {
+	ctx := context.Background()
+	products, err := i.inventory.GetProducts(ctx)
+	if err != nil {
+		logger.Error("Error collecting stock levels: " + err.Error())
+	}
+	for _, product := range products {
+		metrics <- prometheus.MustNewConstMetric(stockDesc, prometheus.GaugeValue, float64(product.AmountAvailable),
+			strconv.Itoa(int(product.ProductID)), product.ProductName, strconv.Itoa(int(product.SellerID)))
+	}
+
+	total, err := i.inventory.GetTotalDeposit(ctx)
+	if err != nil {
+		logger.Error("Error collecting coin float: " + err.Error())
+		return
+	}
+	metrics <- prometheus.MustNewConstMetric(coinFloatDesc, prometheus.GaugeValue, float64(total))
+}
@@ -0,0 +1,79 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"verkaufsautomat/internal/core/domain/resource"
+	"verkaufsautomat/internal/core/events"
+)
+
+type inventory struct {
+	products []resource.Product
+	deposit  int
+}
+
+func (i inventory) GetProducts(ctx context.Context) ([]resource.Product, error) {
+	return i.products, nil
+}
+
+func (i inventory) GetTotalDeposit(ctx context.Context) (int, error) {
+	return i.deposit, nil
+}
+
+func TestMetrics(t *testing.T) {
+	telemetry := New()
+	bus := events.NewBus()
+	defer telemetry.Subscribe(bus)()
+
+	if err := telemetry.RegisterInventory(inventory{
+		products: []resource.Product{{ProductID: 12, ProductName: "cocacola", AmountAvailable: 4, SellerID: 1}},
+		deposit:  135,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	router := gin.New()
+	router.Use(telemetry.Middleware())
+	router.GET("/products/:id", func(c *gin.Context) { c.Status(http.StatusNotFound) })
+	router.GET("/metrics", gin.WrapH(telemetry.Handler()))
+
+	for _, path := range []string{"/products/1", "/products/2"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+
+	bus.Publish(events.DepositMade{UserID: 2, Amount: 50, Deposit: 50})
+	bus.Publish(events.PurchaseCompleted{
+		Order:   resource.Order{OrderID: 1, ProductID: 12, Quantity: 2, TotalPrice: 130},
+		Product: resource.Product{ProductID: 12, SellerID: 1},
+	})
+	bus.Publish(events.PurchaseFailed{BuyerID: 2, ProductID: 12, Quantity: 9, Err: resource.ErrInsufficientStock})
+	bus.Publish(events.PurchaseFailed{BuyerID: 2, ProductID: 12, Quantity: 1, Err: errors.New("connection refused")})
+
+	response := httptest.NewRecorder()
+	router.ServeHTTP(response, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := io.ReadAll(response.Body)
+
+	expected := []string{
+		`verkaufsautomat_http_requests_total{method="GET",route="/products/:id",status="404"} 2`,
+		`verkaufsautomat_http_request_duration_seconds_count{method="GET",route="/products/:id"} 2`,
+		`verkaufsautomat_deposits_total{coin="50"} 1`,
+		`verkaufsautomat_purchases_total{product_id="12",seller_id="1"} 1`,
+		`verkaufsautomat_items_sold_total{product_id="12",seller_id="1"} 2`,
+		`verkaufsautomat_revenue_cents_total{product_id="12",seller_id="1"} 130`,
+		`verkaufsautomat_purchases_failed_total{reason="insufficient_stock"} 1`,
+		`verkaufsautomat_purchases_failed_total{reason="error"} 1`,
+		`verkaufsautomat_stock_available{product_id="12",product_name="cocacola",seller_id="1"} 4`,
+		`verkaufsautomat_coin_float_cents 135`,
+	}
+	for _, line := range expected {
+		if !strings.Contains(string(body), line) {
+			t.Errorf("Expected %s in the metrics", line)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+// Package notifier delivers messages to users. The log and file notifiers
+// are meant for local use and have to be chosen explicitly: the file
+// notifier leaves password reset tokens where whoever runs the server can
+// read them, instead of sending them to the user.
+package notifier
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+	"verkaufsautomat/internal/core/domain/resource"
+	"verkaufsautomat/internal/core/logger"
+)
+
+// Log records that a reset token was issued in the application log. The
+// token itself is never logged, only a fingerprint that tells tokens apart
+// but cannot be redeemed.
+type Log struct{}
+
+func (Log) NotifyPasswordReset(ctx context.Context, user resource.User, token string, expiresAt time.Time) error {
+	logger.Ctx(ctx).With(logger.Fields{
+		"user_id":           user.UserID,
+		"username":          user.Username,
+		"reset_fingerprint": Fingerprint(token),
+		"expires_at":        expiresAt.Format(time.RFC3339),
+	}).Info("Password reset requested")
+	return nil
+}
+
+// Fingerprint returns the first 12 hex digits of the SHA-256 of a token.
+func Fingerprint(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// File appends notifications to a file, one JSON object per line.
+type File struct {
+	Path string
+
+	mu sync.Mutex
+}
+
+// Notification is a line written by File.
+type Notification struct {
+	Kind      string    `json:"kind"`
+	UserID    uint      `json:"user_id"`
+	Username  string    `json:"username"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func NewFile(path string) *File {
+	return &File{Path: path}
+}
+
+func (f *File) NotifyPasswordReset(ctx context.Context, user resource.User, token string, expiresAt time.Time) error {
+	return f.write(Notification{
+		Kind:      "password_reset",
+		UserID:    user.UserID,
+		Username:  user.Username,
+		Token:     token,
+		ExpiresAt: expiresAt,
+		CreatedAt: time.Now(),
+	})
+}
+
+func (f *File) write(notification Notification) error {
+	line, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	file, err := os.OpenFile(f.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
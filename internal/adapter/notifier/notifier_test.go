@@ -0,0 +1,83 @@
+package notifier
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"verkaufsautomat/internal/core/domain/resource"
+	"verkaufsautomat/internal/core/logger"
+)
+
+func TestFile_NotifyPasswordReset(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notifications.jsonl")
+	notifier := NewFile(path)
+	expires := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	for _, token := range []string{"first", "second"} {
+		if err := notifier.NotifyPasswordReset(context.Background(), resource.User{UserID: 7, Username: "harry"}, token, expires); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	var notifications []Notification
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var notification Notification
+		if err := json.Unmarshal(scanner.Bytes(), &notification); err != nil {
+			t.Fatal(err)
+		}
+		notifications = append(notifications, notification)
+	}
+
+	if len(notifications) != 2 {
+		t.Fatalf("Expected 2 notifications, got %d", len(notifications))
+	}
+	if n := notifications[1]; n.Kind != "password_reset" || n.UserID != 7 || n.Username != "harry" || n.Token != "second" || !n.ExpiresAt.Equal(expires) {
+		t.Errorf("Unexpected notification %+v", n)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm()&0077 != 0 {
+		t.Errorf("Expected the file to be private, got %v", info.Mode().Perm())
+	}
+}
+
+func TestLog_NotifyPasswordReset(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "verkaufsautomat.log")
+	if err := logger.Configure(logger.Config{Level: "info", Format: "json", Output: path}); err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Configure(logger.DefaultConfig())
+
+	if err := (Log{}).NotifyPasswordReset(context.Background(), resource.User{UserID: 7, Username: "harry"}, "a-live-reset-token", time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "a-live-reset-token") {
+		t.Errorf("Expected the token to stay out of the log, got %s", data)
+	}
+	if !strings.Contains(string(data), Fingerprint("a-live-reset-token")) {
+		t.Errorf("Expected the fingerprint of the token, got %s", data)
+	}
+}
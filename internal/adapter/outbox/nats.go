@@ -0,0 +1,164 @@
+package outbox
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NATSSink publishes every event to a NATS server, or any local broker that
+// speaks the NATS client protocol, on the subject SubjectPrefix + event name.
+// When the server supports headers the event ID is sent as Nats-Msg-Id, which
+// lets JetStream discard events the relay delivers twice.
+//
+// Each publish is followed by a PING, and the event only counts as delivered
+// once the server answered with PONG.
+type NATSSink struct {
+	Address       string
+	SubjectPrefix string
+	Timeout       time.Duration
+
+	mu      sync.Mutex
+	conn    net.Conn
+	reader  *bufio.Reader
+	headers bool
+}
+
+// NewNATSSink accepts either host:port or a nats:// URL.
+func NewNATSSink(url string) *NATSSink {
+	return &NATSSink{
+		Address:       strings.TrimPrefix(url, "nats://"),
+		SubjectPrefix: "verkaufsautomat.",
+		Timeout:       5 * time.Second,
+	}
+}
+
+type natsEnvelope struct {
+	ID         string          `json:"id"`
+	Name       string          `json:"name"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Data       json.RawMessage `json:"data"`
+}
+
+type natsInfo struct {
+	Headers bool `json:"headers"`
+}
+
+func (s *NATSSink) Name() string {
+	return "nats"
+}
+
+func (s *NATSSink) Deliver(ctx context.Context, message Message) error {
+	body, err := json.Marshal(natsEnvelope{
+		ID:         message.ID,
+		Name:       message.Name,
+		OccurredAt: message.OccurredAt,
+		Data:       message.Payload,
+	})
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		if err := s.connect(); err != nil {
+			return err
+		}
+	}
+	if err := s.publish(ctx, s.SubjectPrefix+message.Name, message.ID, body); err != nil {
+		s.conn.Close()
+		s.conn = nil
+		return err
+	}
+	return nil
+}
+
+// Close closes the connection to the server, if any.
+func (s *NATSSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+func (s *NATSSink) connect() error {
+	conn, err := net.DialTimeout("tcp", s.Address, s.Timeout)
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(time.Now().Add(s.Timeout))
+
+	reader := bufio.NewReader(conn)
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	if !strings.HasPrefix(line, "INFO ") {
+		conn.Close()
+		return fmt.Errorf("unexpected greeting %q", strings.TrimSpace(line))
+	}
+	var info natsInfo
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "INFO ")), &info); err != nil {
+		conn.Close()
+		return err
+	}
+
+	connect := fmt.Sprintf(`CONNECT {"verbose":false,"pedantic":false,"name":"verkaufsautomat","headers":%t}`+"\r\n", info.Headers)
+	if _, err := conn.Write([]byte(connect)); err != nil {
+		conn.Close()
+		return err
+	}
+
+	s.conn, s.reader, s.headers = conn, reader, info.Headers
+	return nil
+}
+
+func (s *NATSSink) publish(ctx context.Context, subject, id string, body []byte) error {
+	deadline := time.Now().Add(s.Timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	s.conn.SetDeadline(deadline)
+
+	var command string
+	if s.headers {
+		header := "NATS/1.0\r\nNats-Msg-Id: " + id + "\r\n\r\n"
+		command = fmt.Sprintf("HPUB %s %d %d\r\n%s%s\r\nPING\r\n", subject, len(header), len(header)+len(body), header, body)
+	} else {
+		command = fmt.Sprintf("PUB %s %d\r\n%s\r\nPING\r\n", subject, len(body), body)
+	}
+	if _, err := s.conn.Write([]byte(command)); err != nil {
+		return err
+	}
+
+	for {
+		line, err := s.reader.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "PONG":
+			return nil
+		case line == "PING":
+			if _, err := s.conn.Write([]byte("PONG\r\n")); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return errors.New("nats: " + strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		}
+	}
+}
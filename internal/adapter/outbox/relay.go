@@ -0,0 +1,178 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+	"verkaufsautomat/internal/core/domain/resource"
+	"verkaufsautomat/internal/core/events"
+	"verkaufsautomat/internal/core/logger"
+	ports "verkaufsautomat/internal/ports/resource"
+)
+
+// Message is a stored event as handed to a sink.
+type Message struct {
+	// ID stays the same when an event is delivered again, so sinks can pass
+	// it on for deduplication.
+	ID         string
+	Name       string
+	OccurredAt time.Time
+	Payload    json.RawMessage
+	Event      events.Event
+}
+
+// Sink is a destination for outbox events. Deliver must only return nil once
+// the sink has accepted the message.
+type Sink interface {
+	Name() string
+	Deliver(ctx context.Context, message Message) error
+}
+
+// Relay delivers outbox events to its sinks. An event is marked as published
+// only after every sink accepted it; otherwise it is retried with
+// exponential backoff. Retries skip the sinks that already accepted the
+// event, but a sink can still see an event more than once when recording
+// the attempt fails.
+//
+// Published events are deleted once they are older than Retention, every
+// PruneInterval. A zero Retention keeps them.
+type Relay struct {
+	Repository    ports.OutboxRepository
+	Sinks         []Sink
+	BatchSize     int
+	Backoff       time.Duration
+	MaxBackoff    time.Duration
+	PollInterval  time.Duration
+	Retention     time.Duration
+	PruneInterval time.Duration
+}
+
+// pruneBatchSize bounds the rows deleted by one statement, so that pruning
+// a large backlog does not hold locks for long.
+const pruneBatchSize = 1000
+
+func NewRelay(Repository ports.OutboxRepository, Sinks ...Sink) *Relay {
+	return &Relay{
+		Repository:    Repository,
+		Sinks:         Sinks,
+		BatchSize:     100,
+		Backoff:       time.Second,
+		MaxBackoff:    10 * time.Minute,
+		PollInterval:  500 * time.Millisecond,
+		PruneInterval: time.Hour,
+	}
+}
+
+// Run relays due events and prunes published ones until the context is
+// cancelled.
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.PollInterval)
+	defer ticker.Stop()
+	prune := time.NewTicker(r.PruneInterval)
+	defer prune.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.RelayDue(ctx)
+		case <-prune.C:
+			r.Prune(ctx)
+		}
+	}
+}
+
+// Prune deletes the events published more than Retention ago.
+func (r *Relay) Prune(ctx context.Context) {
+	if r.Retention <= 0 {
+		return
+	}
+	before := time.Now().Add(-r.Retention)
+	for ctx.Err() == nil {
+		deleted, err := r.Repository.DeletePublishedOutboxEvents(ctx, before, pruneBatchSize)
+		if err != nil {
+			logger.Error("Error pruning outbox events: " + err.Error())
+			return
+		}
+		if deleted < pruneBatchSize {
+			return
+		}
+	}
+}
+
+// RelayDue makes one delivery attempt for every event that is due.
+func (r *Relay) RelayDue(ctx context.Context) {
+	records, err := r.Repository.GetDueOutboxEvents(ctx, time.Now(), r.BatchSize)
+	if err != nil {
+		logger.Error("Error getting outbox events: " + err.Error())
+		return
+	}
+
+	for _, record := range records {
+		if ctx.Err() != nil {
+			return
+		}
+		r.record(&record, r.relay(ctx, &record))
+		if err := r.Repository.UpdateOutboxEvent(ctx, record); err != nil {
+			logger.Error("Error updating outbox event: " + err.Error())
+		}
+	}
+}
+
+// relay hands the event to every sink that has not accepted it yet and
+// adds the sinks that do to record.Sinks.
+func (r *Relay) relay(ctx context.Context, record *resource.OutboxEvent) error {
+	event, err := events.Decode(record.EventName, []byte(record.Payload))
+	if err != nil {
+		return err
+	}
+
+	message := Message{
+		ID:         record.EventID,
+		Name:       record.EventName,
+		OccurredAt: record.CreatedAt,
+		Payload:    json.RawMessage(record.Payload),
+		Event:      event,
+	}
+	for _, sink := range r.Sinks {
+		if accepted(*record, sink.Name()) {
+			continue
+		}
+		if err := sink.Deliver(ctx, message); err != nil {
+			return fmt.Errorf("%s: %w", sink.Name(), err)
+		}
+		record.Sinks = append(record.Sinks, sink.Name())
+	}
+	return nil
+}
+
+func accepted(record resource.OutboxEvent, sink string) bool {
+	for _, name := range record.Sinks {
+		if name == sink {
+			return true
+		}
+	}
+	return false
+}
+
+// record updates the event after an attempt, scheduling a retry with
+// exponential backoff when it failed.
+func (r *Relay) record(record *resource.OutboxEvent, err error) {
+	record.Attempts++
+	if err == nil {
+		now := time.Now()
+		record.PublishedAt = &now
+		record.LastError = ""
+		return
+	}
+
+	logger.Error("Error relaying event " + record.EventID + ": " + err.Error())
+	record.LastError = err.Error()
+	backoff := r.Backoff << (record.Attempts - 1)
+	if backoff > r.MaxBackoff || backoff <= 0 {
+		backoff = r.MaxBackoff
+	}
+	record.NextAttemptAt = time.Now().Add(backoff)
+}
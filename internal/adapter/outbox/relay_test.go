@@ -0,0 +1,210 @@
+package outbox
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"verkaufsautomat/internal/core/domain/resource"
+	"verkaufsautomat/internal/core/events"
+)
+
+// memoryRepository keeps outbox events in memory.
+type memoryRepository struct {
+	mu     sync.Mutex
+	events []resource.OutboxEvent
+}
+
+func (m *memoryRepository) CreateOutboxEvent(ctx context.Context, event *resource.OutboxEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	event.OutboxEventID = uint(len(m.events) + 1)
+	m.events = append(m.events, *event)
+	return nil
+}
+
+func (m *memoryRepository) UpdateOutboxEvent(ctx context.Context, event resource.OutboxEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range m.events {
+		if m.events[i].OutboxEventID == event.OutboxEventID {
+			m.events[i] = event
+		}
+	}
+	return nil
+}
+
+func (m *memoryRepository) GetDueOutboxEvents(ctx context.Context, now time.Time, limit int) ([]resource.OutboxEvent, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var due []resource.OutboxEvent
+	for _, event := range m.events {
+		if event.PublishedAt == nil && !event.NextAttemptAt.After(now) {
+			due = append(due, event)
+		}
+	}
+	return due, nil
+}
+
+func (m *memoryRepository) DeletePublishedOutboxEvents(ctx context.Context, before time.Time, limit int) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var kept []resource.OutboxEvent
+	var deleted int64
+	for _, event := range m.events {
+		if event.PublishedAt != nil && event.PublishedAt.Before(before) && deleted < int64(limit) {
+			deleted++
+			continue
+		}
+		kept = append(kept, event)
+	}
+	m.events = kept
+	return deleted, nil
+}
+
+// flakySink fails the first delivery of every event.
+type flakySink struct {
+	delivered []Message
+	failed    map[string]bool
+}
+
+func (s *flakySink) Name() string {
+	return "flaky"
+}
+
+func (s *flakySink) Deliver(ctx context.Context, message Message) error {
+	if !s.failed[message.ID] {
+		s.failed[message.ID] = true
+		return errors.New("unavailable")
+	}
+	s.delivered = append(s.delivered, message)
+	return nil
+}
+
+// recordingSink accepts every event.
+type recordingSink struct {
+	delivered []Message
+}
+
+func (s *recordingSink) Name() string {
+	return "recording"
+}
+
+func (s *recordingSink) Deliver(ctx context.Context, message Message) error {
+	s.delivered = append(s.delivered, message)
+	return nil
+}
+
+func TestRelay(t *testing.T) {
+	repository := &memoryRepository{}
+	repository.CreateOutboxEvent(context.Background(), &resource.OutboxEvent{
+		EventID:   "e1",
+		EventName: events.DepositMadeName,
+		Payload:   `{"user_id":2,"amount":50,"deposit":85}`,
+	})
+
+	reliable := &recordingSink{}
+	sink := &flakySink{failed: map[string]bool{}}
+	relay := NewRelay(repository, reliable, sink)
+	relay.Backoff = time.Millisecond
+
+	relay.RelayDue(context.Background())
+	if event := repository.events[0]; event.PublishedAt != nil || event.Attempts != 1 || !strings.HasPrefix(event.LastError, "flaky: ") {
+		t.Fatalf("Expected a failed attempt to be recorded, got %+v", event)
+	}
+
+	time.Sleep(5 * time.Millisecond)
+	relay.RelayDue(context.Background())
+	if repository.events[0].PublishedAt == nil {
+		t.Fatal("Expected the event to be published on the second attempt")
+	}
+	if len(sink.delivered) != 1 {
+		t.Fatalf("Expected one delivery, got %d", len(sink.delivered))
+	}
+	if len(reliable.delivered) != 1 {
+		t.Errorf("Expected the retry to skip the sink that accepted the event, got %d deliveries", len(reliable.delivered))
+	}
+	if event, ok := sink.delivered[0].Event.(events.DepositMade); !ok || event.Deposit != 85 {
+		t.Errorf("Unexpected event %#v", sink.delivered[0].Event)
+	}
+
+	relay.RelayDue(context.Background())
+	if len(sink.delivered) != 1 {
+		t.Error("Published events must not be relayed again")
+	}
+}
+
+func TestRelay_Prune(t *testing.T) {
+	repository := &memoryRepository{}
+	old, recent := time.Now().Add(-48*time.Hour), time.Now().Add(-time.Hour)
+	for _, published := range []*time.Time{&old, &recent, nil} {
+		repository.CreateOutboxEvent(context.Background(), &resource.OutboxEvent{EventName: events.DepositMadeName, PublishedAt: published})
+	}
+
+	relay := NewRelay(repository)
+	relay.Prune(context.Background())
+	if len(repository.events) != 3 {
+		t.Fatalf("Expected events to be kept without a retention, got %d", len(repository.events))
+	}
+
+	relay.Retention = 24 * time.Hour
+	relay.Prune(context.Background())
+	if len(repository.events) != 2 || !repository.events[0].PublishedAt.Equal(recent) {
+		t.Errorf("Expected only the event published two days ago to be deleted, got %+v", repository.events)
+	}
+}
+
+func TestNATSSink(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.Write([]byte(`INFO {"headers":true}` + "\r\n"))
+
+		reader := bufio.NewReader(conn)
+		var published strings.Builder
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			if strings.HasPrefix(line, "PING") {
+				received <- published.String()
+				conn.Write([]byte("PONG\r\n"))
+				continue
+			}
+			if !strings.HasPrefix(line, "CONNECT") {
+				published.WriteString(line)
+			}
+		}
+	}()
+
+	sink := NewNATSSink("nats://" + listener.Addr().String())
+	defer sink.Close()
+
+	err = sink.Deliver(context.Background(), Message{ID: "e1", Name: events.DepositMadeName, Payload: []byte(`{"deposit":85}`)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	published := <-received
+	if !strings.HasPrefix(published, "HPUB verkaufsautomat.deposit.made ") {
+		t.Errorf("Unexpected publish %q", published)
+	}
+	if !strings.Contains(published, "Nats-Msg-Id: e1") || !strings.Contains(published, `"data":{"deposit":85}`) {
+		t.Errorf("Expected the event ID and payload in %q", published)
+	}
+}
@@ -0,0 +1,33 @@
+package outbox
+
+import (
+	"context"
+	"verkaufsautomat/internal/adapter/webhook"
+	"verkaufsautomat/internal/core/logger"
+)
+
+// LogSink writes every event to the application log.
+type LogSink struct{}
+
+func (LogSink) Name() string {
+	return "log"
+}
+
+func (LogSink) Deliver(ctx context.Context, message Message) error {
+	logger.Info("Event " + message.Name + " " + message.ID + ": " + string(message.Payload))
+	return nil
+}
+
+// WebhookSink queues webhook deliveries for the events sellers subscribed
+// to. The dispatcher sends and retries them on its own schedule.
+type WebhookSink struct {
+	Dispatcher *webhook.Dispatcher
+}
+
+func (WebhookSink) Name() string {
+	return "webhook"
+}
+
+func (s WebhookSink) Deliver(ctx context.Context, message Message) error {
+	return s.Dispatcher.Queue(ctx, message.ID, message.Event)
+}
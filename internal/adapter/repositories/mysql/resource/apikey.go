@@ -0,0 +1,44 @@
+package resource
+
+import (
+	"context"
+	"time"
+	"verkaufsautomat/internal/core/domain/resource"
+)
+
+func (m MachineRepositoryDB) CreateAPIKey(ctx context.Context, key *resource.APIKey) error {
+	return m.db.WithContext(ctx).Create(key).Error
+}
+
+func (m MachineRepositoryDB) GetAPIKeys(ctx context.Context) ([]resource.APIKey, error) {
+	var keys []resource.APIKey
+	err := m.db.WithContext(ctx).Order("api_key_id").Find(&keys).Error
+	return keys, err
+}
+
+func (m MachineRepositoryDB) GetAPIKeyById(ctx context.Context, id int) (resource.APIKey, error) {
+	var key resource.APIKey
+	if err := m.db.WithContext(ctx).Where("api_key_id = ?", id).First(&key).Error; err != nil {
+		return key, notFound(err, resource.ErrAPIKeyNotFound)
+	}
+	return key, nil
+}
+
+func (m MachineRepositoryDB) GetAPIKeyByPrefix(ctx context.Context, prefix string) (resource.APIKey, error) {
+	var key resource.APIKey
+	if err := m.db.WithContext(ctx).Where("prefix = ?", prefix).First(&key).Error; err != nil {
+		return key, notFound(err, resource.ErrInvalidAPIKey)
+	}
+	return key, nil
+}
+
+// RevokeAPIKey keeps the key, so that it still shows up in lists and audit
+// trails, but marks it unusable. Revoking twice keeps the first time.
+func (m MachineRepositoryDB) RevokeAPIKey(ctx context.Context, id int, revokedAt time.Time) error {
+	return m.db.WithContext(ctx).Model(&resource.APIKey{}).
+		Where("api_key_id = ? AND revoked_at IS NULL", id).Update("revoked_at", revokedAt).Error
+}
+
+func (m MachineRepositoryDB) TouchAPIKey(ctx context.Context, id int, usedAt time.Time) error {
+	return m.db.WithContext(ctx).Model(&resource.APIKey{}).Where("api_key_id = ?", id).Update("last_used_at", usedAt).Error
+}
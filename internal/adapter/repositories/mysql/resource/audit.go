@@ -0,0 +1,58 @@
+package resource
+
+import (
+	"context"
+	"strings"
+	"verkaufsautomat/internal/core/domain/resource"
+	"verkaufsautomat/internal/core/logger"
+)
+
+func (m MachineRepositoryDB) CreateAuditEntry(ctx context.Context, entry *resource.AuditEntry) error {
+	return m.db.WithContext(ctx).Create(entry).Error
+}
+
+func (m MachineRepositoryDB) GetAuditEntries(ctx context.Context, filter resource.AuditFilter) ([]resource.AuditEntry, error) {
+	query := m.db.WithContext(ctx).Model(&resource.AuditEntry{})
+	if filter.ActorID != 0 {
+		query = query.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.TargetType != "" {
+		query = query.Where("target_type = ?", filter.TargetType)
+	}
+	if filter.TargetID != "" {
+		query = query.Where("target_id = ?", filter.TargetID)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("created_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("created_at < ?", filter.To)
+	}
+
+	var entries []resource.AuditEntry
+	err := query.Order("audit_id desc").Limit(filter.Limit).Offset(filter.Offset).Find(&entries).Error
+	return entries, err
+}
+
+// ProtectAuditEntries installs triggers that reject updates and deletes of
+// audit entries, so the table stays append-only even for direct SQL access.
+func (m MachineRepositoryDB) ProtectAuditEntries() {
+	for _, operation := range []string{"update", "delete"} {
+		name := "audit_entries_no_" + operation
+
+		var count int64
+		m.db.Raw("SELECT COUNT(*) FROM information_schema.triggers WHERE trigger_schema = DATABASE() AND trigger_name = ?", name).Scan(&count)
+		if count > 0 {
+			continue
+		}
+
+		err := m.db.Exec("CREATE TRIGGER " + name + " BEFORE " + strings.ToUpper(operation) + " ON audit_entries FOR EACH ROW " +
+			"SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'audit entries are append-only'").Error
+		if err != nil {
+			logger.Error("Error protecting audit entries: " + err.Error())
+		}
+	}
+}
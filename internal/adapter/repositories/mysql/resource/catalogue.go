@@ -0,0 +1,37 @@
+package resource
+
+import (
+	"context"
+	"database/sql"
+	"time"
+	"verkaufsautomat/internal/core/domain/resource"
+	"verkaufsautomat/internal/core/events"
+)
+
+// GetCatalogueModifiedAt takes deletions from the outbox, as deleted
+// products leave no row behind. Renamed categories count as changes too.
+// Categories in use cannot be deleted, so their deletions do not. Each
+// maximum is read from an index. Deletions older than the outbox retention
+// are no longer seen, which can only move the time back to before a
+// change clients already have.
+func (m MachineRepositoryDB) GetCatalogueModifiedAt(ctx context.Context) (time.Time, error) {
+	var updated, renamed, deleted sql.NullTime
+	if err := m.db.WithContext(ctx).Model(&resource.Product{}).Select("MAX(updated_at)").Row().Scan(&updated); err != nil {
+		return time.Time{}, err
+	}
+	if err := m.db.WithContext(ctx).Model(&resource.Category{}).Select("MAX(updated_at)").Row().Scan(&renamed); err != nil {
+		return time.Time{}, err
+	}
+	err := m.db.WithContext(ctx).Model(&resource.OutboxEvent{}).Where("event_name = ?", events.ProductDeletedName).
+		Select("MAX(created_at)").Row().Scan(&deleted)
+	if err != nil {
+		return time.Time{}, err
+	}
+	modifiedAt := updated.Time
+	for _, t := range []time.Time{renamed.Time, deleted.Time} {
+		if t.After(modifiedAt) {
+			modifiedAt = t
+		}
+	}
+	return modifiedAt, nil
+}
@@ -0,0 +1,108 @@
+package resource
+
+import (
+	"context"
+	"verkaufsautomat/internal/core/domain/resource"
+)
+
+// SearchProducts matches tags and allergens with FIND_IN_SET, as they are
+// stored as comma separated lists.
+func (m MachineRepositoryDB) SearchProducts(ctx context.Context, filter resource.ProductFilter) ([]resource.Product, error) {
+	query := m.db.WithContext(ctx).Preload("Categories").Order("product_id")
+	if filter.Category != "" {
+		query = query.Where("product_id IN (?)", m.db.Table("product_categories").
+			Select("product_categories.product_id").
+			Joins("JOIN categories ON categories.category_id = product_categories.category_id").
+			Where("categories.name = ?", filter.Category))
+	}
+	for _, tag := range filter.Tags {
+		query = query.Where("FIND_IN_SET(?, tags)", tag)
+	}
+	for _, allergen := range filter.WithoutAllergens {
+		query = query.Where("NOT FIND_IN_SET(?, COALESCE(attr_allergens, ''))", allergen)
+	}
+	if filter.Vegan != nil {
+		query = query.Where("attr_vegan = ?", *filter.Vegan)
+	}
+	if filter.MaxCalories != nil {
+		query = query.Where("attr_calories <= ?", *filter.MaxCalories)
+	}
+	if filter.MinVolumeML != nil {
+		query = query.Where("attr_volume_ml >= ?", *filter.MinVolumeML)
+	}
+	if filter.MaxVolumeML != nil {
+		query = query.Where("attr_volume_ml <= ?", *filter.MaxVolumeML)
+	}
+
+	var products []resource.Product
+	err := query.Find(&products).Error
+	return products, err
+}
+
+func (m MachineRepositoryDB) CreateCategory(ctx context.Context, category *resource.Category) error {
+	if exists, err := m.categoryNameTaken(ctx, category.Name, 0); err != nil || exists {
+		if exists {
+			return resource.ErrCategoryExists
+		}
+		return err
+	}
+	return m.db.WithContext(ctx).Create(category).Error
+}
+
+func (m MachineRepositoryDB) GetCategories(ctx context.Context) ([]resource.Category, error) {
+	var categories []resource.Category
+	err := m.db.WithContext(ctx).Order("name").Find(&categories).Error
+	return categories, err
+}
+
+func (m MachineRepositoryDB) GetCategoryById(ctx context.Context, id int) (resource.Category, error) {
+	var category resource.Category
+	if err := m.db.WithContext(ctx).Where("category_id = ?", id).First(&category).Error; err != nil {
+		return category, notFound(err, resource.ErrCategoryNotFound)
+	}
+	return category, nil
+}
+
+func (m MachineRepositoryDB) GetCategoriesByIds(ctx context.Context, ids []uint) ([]resource.Category, error) {
+	var categories []resource.Category
+	if len(ids) == 0 {
+		return categories, nil
+	}
+	err := m.db.WithContext(ctx).Where("category_id IN ?", ids).Order("name").Find(&categories).Error
+	return categories, err
+}
+
+func (m MachineRepositoryDB) UpdateCategory(ctx context.Context, category *resource.Category) error {
+	if _, err := m.GetCategoryById(ctx, int(category.CategoryID)); err != nil {
+		return err
+	}
+	if exists, err := m.categoryNameTaken(ctx, category.Name, category.CategoryID); err != nil || exists {
+		if exists {
+			return resource.ErrCategoryExists
+		}
+		return err
+	}
+	return m.db.WithContext(ctx).Save(category).Error
+}
+
+// DeleteCategory refuses to delete categories that products are in.
+func (m MachineRepositoryDB) DeleteCategory(ctx context.Context, id int) error {
+	category, err := m.GetCategoryById(ctx, id)
+	if err != nil {
+		return err
+	}
+	var products int64
+	if err := m.db.WithContext(ctx).Table("product_categories").Where("category_id = ?", id).Count(&products).Error; err != nil {
+		return err
+	}
+	if products > 0 {
+		return resource.ErrCategoryInUse
+	}
+	return m.db.WithContext(ctx).Delete(&category).Error
+}
+
+func (m MachineRepositoryDB) categoryNameTaken(ctx context.Context, name string, except uint) (bool, error) {
+	var count int64
+	err := m.db.WithContext(ctx).Model(&resource.Category{}).Where("name = ? AND category_id <> ?", name, except).Count(&count).Error
+	return count > 0, err
+}
@@ -0,0 +1,70 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+	"verkaufsautomat/internal/core/domain/resource"
+)
+
+// SchemaVersion is the version of the schema this build expects. Bump it
+// whenever the migrations in migrate change.
+const SchemaVersion = 10
+
+// schemaMigration records a schema version once its migrations have run.
+type schemaMigration struct {
+	Version   int `gorm:"primaryKey;autoIncrement:false"`
+	AppliedAt time.Time
+}
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// HealthCheck pings the database and checks that the schema is at
+// SchemaVersion.
+func (m MachineRepositoryDB) HealthCheck(ctx context.Context) []resource.DependencyStatus {
+	return []resource.DependencyStatus{
+		probe("database", func() (string, error) {
+			db, err := m.db.DB()
+			if err != nil {
+				return "", err
+			}
+			return "", db.PingContext(ctx)
+		}),
+		probe("migrations", func() (string, error) {
+			version, err := m.schemaVersion(ctx)
+			if err != nil {
+				return "", err
+			}
+			detail := "schema is at version " + strconv.Itoa(version) + ", expected " + strconv.Itoa(SchemaVersion)
+			if version < SchemaVersion {
+				return "", errors.New(detail)
+			}
+			return detail, nil
+		}),
+	}
+}
+
+func (m MachineRepositoryDB) schemaVersion(ctx context.Context) (int, error) {
+	var version int
+	err := m.db.WithContext(ctx).Model(&schemaMigration{}).Select("COALESCE(MAX(version), 0)").Scan(&version).Error
+	return version, err
+}
+
+// probe runs check and records how long it took.
+func probe(name string, check func() (string, error)) resource.DependencyStatus {
+	start := time.Now()
+	detail, err := check()
+	status := resource.DependencyStatus{
+		Name:      name,
+		Healthy:   err == nil,
+		LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+		Detail:    detail,
+	}
+	if err != nil {
+		status.Error = err.Error()
+	}
+	return status
+}
@@ -0,0 +1,66 @@
+package resource
+
+import (
+	"context"
+	"time"
+	"verkaufsautomat/internal/core/domain/resource"
+)
+
+func (m MachineRepositoryDB) GetMFA(ctx context.Context, userID int) (resource.MFA, error) {
+	var mfa resource.MFA
+	if err := m.db.WithContext(ctx).Where("user_id = ?", userID).First(&mfa).Error; err != nil {
+		return mfa, notFound(err, resource.ErrMFANotEnabled)
+	}
+	return mfa, nil
+}
+
+func (m MachineRepositoryDB) SaveMFA(ctx context.Context, mfa *resource.MFA) error {
+	return m.db.WithContext(ctx).Save(mfa).Error
+}
+
+func (m MachineRepositoryDB) UseMFAStep(ctx context.Context, userID int, step int64) error {
+	result := m.db.WithContext(ctx).Model(&resource.MFA{}).
+		Where("user_id = ? AND last_step < ?", userID, step).Update("last_step", step)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return resource.ErrInvalidMFACode
+	}
+	return nil
+}
+
+func (m MachineRepositoryDB) DeleteMFA(ctx context.Context, userID int) error {
+	db := m.db.WithContext(ctx)
+	if err := db.Where("user_id = ?", userID).Delete(&resource.RecoveryCode{}).Error; err != nil {
+		return err
+	}
+	return db.Where("user_id = ?", userID).Delete(&resource.MFA{}).Error
+}
+
+func (m MachineRepositoryDB) ReplaceRecoveryCodes(ctx context.Context, userID int, hashes []string) error {
+	db := m.db.WithContext(ctx)
+	if err := db.Where("user_id = ?", userID).Delete(&resource.RecoveryCode{}).Error; err != nil {
+		return err
+	}
+	codes := make([]resource.RecoveryCode, len(hashes))
+	for i, hash := range hashes {
+		codes[i] = resource.RecoveryCode{UserID: uint(userID), Hash: hash}
+	}
+	if len(codes) == 0 {
+		return nil
+	}
+	return db.Create(&codes).Error
+}
+
+func (m MachineRepositoryDB) UseRecoveryCode(ctx context.Context, userID int, hash string, usedAt time.Time) error {
+	result := m.db.WithContext(ctx).Model(&resource.RecoveryCode{}).
+		Where("user_id = ? AND hash = ? AND used_at IS NULL", userID, hash).Limit(1).Update("used_at", usedAt)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return resource.ErrInvalidMFACode
+	}
+	return nil
+}
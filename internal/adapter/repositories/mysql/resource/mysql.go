@@ -1,43 +1,99 @@
 package resource
 
 import (
-	"github.com/joho/godotenv"
+	"context"
+	"database/sql"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
-	"log"
-	"os"
+	"time"
+	"verkaufsautomat/internal/config"
 	"verkaufsautomat/internal/core/domain/resource"
+	"verkaufsautomat/internal/core/logger"
 )
 
 type MachineRepositoryDB struct {
 	db *gorm.DB
 }
 
-var err = godotenv.Load("verkaufsautomat.env")
+// maxConnectBackoff caps the wait between connection attempts.
+const maxConnectBackoff = 30 * time.Second
 
-var DbUsername = os.Getenv("MYSQL_USER")
-var DbPassword = os.Getenv("MYSQL_PASSWORD")
-var DbName = os.Getenv("MYSQL_DATABASE")
-var DbHost = os.Getenv("MYSQL_DB_HOST")
-var DbPort = os.Getenv("MYSQL_DB_PORT")
+// ConnectMachineRepositoryDB connects to the database and migrates it. While
+// the database is unreachable, as it often is when both are started
+// together, it retries with a growing backoff until ctx is done.
+func ConnectMachineRepositoryDB(ctx context.Context, database config.Database, admin config.Admin) (*MachineRepositoryDB, error) {
+	backoff := time.Second
+	for {
+		client, err := gorm.Open(mysql.Open(database.DSN()), &gorm.Config{})
+		if err == nil {
+			if err := client.Use(tracingPlugin{}); err != nil {
+				return nil, err
+			}
+			repository := &MachineRepositoryDB{client}
+			if err := repository.migrate(ctx, admin); err != nil {
+				return nil, err
+			}
+			return repository, nil
+		}
 
-func NewMachineRepositoryDB() *MachineRepositoryDB {
-	dsn := DbUsername + ":" + DbPassword + "@tcp" + "(" + DbHost + ":" + DbPort + ")/" + DbName + "?" + "charset=utf8mb4&parseTime=True&loc=Local"
-	client, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
-	if err != nil {
-		log.Fatal(err)
+		logger.With(logger.Fields{"retry_in": backoff.String()}).Warn("Database unavailable: " + err.Error())
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > maxConnectBackoff {
+			backoff = maxConnectBackoff
+		}
+	}
+}
+
+// migrate brings the schema up to SchemaVersion and seeds roles, permissions
+// and the admin account.
+func (m MachineRepositoryDB) migrate(ctx context.Context, admin config.Admin) error {
+	db := m.db.WithContext(ctx)
+	if err := numberWebhookReplays(db); err != nil {
+		return err
 	}
+	if err := db.AutoMigrate(&resource.Category{}, &resource.Product{}, &resource.User{}, &resource.Role{}, &resource.Permission{}, &resource.RolePermission{}, &resource.Order{}, &resource.Webhook{}, &resource.WebhookDelivery{}, &resource.OutboxEvent{}, &resource.AuditEntry{}, &resource.PasswordReset{}, &resource.APIKey{}, &resource.MFA{}, &resource.RecoveryCode{}, &schemaMigration{}); err != nil {
+		return err
+	}
+
+	m.AutoPopulateRoleTable()
+	m.AutoPopulatePermissionTable()
+	m.AssignPermissionToRole()
+	m.AssignPermissionToRole2()
+	m.ProtectAuditEntries()
+	m.AutoPopulateAdmin(admin.Username, admin.Password)
+
+	return db.Where(schemaMigration{Version: SchemaVersion}).Attrs(schemaMigration{AppliedAt: time.Now()}).FirstOrCreate(&schemaMigration{}).Error
+}
 
-	client.AutoMigrate(&resource.Product{}, &resource.User{}, &resource.Role{}, &resource.Permission{}, &resource.RolePermission{})
+// numberWebhookReplays fills in the replay column of deliveries stored
+// before it existed, so that the unique index on webhook, event and replay
+// can be created.
+func numberWebhookReplays(db *gorm.DB) error {
+	migrator := db.Migrator()
+	if !migrator.HasTable(&resource.WebhookDelivery{}) || migrator.HasColumn(&resource.WebhookDelivery{}, "Replay") {
+		return nil
+	}
+	if err := migrator.AddColumn(&resource.WebhookDelivery{}, "Replay"); err != nil {
+		return err
+	}
+	return db.Exec("UPDATE webhook_deliveries d SET replay = (SELECT COUNT(*) FROM (SELECT webhook_id, event_id, delivery_id FROM webhook_deliveries) e " +
+		"WHERE e.webhook_id = d.webhook_id AND e.event_id = d.event_id AND e.delivery_id < d.delivery_id)").Error
+}
 
-	autoPopulateRoleTable := MachineRepositoryDB{db: client}
-	autoPopulateRoleTable.AutoPopulateRoleTable()
-	autoPopulatePermissionTable := MachineRepositoryDB{db: client}
-	autoPopulatePermissionTable.AutoPopulatePermissionTable()
-	assignPermissionToRole := MachineRepositoryDB{db: client}
-	assignPermissionToRole.AssignPermissionToRole()
-	assignPermissionToRole2 := MachineRepositoryDB{db: client}
-	assignPermissionToRole2.AssignPermissionToRole2()
+// DB returns the connection pool, for example to export its statistics.
+func (m MachineRepositoryDB) DB() (*sql.DB, error) {
+	return m.db.DB()
+}
 
-	return &MachineRepositoryDB{client}
+// Close closes the connection pool.
+func (m MachineRepositoryDB) Close() error {
+	db, err := m.db.DB()
+	if err != nil {
+		return err
+	}
+	return db.Close()
 }
@@ -0,0 +1,35 @@
+package resource
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"time"
+	"verkaufsautomat/internal/core/domain/resource"
+	ports "verkaufsautomat/internal/ports/resource"
+)
+
+func (m MachineRepositoryDB) Transaction(ctx context.Context, fn func(repository ports.MachineRepository) error) error {
+	return m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(MachineRepositoryDB{db: tx})
+	})
+}
+
+func (m MachineRepositoryDB) CreateOutboxEvent(ctx context.Context, event *resource.OutboxEvent) error {
+	return m.db.WithContext(ctx).Create(event).Error
+}
+
+func (m MachineRepositoryDB) UpdateOutboxEvent(ctx context.Context, event resource.OutboxEvent) error {
+	return m.db.WithContext(ctx).Save(&event).Error
+}
+
+func (m MachineRepositoryDB) GetDueOutboxEvents(ctx context.Context, now time.Time, limit int) ([]resource.OutboxEvent, error) {
+	var events []resource.OutboxEvent
+	err := m.db.WithContext(ctx).Where("published_at IS NULL AND next_attempt_at <= ?", now).
+		Order("outbox_event_id").Limit(limit).Find(&events).Error
+	return events, err
+}
+
+func (m MachineRepositoryDB) DeletePublishedOutboxEvents(ctx context.Context, before time.Time, limit int) (int64, error) {
+	result := m.db.WithContext(ctx).Where("published_at < ?", before).Limit(limit).Delete(&resource.OutboxEvent{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,56 @@
+package resource
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"time"
+	"verkaufsautomat/internal/core/domain/resource"
+)
+
+func (m MachineRepositoryDB) GetUserByUsername(ctx context.Context, username string) (resource.User, error) {
+	var user resource.User
+	if err := m.db.WithContext(ctx).Where("username = ?", username).First(&user).Error; err != nil {
+		return user, notFound(err, resource.ErrUserNotFound)
+	}
+	return user, nil
+}
+
+func (m MachineRepositoryDB) UpdatePassword(ctx context.Context, userID int, passwordHash string) error {
+	result := m.db.WithContext(ctx).Model(&resource.User{}).Where("user_id = ?", userID).
+		Updates(map[string]interface{}{"password": passwordHash, "token_version": gorm.Expr("token_version + 1")})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return resource.ErrUserNotFound
+	}
+	return nil
+}
+
+func (m MachineRepositoryDB) CreatePasswordReset(ctx context.Context, reset *resource.PasswordReset) error {
+	return m.db.WithContext(ctx).Create(reset).Error
+}
+
+func (m MachineRepositoryDB) GetPasswordReset(ctx context.Context, tokenHash string) (resource.PasswordReset, error) {
+	var reset resource.PasswordReset
+	if err := m.db.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&reset).Error; err != nil {
+		return reset, notFound(err, resource.ErrInvalidResetToken)
+	}
+	return reset, nil
+}
+
+func (m MachineRepositoryDB) UsePasswordReset(ctx context.Context, resetID int, usedAt time.Time) error {
+	result := m.db.WithContext(ctx).Model(&resource.PasswordReset{}).
+		Where("reset_id = ? AND used_at IS NULL", resetID).Update("used_at", usedAt)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return resource.ErrInvalidResetToken
+	}
+	return nil
+}
+
+func (m MachineRepositoryDB) RevokePasswordResets(ctx context.Context, userID int) error {
+	return m.db.WithContext(ctx).Where("user_id = ? AND used_at IS NULL", userID).Delete(&resource.PasswordReset{}).Error
+}
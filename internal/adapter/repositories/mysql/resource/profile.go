@@ -0,0 +1,38 @@
+package resource
+
+import (
+	"context"
+	"verkaufsautomat/internal/core/domain/resource"
+)
+
+func (m MachineRepositoryDB) DeleteUser(ctx context.Context, userID int) error {
+	result := m.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&resource.User{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return resource.ErrUserNotFound
+	}
+	return nil
+}
+
+func (m MachineRepositoryDB) AnonymiseOrders(ctx context.Context, buyerID int) error {
+	return m.db.WithContext(ctx).Model(&resource.Order{}).Where("buyer_id = ?", buyerID).Update("buyer_id", 0).Error
+}
+
+func (m MachineRepositoryDB) GetRole(ctx context.Context, roleID int) (resource.Role, error) {
+	var role resource.Role
+	if err := m.db.WithContext(ctx).Where("role_id = ?", roleID).First(&role).Error; err != nil {
+		return role, notFound(err, resource.ErrInvalidRole)
+	}
+	return role, nil
+}
+
+func (m MachineRepositoryDB) GetPermissionsByRole(ctx context.Context, roleID int) ([]string, error) {
+	var permissions []string
+	err := m.db.WithContext(ctx).Model(&resource.Permission{}).Distinct("permissions.permission_name").
+		Joins("JOIN role_permissions ON role_permissions.permission_id = permissions.permission_id").
+		Where("role_permissions.role_id = ?", roleID).Order("permissions.permission_name").
+		Pluck("permissions.permission_name", &permissions).Error
+	return permissions, err
+}
@@ -1,8 +1,14 @@
 package resource
 
 import (
-	"fmt"
+	"context"
+	"errors"
+	"github.com/go-sql-driver/mysql"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"sync"
+	"time"
 	"verkaufsautomat/internal/core/domain/resource"
 	"verkaufsautomat/internal/core/logger"
 )
@@ -16,15 +22,35 @@ func ComparePassword(hashedPassword string, password string) (bool, error) {
 	return true, nil
 }
 
+// AutoPopulateRoleTable stores the roles under the fixed IDs the code relies
+// on, correcting rows left behind by earlier versions.
 func (m MachineRepositoryDB) AutoPopulateRoleTable() {
-	role := resource.Role{
-		RoleName: "buyer",
+	m.db.Save(&resource.Role{RoleId: resource.BuyerRoleID, RoleName: "buyer"})
+	m.db.Save(&resource.Role{RoleId: resource.SellerRoleID, RoleName: "seller"})
+	m.db.Save(&resource.Role{RoleId: resource.AdminRoleID, RoleName: "admin"})
+}
+
+// AutoPopulateAdmin creates the initial admin account when a username and
+// password are configured and no such user exists yet. Further admins are
+// appointed through the role endpoint.
+func (m MachineRepositoryDB) AutoPopulateAdmin(username, password string) {
+	if username == "" || password == "" {
+		return
+	}
+	var existing resource.User
+	if m.db.Where("username = ?", username).First(&existing).Error == nil {
+		return
 	}
-	m.db.Create(&role)
-	role2 := resource.Role{
-		RoleName: "seller",
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		logger.Error("Error hashing admin password: " + err.Error())
+		return
+	}
+	admin := resource.User{Username: username, Password: string(hashed), RoleID: resource.AdminRoleID}
+	if err := m.db.Create(&admin).Error; err != nil {
+		logger.Error("Error creating admin: " + err.Error())
 	}
-	m.db.Create(&role2)
 }
 
 func (m MachineRepositoryDB) AutoPopulatePermissionTable() {
@@ -96,9 +122,13 @@ func (m MachineRepositoryDB) AssignPermissionToRole2() {
 	m.db.Create(&rolePermission3)
 }
 
-func (m MachineRepositoryDB) CreateProduct(product *resource.Product) error {
-	m.db.Create(&product)
-	return nil
+// CreateProduct links the product to its categories, which must exist,
+// rather than creating them.
+func (m MachineRepositoryDB) CreateProduct(ctx context.Context, product *resource.Product) error {
+	if err := m.db.WithContext(ctx).Omit(clause.Associations).Create(product).Error; err != nil {
+		return err
+	}
+	return m.db.WithContext(ctx).Model(product).Association("Categories").Replace(product.Categories)
 }
 
 func (m MachineRepositoryDB) DeleteProduct(product resource.Product) error {
@@ -106,85 +136,241 @@ func (m MachineRepositoryDB) DeleteProduct(product resource.Product) error {
 	return nil
 }
 
-func (m MachineRepositoryDB) UpdateProductByID(id int, product *resource.Product) error {
-	m.db.Model(&product).Where("product_id = ?", id).Save(&product)
-	return nil
+func (m MachineRepositoryDB) UpdateProductByID(ctx context.Context, id int, product *resource.Product) error {
+	var existing resource.Product
+	if err := m.db.WithContext(ctx).Where("product_id = ?", id).First(&existing).Error; err != nil {
+		return notFound(err, resource.ErrProductNotFound)
+	}
+	product.ProductID = existing.ProductID
+	if err := m.db.WithContext(ctx).Omit(clause.Associations).Save(product).Error; err != nil {
+		return err
+	}
+	return m.db.WithContext(ctx).Model(product).Association("Categories").Replace(product.Categories)
 }
 
-func (m MachineRepositoryDB) DeleteProductByID(id int) error {
+func (m MachineRepositoryDB) DeleteProductByID(ctx context.Context, id int) error {
 	var product resource.Product
-	m.db.Where("product_id = ?", id).First(&product)
-	m.db.Delete(&product)
-	return nil
+	if err := m.db.WithContext(ctx).Where("product_id = ?", id).First(&product).Error; err != nil {
+		return notFound(err, resource.ErrProductNotFound)
+	}
+	if err := m.db.WithContext(ctx).Model(&product).Association("Categories").Clear(); err != nil {
+		return err
+	}
+	return m.db.WithContext(ctx).Delete(&product).Error
 }
 
-func (m MachineRepositoryDB) GetProductById(id int) (resource.Product, error) {
+func (m MachineRepositoryDB) GetProductById(ctx context.Context, id int) (resource.Product, error) {
 	var product resource.Product
-	m.db.Where("product_id = ?", id).First(&product)
+	if err := m.db.WithContext(ctx).Preload("Categories").Where("product_id = ?", id).First(&product).Error; err != nil {
+		return product, notFound(err, resource.ErrProductNotFound)
+	}
 	return product, nil
 }
 
-func (m MachineRepositoryDB) GetProducts() ([]resource.Product, error) {
-	var products []resource.Product
-	m.db.Find(&products)
-	return products, nil
+func (m MachineRepositoryDB) GetProducts(ctx context.Context) ([]resource.Product, error) {
+	return m.SearchProducts(ctx, resource.ProductFilter{})
 }
 
-func (m MachineRepositoryDB) Register(user *resource.User) error {
+func (m MachineRepositoryDB) Register(ctx context.Context, user *resource.User) error {
 	var user2 resource.User
-	m.db.Where("username = ?", user.Username).First(&user2)
+	m.db.WithContext(ctx).Where("username = ?", user.Username).First(&user2)
 	if user2.Username != "" {
-		logger.Error("User already exists")
-		return fmt.Errorf("user already exists")
+		logger.Ctx(ctx).Error("User already exists")
+		return resource.ErrUserExists
 	}
-	result := m.db.Create(user)
-	return result.Error
+	result := m.db.WithContext(ctx).Create(user)
+	return duplicate(result.Error, resource.ErrUserExists)
 }
 
-func (m MachineRepositoryDB) Login(user *resource.User) error {
+// Login looks the user up by username and checks the password. Unknown
+// users and wrong passwords both yield ErrInvalidCredentials and take about
+// as long, so that usernames cannot be probed. The user is filled in when
+// the username exists, even if the password is wrong.
+func (m MachineRepositoryDB) Login(ctx context.Context, user *resource.User) error {
 
 	InputPassword := user.Password
-	fmt.Println("InputPassword: ", InputPassword)
-	result := m.db.Where("username = ?", user.Username).First(user)
+	result := m.db.WithContext(ctx).Where("username = ?", user.Username).First(user)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		logger.Ctx(ctx).With(logger.Fields{"username": user.Username}).Warn("User does not exist")
+		ComparePassword(dummyHash(), InputPassword)
+		return resource.ErrInvalidCredentials
+	}
 	if result.Error != nil {
-		logger.Error("User does not exist")
 		return result.Error
 	}
 
 	_, err := ComparePassword(user.Password, InputPassword)
 	if err != nil {
-		logger.Error("Password is incorrect")
-		return err
+		logger.Ctx(ctx).With(logger.Fields{"username": user.Username}).Warn("Password is incorrect")
+		return resource.ErrInvalidCredentials
 	}
 
 	return nil
 }
 
-func (m MachineRepositoryDB) HealthCheck() error {
+var (
+	dummyHashOnce  sync.Once
+	dummyHashValue string
+)
+
+// dummyHash is compared against when the user does not exist, so that the
+// response takes as long as for a wrong password.
+func dummyHash() string {
+	dummyHashOnce.Do(func() {
+		hash, _ := bcrypt.GenerateFromPassword([]byte("not a password"), bcrypt.DefaultCost)
+		dummyHashValue = string(hash)
+	})
+	return dummyHashValue
+}
+
+// UpdateLoginState stores the failed login count and lockout of a user
+// without touching other columns.
+func (m MachineRepositoryDB) UpdateLoginState(ctx context.Context, userID int, failedLogins int, lockedUntil *time.Time) error {
+	return m.db.WithContext(ctx).Model(&resource.User{}).Where("user_id = ?", userID).
+		Updates(map[string]interface{}{"failed_logins": failedLogins, "locked_until": lockedUntil}).Error
+}
+
+func (m MachineRepositoryDB) RevokeTokens(ctx context.Context, userID int) error {
+	result := m.db.WithContext(ctx).Model(&resource.User{}).Where("user_id = ?", userID).
+		UpdateColumn("token_version", gorm.Expr("token_version + 1"))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return resource.ErrUserNotFound
+	}
 	return nil
 }
 
-func (m MachineRepositoryDB) GetUserIdAndRoleId(username string) (uint, uint, error) {
-	var user resource.User
-	m.db.Where("username = ?", username).First(&user)
-	return user.UserID, user.RoleID, nil
+// DepositMoney adds to the deposit in SQL, so that concurrent writes to
+// the user are not lost.
+func (m MachineRepositoryDB) DepositMoney(ctx context.Context, userid, amount int) error {
+	result := m.db.WithContext(ctx).Model(&resource.User{}).Where("user_id = ?", userid).
+		UpdateColumn("deposit", gorm.Expr("deposit + ?", amount))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return resource.ErrUserNotFound
+	}
+	return nil
 }
 
-func (m MachineRepositoryDB) DepositMoney(userid, amount int) error {
+// ResetDeposit sets the deposit to zero without touching other columns.
+func (m MachineRepositoryDB) ResetDeposit(ctx context.Context, userID int) error {
+	return m.db.WithContext(ctx).Model(&resource.User{}).Where("user_id = ?", userID).UpdateColumn("deposit", 0).Error
+}
+
+// GetUserForUpdate locks the user row until the surrounding transaction
+// ends, as BuyProduct does.
+func (m MachineRepositoryDB) GetUserForUpdate(ctx context.Context, id int) (resource.User, error) {
 	var user resource.User
-	m.db.Where("user_id = ?", userid).First(&user)
-	user.Deposit += amount
-	m.db.Save(&user)
-	return nil
+	if err := m.db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Where("user_id = ?", id).First(&user).Error; err != nil {
+		return user, notFound(err, resource.ErrUserNotFound)
+	}
+	return user, nil
 }
 
-func (m MachineRepositoryDB) GetUserById(id int) (resource.User, error) {
+func (m MachineRepositoryDB) GetUserById(ctx context.Context, id int) (resource.User, error) {
 	var user resource.User
-	m.db.Where("user_id = ?", id).First(&user)
+	if err := m.db.WithContext(ctx).Where("user_id = ?", id).First(&user).Error; err != nil {
+		return user, notFound(err, resource.ErrUserNotFound)
+	}
 	return user, nil
 }
 
-func (m MachineRepositoryDB) UpdateUser(user resource.User) error {
-	m.db.Model(&user).Save(&user)
-	return nil
+func (m MachineRepositoryDB) GetTotalDeposit(ctx context.Context) (int, error) {
+	var total int
+	err := m.db.WithContext(ctx).Model(&resource.User{}).Select("COALESCE(SUM(deposit), 0)").Scan(&total).Error
+	return total, err
+}
+
+// UpdateUser stores the username and role. Deposits, passwords and the
+// login state have their own writes, so that a stale copy of the user
+// cannot revert them. A changed role raises the token version. A username
+// taken in the meantime yields ErrUserExists.
+func (m MachineRepositoryDB) UpdateUser(ctx context.Context, user resource.User) error {
+	db := m.db.WithContext(ctx)
+	err := db.Model(&resource.User{}).Where("user_id = ? AND role_id <> ?", user.UserID, user.RoleID).
+		UpdateColumn("token_version", gorm.Expr("token_version + 1")).Error
+	if err != nil {
+		return err
+	}
+	err = db.Model(&resource.User{}).Where("user_id = ?", user.UserID).
+		Updates(map[string]interface{}{"username": user.Username, "role_id": user.RoleID}).Error
+	return duplicate(err, resource.ErrUserExists)
+}
+
+// BuyProduct charges the buyer and takes the stock in a single transaction,
+// locking both rows so that concurrent purchases cannot oversell or overdraw.
+// It fills in the order's ID and total price and returns the buyer's
+// remaining deposit.
+func (m MachineRepositoryDB) BuyProduct(ctx context.Context, order *resource.Order) (int, error) {
+	var remaining int
+	err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var user resource.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("user_id = ?", order.BuyerID).First(&user).Error; err != nil {
+			return notFound(err, resource.ErrUserNotFound)
+		}
+		var product resource.Product
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("product_id = ?", order.ProductID).First(&product).Error; err != nil {
+			return notFound(err, resource.ErrProductNotFound)
+		}
+
+		if product.AmountAvailable < order.Quantity {
+			return resource.ErrInsufficientStock
+		}
+		order.TotalPrice = product.Cost * order.Quantity
+		if user.Deposit < order.TotalPrice {
+			return resource.ErrInsufficientFunds
+		}
+
+		user.Deposit -= order.TotalPrice
+		product.AmountAvailable -= order.Quantity
+		if err := tx.Save(&user).Error; err != nil {
+			return err
+		}
+		if err := tx.Save(&product).Error; err != nil {
+			return err
+		}
+		remaining = user.Deposit
+		return tx.Create(order).Error
+	})
+	return remaining, err
+}
+
+func (m MachineRepositoryDB) GetOrdersByBuyer(ctx context.Context, buyerID int) ([]resource.Order, error) {
+	var orders []resource.Order
+	err := m.db.WithContext(ctx).Where("buyer_id = ?", buyerID).Order("order_id").Find(&orders).Error
+	return orders, err
+}
+
+func (m MachineRepositoryDB) GetOrderById(ctx context.Context, id int) (resource.Order, error) {
+	var order resource.Order
+	if err := m.db.WithContext(ctx).Where("order_id = ?", id).First(&order).Error; err != nil {
+		return order, notFound(err, resource.ErrOrderNotFound)
+	}
+	return order, nil
+}
+
+// notFound translates gorm's record-not-found error into the given domain
+// error and passes every other error through unchanged.
+func notFound(err error, domainErr error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return domainErr
+	}
+	return err
+}
+
+// errDuplicateEntry is MySQL's ER_DUP_ENTRY.
+const errDuplicateEntry = 1062
+
+// duplicate translates MySQL's duplicate key error into the given domain
+// error, for unique columns written by concurrent requests.
+func duplicate(err error, domainErr error) error {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) && mysqlErr.Number == errDuplicateEntry {
+		return domainErr
+	}
+	return err
 }
@@ -0,0 +1,74 @@
+package resource
+
+import (
+	"errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+var tracer = otel.Tracer("verkaufsautomat/internal/adapter/repositories/mysql/resource")
+
+const spanKey = "tracing:span"
+
+// tracingPlugin creates a span for every statement gorm runs, as a child of
+// the span in the context passed with WithContext. Statements are recorded
+// with placeholders; bound values are left out so that password hashes and
+// tokens do not end up in traces.
+type tracingPlugin struct{}
+
+func (tracingPlugin) Name() string {
+	return "tracing"
+}
+
+func (tracingPlugin) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+	for _, err := range []error{
+		callbacks.Create().Before("gorm:create").Register("tracing:before_create", startStatementSpan("create")),
+		callbacks.Create().After("gorm:create").Register("tracing:after_create", endStatementSpan),
+		callbacks.Query().Before("gorm:query").Register("tracing:before_query", startStatementSpan("query")),
+		callbacks.Query().After("gorm:query").Register("tracing:after_query", endStatementSpan),
+		callbacks.Update().Before("gorm:update").Register("tracing:before_update", startStatementSpan("update")),
+		callbacks.Update().After("gorm:update").Register("tracing:after_update", endStatementSpan),
+		callbacks.Delete().Before("gorm:delete").Register("tracing:before_delete", startStatementSpan("delete")),
+		callbacks.Delete().After("gorm:delete").Register("tracing:after_delete", endStatementSpan),
+		callbacks.Row().Before("gorm:row").Register("tracing:before_row", startStatementSpan("row")),
+		callbacks.Row().After("gorm:row").Register("tracing:after_row", endStatementSpan),
+		callbacks.Raw().Before("gorm:raw").Register("tracing:before_raw", startStatementSpan("raw")),
+		callbacks.Raw().After("gorm:raw").Register("tracing:after_raw", endStatementSpan),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func startStatementSpan(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		_, span := tracer.Start(db.Statement.Context, "gorm."+operation, trace.WithSpanKind(trace.SpanKindClient))
+		db.InstanceSet(spanKey, span)
+	}
+}
+
+func endStatementSpan(db *gorm.DB) {
+	value, ok := db.InstanceGet(spanKey)
+	if !ok {
+		return
+	}
+	span := value.(trace.Span)
+	span.SetAttributes(
+		semconv.DBSystemMySQL,
+		semconv.DBStatementKey.String(db.Statement.SQL.String()),
+		semconv.DBSQLTableKey.String(db.Statement.Table),
+		attribute.Int64("db.rows_affected", db.Statement.RowsAffected),
+	)
+	if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		span.RecordError(db.Error)
+		span.SetStatus(codes.Error, db.Error.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,72 @@
+package resource
+
+import (
+	"context"
+	"gorm.io/gorm/clause"
+	"time"
+	"verkaufsautomat/internal/core/domain/resource"
+)
+
+func (m MachineRepositoryDB) CreateWebhook(ctx context.Context, webhook *resource.Webhook) error {
+	return m.db.WithContext(ctx).Create(webhook).Error
+}
+
+func (m MachineRepositoryDB) GetWebhooksBySeller(ctx context.Context, sellerID int) ([]resource.Webhook, error) {
+	var webhooks []resource.Webhook
+	err := m.db.WithContext(ctx).Where("seller_id = ?", sellerID).Order("webhook_id").Find(&webhooks).Error
+	return webhooks, err
+}
+
+func (m MachineRepositoryDB) GetWebhookById(ctx context.Context, id int) (resource.Webhook, error) {
+	var webhook resource.Webhook
+	if err := m.db.WithContext(ctx).Where("webhook_id = ?", id).First(&webhook).Error; err != nil {
+		return webhook, notFound(err, resource.ErrWebhookNotFound)
+	}
+	return webhook, nil
+}
+
+func (m MachineRepositoryDB) DeleteWebhookByID(ctx context.Context, id int) error {
+	webhook, err := m.GetWebhookById(ctx, id)
+	if err != nil {
+		return err
+	}
+	return m.db.WithContext(ctx).Delete(&webhook).Error
+}
+
+// CreateWebhookDelivery returns ErrDeliveryExists, and leaves the table
+// unchanged, when the webhook already has the delivery.
+func (m MachineRepositoryDB) CreateWebhookDelivery(ctx context.Context, delivery *resource.WebhookDelivery) error {
+	result := m.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(delivery)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return resource.ErrDeliveryExists
+	}
+	return nil
+}
+
+func (m MachineRepositoryDB) UpdateWebhookDelivery(ctx context.Context, delivery resource.WebhookDelivery) error {
+	return m.db.WithContext(ctx).Save(&delivery).Error
+}
+
+func (m MachineRepositoryDB) GetWebhookDeliveries(ctx context.Context, webhookID int) ([]resource.WebhookDelivery, error) {
+	var deliveries []resource.WebhookDelivery
+	err := m.db.WithContext(ctx).Where("webhook_id = ?", webhookID).Order("delivery_id desc").Find(&deliveries).Error
+	return deliveries, err
+}
+
+func (m MachineRepositoryDB) GetWebhookDeliveryById(ctx context.Context, id int) (resource.WebhookDelivery, error) {
+	var delivery resource.WebhookDelivery
+	if err := m.db.WithContext(ctx).Where("delivery_id = ?", id).First(&delivery).Error; err != nil {
+		return delivery, notFound(err, resource.ErrDeliveryNotFound)
+	}
+	return delivery, nil
+}
+
+func (m MachineRepositoryDB) GetDueWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]resource.WebhookDelivery, error) {
+	var deliveries []resource.WebhookDelivery
+	err := m.db.WithContext(ctx).Where("status = ? AND next_attempt_at <= ?", resource.DeliveryPending, now).
+		Order("next_attempt_at").Limit(limit).Find(&deliveries).Error
+	return deliveries, err
+}
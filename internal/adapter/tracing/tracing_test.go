@@ -0,0 +1,45 @@
+package tracing
+
+import (
+	"context"
+	"go.opentelemetry.io/otel"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"verkaufsautomat/internal/config"
+)
+
+func TestSetup(t *testing.T) {
+	t.Run("Console spans are written to the output file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "traces.json")
+		shutdown, err := Setup(context.Background(), config.Tracing{Exporter: "console", Output: path})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ctx, parent := otel.Tracer("test").Start(context.Background(), "POST /api/v2/orders")
+		_, child := otel.Tracer("test").Start(ctx, "MachineService.BuyProduct")
+		child.End()
+		parent.End()
+
+		if err := shutdown(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, expected := range []string{`"Name":"POST /api/v2/orders"`, `"Name":"MachineService.BuyProduct"`, parent.SpanContext().TraceID().String(), `"Value":"verkaufsautomat"`} {
+			if !strings.Contains(string(data), expected) {
+				t.Errorf("Expected %s in the exported spans", expected)
+			}
+		}
+	})
+
+	t.Run("Unknown exporters are rejected", func(t *testing.T) {
+		if _, err := Setup(context.Background(), config.Tracing{Exporter: "zipkin"}); err == nil {
+			t.Error("Expected an error for an unknown exporter")
+		}
+	})
+}
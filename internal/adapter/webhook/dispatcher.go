@@ -0,0 +1,297 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+	"verkaufsautomat/internal/core/domain/resource"
+	"verkaufsautomat/internal/core/events"
+	"verkaufsautomat/internal/core/logger"
+	ports "verkaufsautomat/internal/ports/resource"
+)
+
+const (
+	SignatureHeader = "X-Verkaufsautomat-Signature"
+	EventHeader     = "X-Verkaufsautomat-Event"
+	DeliveryHeader  = "X-Verkaufsautomat-Delivery"
+)
+
+// Dispatcher turns domain events into webhook deliveries and sends them.
+// Deliveries are stored before they are sent, so pending retries survive a
+// restart and every attempt shows up in the delivery log.
+//
+// Unless AllowPrivate is set, the client only connects to public
+// addresses, checked after DNS resolution and for every redirect.
+type Dispatcher struct {
+	Repository   ports.WebhookRepository
+	Client       *http.Client
+	AllowPrivate bool
+	MaxAttempts  int
+	Backoff      time.Duration
+	MaxBackoff   time.Duration
+	PollInterval time.Duration
+}
+
+func NewDispatcher(Repository ports.WebhookRepository) *Dispatcher {
+	d := &Dispatcher{
+		Repository:   Repository,
+		MaxAttempts:  8,
+		Backoff:      5 * time.Second,
+		MaxBackoff:   time.Hour,
+		PollInterval: time.Second,
+	}
+	dialer := &net.Dialer{Timeout: 5 * time.Second, KeepAlive: 30 * time.Second, Control: d.checkAddress}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	d.Client = &http.Client{Timeout: 10 * time.Second, Transport: transport}
+	return d
+}
+
+// checkAddress refuses connections to addresses that are not public. It
+// runs as the dialer's Control function, so it sees the resolved address.
+func (d *Dispatcher) checkAddress(network, address string, _ syscall.RawConn) error {
+	if d.AllowPrivate {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !resource.PublicIP(ip) {
+		return fmt.Errorf("webhook address %s is not public", host)
+	}
+	return nil
+}
+
+// Payload is the JSON body of every webhook request.
+type Payload struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+type orderData struct {
+	OrderID     uint   `json:"order_id"`
+	ProductID   uint   `json:"product_id"`
+	ProductName string `json:"product_name"`
+	Quantity    int    `json:"quantity"`
+	TotalPrice  int    `json:"total_price"`
+}
+
+type stockData struct {
+	ProductID       uint   `json:"product_id"`
+	ProductName     string `json:"product_name"`
+	AmountAvailable int    `json:"amount_available"`
+	Threshold       int    `json:"threshold,omitempty"`
+}
+
+// Sign returns the signature header value for a request body sent at the
+// given unix timestamp: "t=<timestamp>,v1=<hex HMAC-SHA256 of timestamp.body>".
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return "t=" + strconv.FormatInt(timestamp, 10) + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Run sends due deliveries until the context is cancelled. Deliveries are
+// queued by Queue, which the outbox relay calls for every stored event.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			d.DeliverDue(ctx)
+		}
+	}
+}
+
+// Queue stores a delivery for every webhook of the product's seller that
+// subscribed to the webhook event the domain event corresponds to. The
+// event ID is sent as the payload ID, so a domain event that is queued again
+// after a failure reaches receivers with the same ID.
+func (d *Dispatcher) Queue(ctx context.Context, eventID string, event events.Event) error {
+	var product resource.Product
+	switch event := event.(type) {
+	case events.PurchaseCompleted:
+		product = event.Product
+	case events.StockChanged:
+		product = event.Product
+	default:
+		return nil
+	}
+
+	webhooks, err := d.Repository.GetWebhooksBySeller(ctx, int(product.SellerID))
+	if err != nil {
+		return err
+	}
+
+	for _, webhook := range webhooks {
+		eventType, data := webhookEvent(event, webhook)
+		if eventType == "" || !subscribed(webhook, eventType) {
+			continue
+		}
+		if err := d.queue(ctx, webhook, eventID, eventType, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// webhookEvent maps a domain event onto the webhook event type and data it
+// produces for the given webhook, if any.
+func webhookEvent(event events.Event, webhook resource.Webhook) (string, interface{}) {
+	switch event := event.(type) {
+	case events.PurchaseCompleted:
+		return resource.WebhookOrderCompleted, orderData{
+			OrderID:     event.Order.OrderID,
+			ProductID:   event.Product.ProductID,
+			ProductName: event.Product.ProductName,
+			Quantity:    event.Order.Quantity,
+			TotalPrice:  event.Order.TotalPrice,
+		}
+	case events.StockChanged:
+		product := event.Product
+		data := stockData{ProductID: product.ProductID, ProductName: product.ProductName, AmountAvailable: product.AmountAvailable}
+		if product.AmountAvailable == 0 && event.PreviousAmount > 0 {
+			return resource.WebhookProductSoldOut, data
+		}
+		threshold := webhook.LowStockThreshold
+		if product.AmountAvailable > 0 && product.AmountAvailable <= threshold && event.PreviousAmount > threshold {
+			data.Threshold = threshold
+			return resource.WebhookProductLowStock, data
+		}
+	}
+	return "", nil
+}
+
+func subscribed(webhook resource.Webhook, eventType string) bool {
+	for _, t := range strings.Split(webhook.EventTypes, ",") {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *Dispatcher) queue(ctx context.Context, webhook resource.Webhook, eventID, eventType string, data interface{}) error {
+	body, err := json.Marshal(Payload{ID: eventID, Type: eventType, CreatedAt: time.Now(), Data: data})
+	if err != nil {
+		return err
+	}
+
+	err = d.Repository.CreateWebhookDelivery(ctx, &resource.WebhookDelivery{
+		WebhookID:     webhook.WebhookID,
+		EventID:       eventID,
+		EventType:     eventType,
+		Payload:       string(body),
+		Status:        resource.DeliveryPending,
+		NextAttemptAt: time.Now(),
+	})
+	if errors.Is(err, resource.ErrDeliveryExists) {
+		// Queued by an earlier relay attempt.
+		return nil
+	}
+	return err
+}
+
+// DeliverDue sends every pending delivery whose next attempt is due.
+func (d *Dispatcher) DeliverDue(ctx context.Context) {
+	deliveries, err := d.Repository.GetDueWebhookDeliveries(ctx, time.Now(), 100)
+	if err != nil {
+		logger.Error("Error getting webhook deliveries: " + err.Error())
+		return
+	}
+
+	for _, delivery := range deliveries {
+		if ctx.Err() != nil {
+			return
+		}
+		d.deliver(ctx, delivery)
+	}
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, delivery resource.WebhookDelivery) {
+	delivery.Attempts++
+
+	webhook, err := d.Repository.GetWebhookById(ctx, int(delivery.WebhookID))
+	if err != nil {
+		delivery.Status = resource.DeliveryFailed
+		delivery.LastError = err.Error()
+	} else {
+		delivery.LastStatusCode, err = d.send(ctx, webhook, delivery)
+		d.record(&delivery, err)
+	}
+
+	if err := d.Repository.UpdateWebhookDelivery(ctx, delivery); err != nil {
+		logger.Error("Error updating webhook delivery: " + err.Error())
+	}
+}
+
+func (d *Dispatcher) send(ctx context.Context, webhook resource.Webhook, delivery resource.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, "POST", webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Verkaufsautomat-Webhooks/1.0")
+	req.Header.Set(EventHeader, delivery.EventType)
+	req.Header.Set(DeliveryHeader, delivery.EventID)
+	req.Header.Set(SignatureHeader, Sign(webhook.Secret, time.Now().Unix(), body))
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("receiver responded with %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// record updates the delivery after an attempt, scheduling a retry with
+// exponential backoff until MaxAttempts is reached.
+func (d *Dispatcher) record(delivery *resource.WebhookDelivery, err error) {
+	if err == nil {
+		now := time.Now()
+		delivery.Status = resource.DeliverySucceeded
+		delivery.LastError = ""
+		delivery.DeliveredAt = &now
+		return
+	}
+
+	delivery.LastError = err.Error()
+	if delivery.Attempts >= d.MaxAttempts {
+		delivery.Status = resource.DeliveryFailed
+		logger.Error("Webhook delivery " + strconv.Itoa(int(delivery.DeliveryID)) + " failed: " + err.Error())
+		return
+	}
+
+	backoff := d.Backoff << (delivery.Attempts - 1)
+	if backoff > d.MaxBackoff || backoff <= 0 {
+		backoff = d.MaxBackoff
+	}
+	delivery.NextAttemptAt = time.Now().Add(backoff)
+}
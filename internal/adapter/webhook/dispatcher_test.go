@@ -0,0 +1,237 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"verkaufsautomat/internal/core/domain/resource"
+	"verkaufsautomat/internal/core/events"
+)
+
+// memoryRepository keeps webhooks and deliveries in memory.
+type memoryRepository struct {
+	mu         sync.Mutex
+	webhooks   []resource.Webhook
+	deliveries []resource.WebhookDelivery
+}
+
+func (m *memoryRepository) CreateWebhook(ctx context.Context, webhook *resource.Webhook) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	webhook.WebhookID = uint(len(m.webhooks) + 1)
+	m.webhooks = append(m.webhooks, *webhook)
+	return nil
+}
+
+func (m *memoryRepository) GetWebhooksBySeller(ctx context.Context, sellerID int) ([]resource.Webhook, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var webhooks []resource.Webhook
+	for _, webhook := range m.webhooks {
+		if webhook.SellerID == uint(sellerID) {
+			webhooks = append(webhooks, webhook)
+		}
+	}
+	return webhooks, nil
+}
+
+func (m *memoryRepository) GetWebhookById(ctx context.Context, id int) (resource.Webhook, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, webhook := range m.webhooks {
+		if webhook.WebhookID == uint(id) {
+			return webhook, nil
+		}
+	}
+	return resource.Webhook{}, resource.ErrWebhookNotFound
+}
+
+func (m *memoryRepository) DeleteWebhookByID(ctx context.Context, id int) error {
+	return nil
+}
+
+func (m *memoryRepository) CreateWebhookDelivery(ctx context.Context, delivery *resource.WebhookDelivery) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, existing := range m.deliveries {
+		if existing.WebhookID == delivery.WebhookID && existing.EventID == delivery.EventID && existing.Replay == delivery.Replay {
+			return resource.ErrDeliveryExists
+		}
+	}
+	delivery.DeliveryID = uint(len(m.deliveries) + 1)
+	m.deliveries = append(m.deliveries, *delivery)
+	return nil
+}
+
+func (m *memoryRepository) UpdateWebhookDelivery(ctx context.Context, delivery resource.WebhookDelivery) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.deliveries[delivery.DeliveryID-1] = delivery
+	return nil
+}
+
+func (m *memoryRepository) GetWebhookDeliveries(ctx context.Context, webhookID int) ([]resource.WebhookDelivery, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]resource.WebhookDelivery{}, m.deliveries...), nil
+}
+
+func (m *memoryRepository) GetWebhookDeliveryById(ctx context.Context, id int) (resource.WebhookDelivery, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.deliveries[id-1], nil
+}
+
+func (m *memoryRepository) GetDueWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]resource.WebhookDelivery, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var due []resource.WebhookDelivery
+	for _, delivery := range m.deliveries {
+		if delivery.Status == resource.DeliveryPending && !delivery.NextAttemptAt.After(now) {
+			due = append(due, delivery)
+		}
+	}
+	return due, nil
+}
+
+func TestDispatcher(t *testing.T) {
+	var mu sync.Mutex
+	var received []*http.Request
+	var bodies [][]byte
+	failures := 1
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		body, _ := io.ReadAll(r.Body)
+		received = append(received, r)
+		bodies = append(bodies, body)
+		if failures > 0 {
+			failures--
+			w.WriteHeader(500)
+			return
+		}
+		w.WriteHeader(204)
+	}))
+	defer receiver.Close()
+
+	repository := &memoryRepository{}
+	repository.CreateWebhook(context.Background(), &resource.Webhook{
+		SellerID:          1,
+		URL:               receiver.URL,
+		Secret:            "shhh",
+		EventTypes:        strings.Join(resource.WebhookEventTypes, ","),
+		LowStockThreshold: 5,
+	})
+
+	dispatcher := NewDispatcher(repository)
+	dispatcher.Backoff = time.Millisecond
+	dispatcher.AllowPrivate = true
+
+	product := resource.Product{ProductID: 7, ProductName: "cocacola", SellerID: 1, AmountAvailable: 3}
+
+	t.Run("Signed delivery with retry", func(t *testing.T) {
+		err := dispatcher.Queue(context.Background(), "e1", events.PurchaseCompleted{Product: product, Order: resource.Order{OrderID: 9, Quantity: 2, TotalPrice: 130}})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		dispatcher.DeliverDue(context.Background())
+		time.Sleep(5 * time.Millisecond)
+		dispatcher.DeliverDue(context.Background())
+
+		deliveries, _ := repository.GetWebhookDeliveries(context.Background(), 1)
+		if len(deliveries) != 1 || deliveries[0].Status != resource.DeliverySucceeded || deliveries[0].Attempts != 2 {
+			t.Fatalf("Expected one delivery that succeeded on the second attempt, got %+v", deliveries)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		request, body := received[1], bodies[1]
+		if request.Header.Get(EventHeader) != resource.WebhookOrderCompleted {
+			t.Errorf("Unexpected event header %q", request.Header.Get(EventHeader))
+		}
+
+		signature := request.Header.Get(SignatureHeader)
+		timestamp, err := strconv.ParseInt(strings.TrimPrefix(strings.Split(signature, ",")[0], "t="), 10, 64)
+		if err != nil || Sign("shhh", timestamp, body) != signature {
+			t.Errorf("Signature %q does not match the body", signature)
+		}
+
+		var payload struct {
+			ID   string `json:"id"`
+			Type string `json:"type"`
+			Data struct {
+				OrderID    int `json:"order_id"`
+				TotalPrice int `json:"total_price"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(body, &payload); err != nil || payload.ID != "e1" || payload.Data.OrderID != 9 || payload.Data.TotalPrice != 130 {
+			t.Errorf("Unexpected payload %s", body)
+		}
+	})
+
+	t.Run("Queued again after a failed relay attempt", func(t *testing.T) {
+		err := dispatcher.Queue(context.Background(), "e1", events.PurchaseCompleted{Product: product, Order: resource.Order{OrderID: 9, Quantity: 2, TotalPrice: 130}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if deliveries, _ := repository.GetWebhookDeliveries(context.Background(), 1); len(deliveries) != 1 {
+			t.Errorf("Expected the event to be delivered once, got %d deliveries", len(deliveries))
+		}
+	})
+
+	t.Run("Stock thresholds", func(t *testing.T) {
+		for _, c := range []struct {
+			previous, current int
+			expected          string
+		}{
+			{previous: 6, current: 3, expected: resource.WebhookProductLowStock},
+			{previous: 4, current: 3, expected: ""},
+			{previous: 1, current: 0, expected: resource.WebhookProductSoldOut},
+		} {
+			stocked := product
+			stocked.AmountAvailable = c.current
+			event := events.StockChanged{Product: stocked, PreviousAmount: c.previous}
+			webhook, _ := repository.GetWebhookById(context.Background(), 1)
+			if eventType, _ := webhookEvent(event, webhook); eventType != c.expected {
+				t.Errorf("Stock %d -> %d: expected %q, got %q", c.previous, c.current, c.expected, eventType)
+			}
+		}
+	})
+
+	t.Run("Private addresses are refused unless allowed", func(t *testing.T) {
+		dispatcher.AllowPrivate = false
+		defer func() { dispatcher.AllowPrivate = true }()
+		// Connections are checked when they are dialled.
+		dispatcher.Client.CloseIdleConnections()
+		mu.Lock()
+		before := len(received)
+		mu.Unlock()
+
+		webhook, _ := repository.GetWebhookById(context.Background(), 1)
+		_, err := dispatcher.send(context.Background(), webhook, resource.WebhookDelivery{Payload: "{}"})
+		if err == nil || !strings.Contains(err.Error(), "is not public") {
+			t.Errorf("Expected the loopback receiver to be refused, got %v", err)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if len(received) != before {
+			t.Error("Expected no request to reach the receiver")
+		}
+	})
+
+	t.Run("Gives up after the last attempt", func(t *testing.T) {
+		delivery := resource.WebhookDelivery{Attempts: dispatcher.MaxAttempts, Status: resource.DeliveryPending}
+		dispatcher.record(&delivery, io.ErrUnexpectedEOF)
+		if delivery.Status != resource.DeliveryFailed {
+			t.Errorf("Expected the delivery to fail, got %q", delivery.Status)
+		}
+	})
+}
@@ -0,0 +1,397 @@
+// Package config loads the server configuration. Every setting has a
+// default and can be overridden, in increasing order of precedence, by a
+// KEY=VALUE file, by an environment variable of the same name and by a
+// command line flag.
+package config
+
+import (
+	"errors"
+	"flag"
+	"github.com/joho/godotenv"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"verkaufsautomat/internal/core/logger"
+)
+
+// DefaultFile is read when neither CONFIG_FILE nor -config name a file. It
+// may be missing.
+const DefaultFile = "verkaufsautomat.env"
+
+type Config struct {
+	HTTP     HTTP
+	GRPC     GRPC
+	Database Database
+	Auth     Auth
+	Admin    Admin
+	Outbox   Outbox
+	Webhooks Webhooks
+	Metrics  Metrics
+	Log      logger.Config
+	Tracing  Tracing
+}
+
+type HTTP struct {
+	Port string
+	// ReadTimeout, ReadHeaderTimeout, WriteTimeout and IdleTimeout bound
+	// the connection, see http.Server. WriteTimeout is off by default as it
+	// would cut the event stream; RequestTimeout bounds handlers instead.
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	MaxHeaderBytes    int
+	// MaxBodyBytes is the largest request body accepted.
+	MaxBodyBytes int64
+	// ShutdownTimeout is how long in-flight requests may take to finish
+	// once the server is asked to stop.
+	ShutdownTimeout time.Duration
+	// TLSCertFile and TLSKeyFile enable HTTPS. The files are checked for
+	// changes every TLSReloadInterval, so renewed certificates are picked
+	// up without a restart.
+	TLSCertFile       string
+	TLSKeyFile        string
+	TLSReloadInterval time.Duration
+	// RequestTimeout bounds every request unless RouteTimeouts has an entry
+	// for its route. Zero means no deadline.
+	RequestTimeout time.Duration
+	RouteTimeouts  map[string]time.Duration
+	// CORSOrigins may call the API from browsers with credentials, such as
+	// the session cookie. CORSPublicOrigins may only read the public routes
+	// and may be "*". Origins are a scheme and host with an optional port,
+	// and a leading "*." in the host matches any subdomain.
+	CORSOrigins       []string
+	CORSPublicOrigins []string
+	CORSMaxAge        time.Duration
+	// CatalogueMaxAge is how long clients and shared caches may reuse the
+	// public catalogue before revalidating it.
+	CatalogueMaxAge time.Duration
+	// TrustedProxies are the IPs and CIDR ranges whose X-Forwarded-For
+	// header is used as the client IP. By default no proxy is trusted and
+	// the client IP is the peer address.
+	TrustedProxies []string
+	// The session cookies are host-only when CookieDomain is empty.
+	// CookieSameSite is lax, strict or none; none requires CookieSecure.
+	CookieDomain   string
+	CookieSecure   bool
+	CookieSameSite string
+	Login          LoginLimits
+}
+
+// LoginLimits throttle login attempts. Within every Window a client IP may
+// try IPLimit logins and a username UsernameLimit, after which they get
+// 429. Each failure for a username also delays its next attempt, starting
+// at Delay and doubling up to MaxDelay.
+type LoginLimits struct {
+	Window        time.Duration
+	IPLimit       int
+	UsernameLimit int
+	Delay         time.Duration
+	MaxDelay      time.Duration
+}
+
+type GRPC struct {
+	Port string
+}
+
+type Database struct {
+	User     string
+	Password string
+	Name     string
+	Host     string
+	Port     string
+	// ConnectTimeout is how long startup waits for the database.
+	ConnectTimeout time.Duration
+}
+
+// DSN returns the data source name for the MySQL driver.
+func (d Database) DSN() string {
+	return d.User + ":" + d.Password + "@tcp" + "(" + d.Host + ":" + d.Port + ")/" + d.Name + "?" + "charset=utf8mb4&parseTime=True&loc=Local"
+}
+
+type Auth struct {
+	JWTSecret   string
+	TokenExpiry time.Duration
+	// LockoutThreshold failed logins in a row lock an account for
+	// LockoutDuration. Zero disables lockout.
+	LockoutThreshold int
+	LockoutDuration  time.Duration
+	// Passwords need PasswordMinLength characters and may have at most
+	// PasswordMaxLength bytes. Passwords listed in BreachedPasswordsFile,
+	// one per line, are refused.
+	PasswordMinLength     int
+	PasswordMaxLength     int
+	BreachedPasswordsFile string
+	// ResetTokenExpiry is how long a password reset token can be redeemed.
+	// Tokens are sent through Notifier, file appending them to
+	// NotifierFile and log only recording that one was issued. Both are
+	// for development; without a notifier password resets are refused.
+	ResetTokenExpiry time.Duration
+	Notifier         string
+	NotifierFile     string
+	// MFARequiredRoles names the roles, buyer, seller or admin, that must
+	// log in with a second factor. MFAIssuer names the service in
+	// authenticator apps, and MFA challenge tokens are valid for
+	// MFAChallengeExpiry.
+	MFARequiredRoles   []string
+	MFAIssuer          string
+	MFAChallengeExpiry time.Duration
+}
+
+// Admin is the account created on startup, if both fields are set.
+type Admin struct {
+	Username string
+	Password string
+}
+
+type Outbox struct {
+	// Sinks are log, webhook and nats.
+	Sinks   []string
+	NATSURL string
+	// Retention is how long published events are kept. Zero keeps them.
+	Retention time.Duration
+}
+
+// Webhooks may only be sent to public addresses unless AllowPrivate is
+// set, which is meant for local development against receivers on the same
+// machine or network.
+type Webhooks struct {
+	AllowPrivate bool
+}
+
+// Metrics are served on their own listener, so that they stay off the
+// public API. An empty Addr turns them off.
+type Metrics struct {
+	Addr string
+}
+
+// Tracing selects where spans are exported to.
+type Tracing struct {
+	// Exporter is none, console or otlp. The OTLP exporter is configured
+	// with the standard OTEL_EXPORTER_OTLP_* variables.
+	Exporter string
+	// Output is the file console spans are appended to, or stdout.
+	Output string
+}
+
+// Default returns the configuration used for settings that are not set
+// anywhere else. It has no JWT secret, so it does not validate on its own.
+func Default() Config {
+	return Config{
+		HTTP: HTTP{
+			Port:              "8080",
+			ReadTimeout:       15 * time.Second,
+			ReadHeaderTimeout: 5 * time.Second,
+			IdleTimeout:       2 * time.Minute,
+			MaxHeaderBytes:    64 << 10,
+			MaxBodyBytes:      1 << 20,
+			ShutdownTimeout:   30 * time.Second,
+			TLSReloadInterval: time.Minute,
+			RequestTimeout:    10 * time.Second,
+			RouteTimeouts:     map[string]time.Duration{},
+			CORSOrigins:       []string{"http://localhost:8080"},
+			CORSPublicOrigins: []string{"*"},
+			CORSMaxAge:        12 * time.Hour,
+			CatalogueMaxAge:   time.Minute,
+			CookieSameSite:    "lax",
+			Login: LoginLimits{
+				Window:        time.Minute,
+				IPLimit:       20,
+				UsernameLimit: 10,
+				Delay:         250 * time.Millisecond,
+				MaxDelay:      4 * time.Second,
+			},
+		},
+		GRPC: GRPC{Port: "9090"},
+		Database: Database{
+			Host:           "localhost",
+			Port:           "3306",
+			ConnectTimeout: 2 * time.Minute,
+		},
+		Auth: Auth{
+			TokenExpiry:        time.Hour,
+			LockoutThreshold:   5,
+			LockoutDuration:    15 * time.Minute,
+			PasswordMinLength:  8,
+			PasswordMaxLength:  72,
+			ResetTokenExpiry:   30 * time.Minute,
+			MFAIssuer:          "Verkaufsautomat",
+			MFAChallengeExpiry: 5 * time.Minute,
+		},
+		Outbox:  Outbox{Sinks: []string{"log", "webhook"}, NATSURL: "nats://localhost:4222", Retention: 7 * 24 * time.Hour},
+		Metrics: Metrics{Addr: "127.0.0.1:9100"},
+		Log:     logger.DefaultConfig(),
+		Tracing: Tracing{Exporter: "none", Output: "stdout"},
+	}
+}
+
+// Load builds the configuration from the defaults, the config file, the
+// environment and args, the command line without the program name, and
+// validates it.
+func Load(args []string) (Config, error) {
+	config := Default()
+	settings := config.settings()
+
+	flags := flag.NewFlagSet("verkaufsautomat", flag.ContinueOnError)
+	file := flags.String("config", "", "KEY=VALUE file to read settings from (CONFIG_FILE)")
+	for _, s := range settings {
+		flags.Var(&flagValue{s}, s.flag, s.usage+" ("+s.env+")")
+	}
+	if err := flags.Parse(args); err != nil {
+		return Config{}, err
+	}
+
+	path, required := *file, true
+	if path == "" {
+		path = os.Getenv("CONFIG_FILE")
+	}
+	if path == "" {
+		path, required = DefaultFile, false
+	}
+	values, err := godotenv.Read(path)
+	if err != nil && (required || !errors.Is(err, os.ErrNotExist)) {
+		return Config{}, err
+	}
+
+	set := map[string]bool{}
+	flags.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	for _, s := range settings {
+		if set[s.flag] {
+			continue
+		}
+		value, ok := os.LookupEnv(s.env)
+		if !ok {
+			value, ok = values[s.env]
+		}
+		if !ok {
+			continue
+		}
+		if err := s.set(value); err != nil {
+			return Config{}, errors.New(s.env + ": " + err.Error())
+		}
+	}
+
+	return config, config.Validate()
+}
+
+// Validate reports every setting that is missing or out of range.
+func (c Config) Validate() error {
+	var problems []string
+	check := func(ok bool, problem string) {
+		if !ok {
+			problems = append(problems, problem)
+		}
+	}
+
+	check(validPort(c.HTTP.Port), "PORT must be a port number")
+	check(validPort(c.GRPC.Port), "GRPC_PORT must be a port number")
+	check(c.HTTP.ReadTimeout >= 0, "HTTP_READ_TIMEOUT must not be negative")
+	check(c.HTTP.ReadHeaderTimeout >= 0, "HTTP_READ_HEADER_TIMEOUT must not be negative")
+	check(c.HTTP.WriteTimeout >= 0, "HTTP_WRITE_TIMEOUT must not be negative")
+	check(c.HTTP.IdleTimeout >= 0, "HTTP_IDLE_TIMEOUT must not be negative")
+	check(c.HTTP.MaxHeaderBytes > 0, "HTTP_MAX_HEADER_BYTES must be positive")
+	check(c.HTTP.MaxBodyBytes > 0, "HTTP_MAX_BODY_BYTES must be positive")
+	check(c.HTTP.ShutdownTimeout > 0, "SHUTDOWN_TIMEOUT must be positive")
+	check((c.HTTP.TLSCertFile == "") == (c.HTTP.TLSKeyFile == ""), "TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	check(c.HTTP.TLSReloadInterval > 0, "TLS_RELOAD_INTERVAL must be positive")
+	check(c.HTTP.RequestTimeout >= 0, "REQUEST_TIMEOUT must not be negative")
+	for route, timeout := range c.HTTP.RouteTimeouts {
+		check(timeout >= 0, "ROUTE_TIMEOUTS for "+route+" must not be negative")
+	}
+	check(c.HTTP.Login.Window > 0, "LOGIN_RATE_WINDOW must be positive")
+	check(c.HTTP.Login.IPLimit > 0, "LOGIN_IP_LIMIT must be positive")
+	check(c.HTTP.Login.UsernameLimit > 0, "LOGIN_USERNAME_LIMIT must be positive")
+	check(c.HTTP.Login.Delay >= 0 && c.HTTP.Login.MaxDelay >= c.HTTP.Login.Delay, "LOGIN_DELAY must not be negative or above LOGIN_MAX_DELAY")
+	for _, origin := range c.HTTP.CORSOrigins {
+		check(validOrigin(origin), "CORS_ORIGINS has invalid origin "+origin)
+	}
+	for _, origin := range c.HTTP.CORSPublicOrigins {
+		check(origin == "*" || validOrigin(origin), "CORS_PUBLIC_ORIGINS has invalid origin "+origin)
+	}
+	check(c.HTTP.CORSMaxAge >= 0, "CORS_MAX_AGE must not be negative")
+	for _, proxy := range c.HTTP.TrustedProxies {
+		check(validProxy(proxy), "TRUSTED_PROXIES has invalid IP or CIDR range "+proxy)
+	}
+	check(c.HTTP.CatalogueMaxAge >= 0, "CATALOGUE_MAX_AGE must not be negative")
+	check(oneOf(c.HTTP.CookieSameSite, "lax", "strict", "none"), "COOKIE_SAMESITE must be lax, strict or none")
+	check(c.HTTP.CookieSameSite != "none" || c.HTTP.CookieSecure, "COOKIE_SAMESITE none requires COOKIE_SECURE")
+
+	check(c.Database.User != "", "MYSQL_USER is required")
+	check(c.Database.Name != "", "MYSQL_DATABASE is required")
+	check(c.Database.Host != "", "MYSQL_DB_HOST is required")
+	check(validPort(c.Database.Port), "MYSQL_DB_PORT must be a port number")
+	check(c.Database.ConnectTimeout > 0, "DB_CONNECT_TIMEOUT must be positive")
+
+	check(len(c.Auth.JWTSecret) >= 16, "JWT_SECRET must be at least 16 characters")
+	check(c.Auth.TokenExpiry > 0, "TOKEN_EXPIRY must be positive")
+	check(c.Auth.LockoutThreshold >= 0, "LOCKOUT_THRESHOLD must not be negative")
+	check(c.Auth.LockoutThreshold == 0 || c.Auth.LockoutDuration > 0, "LOCKOUT_DURATION must be positive")
+	check(c.Auth.PasswordMinLength > 0, "PASSWORD_MIN_LENGTH must be positive")
+	check(c.Auth.PasswordMaxLength >= c.Auth.PasswordMinLength && c.Auth.PasswordMaxLength <= 72, "PASSWORD_MAX_LENGTH must be between PASSWORD_MIN_LENGTH and 72")
+	check(c.Auth.ResetTokenExpiry > 0, "RESET_TOKEN_EXPIRY must be positive")
+	check(oneOf(c.Auth.Notifier, "", "log", "file"), "NOTIFIER must be empty, log or file")
+	check(c.Auth.Notifier != "file" || c.Auth.NotifierFile != "", "NOTIFIER_FILE is required for the file notifier")
+	for _, role := range c.Auth.MFARequiredRoles {
+		check(oneOf(role, "buyer", "seller", "admin"), "MFA_REQUIRED_ROLES has unknown role "+role)
+	}
+	check(c.Auth.MFAIssuer != "" && !strings.Contains(c.Auth.MFAIssuer, ":"), "MFA_ISSUER must be set and must not contain a colon")
+	check(c.Auth.MFAChallengeExpiry > 0, "MFA_CHALLENGE_EXPIRY must be positive")
+	check((c.Admin.Username == "") == (c.Admin.Password == ""), "ADMIN_USERNAME and ADMIN_PASSWORD must be set together")
+
+	for _, sink := range c.Outbox.Sinks {
+		check(oneOf(sink, "log", "webhook", "nats"), "OUTBOX_SINKS has unknown sink "+sink)
+	}
+	check(c.Outbox.Retention >= 0, "OUTBOX_RETENTION must not be negative")
+	check(c.Metrics.Addr == "" || validAddr(c.Metrics.Addr), "METRICS_ADDR must be a host and port, such as :9100")
+	check(oneOf(c.Log.Level, "debug", "info", "warn", "error"), "LOG_LEVEL must be debug, info, warn or error")
+	check(oneOf(c.Log.Format, "text", "json"), "LOG_FORMAT must be text or json")
+	check(oneOf(c.Tracing.Exporter, "none", "console", "otlp"), "OTEL_TRACES_EXPORTER must be none, console or otlp")
+
+	if len(problems) > 0 {
+		return errors.New("invalid configuration: " + strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func validPort(port string) bool {
+	number, err := strconv.Atoi(port)
+	return err == nil && number > 0 && number <= 65535
+}
+
+// validAddr reports whether addr is a host, which may be empty, and a port.
+func validAddr(addr string) bool {
+	_, port, err := net.SplitHostPort(addr)
+	return err == nil && validPort(port)
+}
+
+// validOrigin reports whether origin is a scheme and host with an optional
+// port, where the host may start with "*." to match subdomains.
+func validOrigin(origin string) bool {
+	u, err := url.Parse(origin)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.User != nil || u.Path != "" || u.RawQuery != "" || u.Fragment != "" {
+		return false
+	}
+	host := strings.TrimPrefix(u.Hostname(), "*.")
+	return host != "" && !strings.Contains(host, "*")
+}
+
+// validProxy reports whether proxy is an IP address or a CIDR range.
+func validProxy(proxy string) bool {
+	if strings.Contains(proxy, "/") {
+		_, _, err := net.ParseCIDR(proxy)
+		return err == nil
+	}
+	return net.ParseIP(proxy) != nil
+}
+
+func oneOf(value string, allowed ...string) bool {
+	for _, a := range allowed {
+		if value == a {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,122 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRouteTimeouts(t *testing.T) {
+	timeouts, err := ParseRouteTimeouts("POST /api/v2/orders=2s, GET  /api/v2/products=500ms")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if timeouts["POST /api/v2/orders"] != 2*time.Second || timeouts["GET /api/v2/products"] != 500*time.Millisecond {
+		t.Errorf("Unexpected timeouts %v", timeouts)
+	}
+
+	for _, spec := range []string{"/api/v2/orders=2s", "POST /api/v2/orders", "POST /api/v2/orders=soon"} {
+		if _, err := ParseRouteTimeouts(spec); err == nil {
+			t.Errorf("Expected an error for %q", spec)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "verkaufsautomat.env")
+	contents := "MYSQL_USER=vending\nMYSQL_DATABASE=vending\nMYSQL_DB_HOST=db\nJWT_SECRET=a secret from the file\nPORT=8000\nTOKEN_EXPIRY=30m\n"
+	if err := os.WriteFile(file, []byte(contents), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("Flags override the environment, which overrides the file", func(t *testing.T) {
+		t.Setenv("PORT", "8001")
+		t.Setenv("GRPC_PORT", "9001")
+		t.Setenv("OUTBOX_SINKS", "log, nats")
+
+		config, err := Load([]string{"-config", file, "-port", "8002", "-route-timeouts", "POST /api/v2/orders=2s"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if config.HTTP.Port != "8002" || config.GRPC.Port != "9001" || config.Database.Host != "db" {
+			t.Errorf("Unexpected ports or host %+v %+v %+v", config.HTTP, config.GRPC, config.Database)
+		}
+		if config.Auth.JWTSecret != "a secret from the file" || config.Auth.TokenExpiry != 30*time.Minute {
+			t.Errorf("Unexpected auth %+v", config.Auth)
+		}
+		if len(config.Outbox.Sinks) != 2 || config.Outbox.Sinks[1] != "nats" {
+			t.Errorf("Unexpected sinks %v", config.Outbox.Sinks)
+		}
+		if config.HTTP.RouteTimeouts["POST /api/v2/orders"] != 2*time.Second {
+			t.Errorf("Unexpected route timeouts %v", config.HTTP.RouteTimeouts)
+		}
+		if config.Database.Port != "3306" || config.HTTP.RequestTimeout != 10*time.Second {
+			t.Error("Expected defaults for settings that are not set")
+		}
+	})
+
+	t.Run("The file is read from CONFIG_FILE", func(t *testing.T) {
+		t.Setenv("CONFIG_FILE", file)
+
+		config, err := Load(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if config.HTTP.Port != "8000" {
+			t.Errorf("Expected port 8000, got %s", config.HTTP.Port)
+		}
+	})
+
+	t.Run("A named file must exist", func(t *testing.T) {
+		if _, err := Load([]string{"-config", filepath.Join(t.TempDir(), "missing.env")}); err == nil {
+			t.Error("Expected an error for a missing file")
+		}
+	})
+
+	t.Run("Malformed values are rejected", func(t *testing.T) {
+		t.Setenv("TOKEN_EXPIRY", "an hour")
+
+		if _, err := Load([]string{"-config", file}); err == nil || !strings.Contains(err.Error(), "TOKEN_EXPIRY") {
+			t.Errorf("Expected an error naming TOKEN_EXPIRY, got %v", err)
+		}
+	})
+}
+
+func TestConfig_Validate(t *testing.T) {
+	valid := Default()
+	valid.Database.User = "vending"
+	valid.Database.Name = "vending"
+	valid.Auth.JWTSecret = "a secret of sufficient length"
+	if err := valid.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	invalid := valid
+	invalid.HTTP.Port = "80a"
+	invalid.Auth.JWTSecret = "short"
+	invalid.Admin.Username = "root"
+	invalid.Outbox.Sinks = []string{"kafka"}
+	invalid.Auth.PasswordMaxLength = 100
+	invalid.Auth.Notifier = "file"
+	invalid.HTTP.CookieSameSite = "none"
+	invalid.HTTP.CatalogueMaxAge = -time.Minute
+	invalid.Auth.MFARequiredRoles = []string{"seller", "root"}
+	invalid.HTTP.CORSOrigins = []string{"*", "https://*.example.com", "https://shop.example.com/"}
+	invalid.HTTP.TrustedProxies = []string{"10.0.0.0/8", "proxy.local"}
+	invalid.Metrics.Addr = "9100"
+	invalid.Outbox.Retention = -time.Hour
+	err := invalid.Validate()
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+	for _, setting := range []string{"PORT", "JWT_SECRET", "PASSWORD_MAX_LENGTH", "NOTIFIER_FILE", "ADMIN_PASSWORD", "COOKIE_SAMESITE", "CATALOGUE_MAX_AGE", "MFA_REQUIRED_ROLES has unknown role root", "CORS_ORIGINS has invalid origin *;", "invalid origin https://shop.example.com/", "TRUSTED_PROXIES has invalid IP or CIDR range proxy.local", "METRICS_ADDR", "OUTBOX_RETENTION", "kafka"} {
+		if !strings.Contains(err.Error(), setting) {
+			t.Errorf("Expected %s in %q", setting, err.Error())
+		}
+	}
+	if strings.Contains(err.Error(), "*.example.com") {
+		t.Errorf("Expected subdomain patterns to be valid, got %q", err.Error())
+	}
+}
@@ -0,0 +1,221 @@
+package config
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// setting binds a configuration field to its environment variable and flag.
+type setting struct {
+	env   string
+	flag  string
+	usage string
+	set   func(string) error
+	get   func() string
+}
+
+func (c *Config) settings() []setting {
+	return []setting{
+		stringSetting("PORT", "port", "HTTP port", &c.HTTP.Port),
+		durationSetting("HTTP_READ_TIMEOUT", "http-read-timeout", "time to read a request including its body", &c.HTTP.ReadTimeout),
+		durationSetting("HTTP_READ_HEADER_TIMEOUT", "http-read-header-timeout", "time to read request headers", &c.HTTP.ReadHeaderTimeout),
+		durationSetting("HTTP_WRITE_TIMEOUT", "http-write-timeout", "time to write a response, 0 for none", &c.HTTP.WriteTimeout),
+		durationSetting("HTTP_IDLE_TIMEOUT", "http-idle-timeout", "time an idle keep-alive connection is kept open", &c.HTTP.IdleTimeout),
+		intSetting("HTTP_MAX_HEADER_BYTES", "http-max-header-bytes", "largest request header accepted", &c.HTTP.MaxHeaderBytes),
+		int64Setting("HTTP_MAX_BODY_BYTES", "http-max-body-bytes", "largest request body accepted", &c.HTTP.MaxBodyBytes),
+		durationSetting("SHUTDOWN_TIMEOUT", "shutdown-timeout", "time in-flight requests get to finish on shutdown", &c.HTTP.ShutdownTimeout),
+		stringSetting("TLS_CERT_FILE", "tls-cert-file", "certificate to serve HTTPS with", &c.HTTP.TLSCertFile),
+		stringSetting("TLS_KEY_FILE", "tls-key-file", "private key of the certificate", &c.HTTP.TLSKeyFile),
+		durationSetting("TLS_RELOAD_INTERVAL", "tls-reload-interval", "how often the certificate files are checked for changes", &c.HTTP.TLSReloadInterval),
+		durationSetting("REQUEST_TIMEOUT", "request-timeout", "deadline for requests, 0 for none", &c.HTTP.RequestTimeout),
+		{
+			env: "ROUTE_TIMEOUTS", flag: "route-timeouts", usage: "per-route deadlines such as \"POST /api/v2/orders=2s\"",
+			set: func(value string) error {
+				timeouts, err := ParseRouteTimeouts(value)
+				if err != nil {
+					return err
+				}
+				c.HTTP.RouteTimeouts = timeouts
+				return nil
+			},
+			get: func() string {
+				var entries []string
+				for route, timeout := range c.HTTP.RouteTimeouts {
+					entries = append(entries, route+"="+timeout.String())
+				}
+				return strings.Join(entries, ",")
+			},
+		},
+		listSetting("CORS_ORIGINS", "cors-origins", "comma separated origins allowed to call the API with credentials, *.example.com style hosts match subdomains", &c.HTTP.CORSOrigins),
+		listSetting("CORS_PUBLIC_ORIGINS", "cors-public-origins", "comma separated origins allowed to read the public routes, * for any", &c.HTTP.CORSPublicOrigins),
+		durationSetting("CORS_MAX_AGE", "cors-max-age", "how long browsers may cache preflight responses", &c.HTTP.CORSMaxAge),
+		listSetting("TRUSTED_PROXIES", "trusted-proxies", "comma separated IPs and CIDR ranges of proxies whose X-Forwarded-For header is trusted", &c.HTTP.TrustedProxies),
+		durationSetting("CATALOGUE_MAX_AGE", "catalogue-max-age", "how long clients may cache the public catalogue", &c.HTTP.CatalogueMaxAge),
+		stringSetting("COOKIE_DOMAIN", "cookie-domain", "domain of the session cookies, empty for the requested host only", &c.HTTP.CookieDomain),
+		boolSetting("COOKIE_SECURE", "cookie-secure", "only send the session cookies over HTTPS", &c.HTTP.CookieSecure),
+		stringSetting("COOKIE_SAMESITE", "cookie-samesite", "SameSite mode of the session cookies: lax, strict or none", &c.HTTP.CookieSameSite),
+		durationSetting("LOGIN_RATE_WINDOW", "login-rate-window", "window the login limits apply to", &c.HTTP.Login.Window),
+		intSetting("LOGIN_IP_LIMIT", "login-ip-limit", "logins per window from one IP", &c.HTTP.Login.IPLimit),
+		intSetting("LOGIN_USERNAME_LIMIT", "login-username-limit", "logins per window for one username", &c.HTTP.Login.UsernameLimit),
+		durationSetting("LOGIN_DELAY", "login-delay", "delay after the first failed login, doubling with each further failure", &c.HTTP.Login.Delay),
+		durationSetting("LOGIN_MAX_DELAY", "login-max-delay", "longest delay before a login attempt", &c.HTTP.Login.MaxDelay),
+		stringSetting("GRPC_PORT", "grpc-port", "gRPC port", &c.GRPC.Port),
+		stringSetting("MYSQL_USER", "db-user", "database user", &c.Database.User),
+		stringSetting("MYSQL_PASSWORD", "db-password", "database password", &c.Database.Password),
+		stringSetting("MYSQL_DATABASE", "db-name", "database name", &c.Database.Name),
+		stringSetting("MYSQL_DB_HOST", "db-host", "database host", &c.Database.Host),
+		stringSetting("MYSQL_DB_PORT", "db-port", "database port", &c.Database.Port),
+		durationSetting("DB_CONNECT_TIMEOUT", "db-connect-timeout", "how long to wait for the database on startup", &c.Database.ConnectTimeout),
+		stringSetting("JWT_SECRET", "jwt-secret", "key tokens are signed with", &c.Auth.JWTSecret),
+		durationSetting("TOKEN_EXPIRY", "token-expiry", "how long tokens are valid", &c.Auth.TokenExpiry),
+		intSetting("LOCKOUT_THRESHOLD", "lockout-threshold", "failed logins in a row that lock an account, 0 to disable", &c.Auth.LockoutThreshold),
+		durationSetting("LOCKOUT_DURATION", "lockout-duration", "how long an account stays locked", &c.Auth.LockoutDuration),
+		intSetting("PASSWORD_MIN_LENGTH", "password-min-length", "fewest characters a password may have", &c.Auth.PasswordMinLength),
+		intSetting("PASSWORD_MAX_LENGTH", "password-max-length", "most bytes a password may have, at most 72", &c.Auth.PasswordMaxLength),
+		stringSetting("BREACHED_PASSWORDS_FILE", "breached-passwords-file", "file listing passwords that are refused, one per line", &c.Auth.BreachedPasswordsFile),
+		durationSetting("RESET_TOKEN_EXPIRY", "reset-token-expiry", "how long password reset tokens are valid", &c.Auth.ResetTokenExpiry),
+		stringSetting("NOTIFIER", "notifier", "how reset tokens are sent: log or file, for development only", &c.Auth.Notifier),
+		stringSetting("NOTIFIER_FILE", "notifier-file", "file the file notifier appends to", &c.Auth.NotifierFile),
+		listSetting("MFA_REQUIRED_ROLES", "mfa-required-roles", "comma separated roles that must log in with a second factor", &c.Auth.MFARequiredRoles),
+		stringSetting("MFA_ISSUER", "mfa-issuer", "service name shown in authenticator apps", &c.Auth.MFAIssuer),
+		durationSetting("MFA_CHALLENGE_EXPIRY", "mfa-challenge-expiry", "time to give the second factor after the password", &c.Auth.MFAChallengeExpiry),
+		stringSetting("ADMIN_USERNAME", "admin-username", "admin account created on startup", &c.Admin.Username),
+		stringSetting("ADMIN_PASSWORD", "admin-password", "password of the admin account", &c.Admin.Password),
+		listSetting("OUTBOX_SINKS", "outbox-sinks", "comma separated sinks: log, webhook, nats", &c.Outbox.Sinks),
+		stringSetting("NATS_URL", "nats-url", "NATS server for the nats sink", &c.Outbox.NATSURL),
+		boolSetting("WEBHOOK_ALLOW_PRIVATE", "webhook-allow-private", "allow webhooks to localhost and private networks, for local development", &c.Webhooks.AllowPrivate),
+		durationSetting("OUTBOX_RETENTION", "outbox-retention", "how long published events are kept, 0 to keep them", &c.Outbox.Retention),
+		stringSetting("METRICS_ADDR", "metrics-addr", "address to serve Prometheus metrics on, empty to turn them off", &c.Metrics.Addr),
+		stringSetting("LOG_LEVEL", "log-level", "debug, info, warn or error", &c.Log.Level),
+		stringSetting("LOG_FORMAT", "log-format", "text or json", &c.Log.Format),
+		stringSetting("LOG_OUTPUT", "log-output", "stdout, stderr or a file", &c.Log.Output),
+		stringSetting("OTEL_TRACES_EXPORTER", "traces-exporter", "none, console or otlp", &c.Tracing.Exporter),
+		stringSetting("OTEL_TRACES_OUTPUT", "traces-output", "stdout or a file for console spans", &c.Tracing.Output),
+	}
+}
+
+func stringSetting(env, flag, usage string, field *string) setting {
+	return setting{env: env, flag: flag, usage: usage,
+		set: func(value string) error {
+			*field = value
+			return nil
+		},
+		get: func() string { return *field },
+	}
+}
+
+func boolSetting(env, flag, usage string, field *bool) setting {
+	return setting{env: env, flag: flag, usage: usage,
+		set: func(value string) error {
+			parsed, err := strconv.ParseBool(value)
+			if err != nil {
+				return err
+			}
+			*field = parsed
+			return nil
+		},
+		get: func() string { return strconv.FormatBool(*field) },
+	}
+}
+
+func intSetting(env, flag, usage string, field *int) setting {
+	return setting{env: env, flag: flag, usage: usage,
+		set: func(value string) error {
+			parsed, err := strconv.Atoi(value)
+			if err != nil {
+				return err
+			}
+			*field = parsed
+			return nil
+		},
+		get: func() string { return strconv.Itoa(*field) },
+	}
+}
+
+func int64Setting(env, flag, usage string, field *int64) setting {
+	return setting{env: env, flag: flag, usage: usage,
+		set: func(value string) error {
+			parsed, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return err
+			}
+			*field = parsed
+			return nil
+		},
+		get: func() string { return strconv.FormatInt(*field, 10) },
+	}
+}
+
+func durationSetting(env, flag, usage string, field *time.Duration) setting {
+	return setting{env: env, flag: flag, usage: usage,
+		set: func(value string) error {
+			parsed, err := time.ParseDuration(value)
+			if err != nil {
+				return err
+			}
+			*field = parsed
+			return nil
+		},
+		get: func() string { return field.String() },
+	}
+}
+
+func listSetting(env, flag, usage string, field *[]string) setting {
+	return setting{env: env, flag: flag, usage: usage,
+		set: func(value string) error {
+			var list []string
+			for _, item := range strings.Split(value, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					list = append(list, item)
+				}
+			}
+			*field = list
+			return nil
+		},
+		get: func() string { return strings.Join(*field, ",") },
+	}
+}
+
+// flagValue lets a setting be used as a flag.Value.
+type flagValue struct {
+	setting setting
+}
+
+func (f *flagValue) Set(value string) error {
+	return f.setting.set(value)
+}
+
+func (f *flagValue) String() string {
+	if f.setting.get == nil {
+		return ""
+	}
+	return f.setting.get()
+}
+
+// ParseRouteTimeouts reads timeouts in the form
+// "POST /api/v2/orders=2s,GET /api/v2/products=500ms".
+func ParseRouteTimeouts(spec string) (map[string]time.Duration, error) {
+	timeouts := map[string]time.Duration{}
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		separator := strings.LastIndex(entry, "=")
+		if separator < 0 {
+			return nil, errors.New("route timeout " + entry + " is not of the form METHOD /path=duration")
+		}
+		route := strings.Join(strings.Fields(entry[:separator]), " ")
+		if len(strings.Fields(route)) != 2 {
+			return nil, errors.New("route timeout " + entry + " is not of the form METHOD /path=duration")
+		}
+		timeout, err := time.ParseDuration(strings.TrimSpace(entry[separator+1:]))
+		if err != nil {
+			return nil, err
+		}
+		timeouts[route] = timeout
+	}
+	return timeouts, nil
+}
@@ -0,0 +1,80 @@
+package resource
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+)
+
+// Allergens are the fourteen allergens that EU law requires food labels to
+// declare.
+var Allergens = []string{"celery", "crustaceans", "eggs", "fish", "gluten", "lupin", "milk", "molluscs", "mustard", "nuts", "peanuts", "sesame", "soybeans", "sulphites"}
+
+// Attributes describe what a product is. Nil fields are not known.
+type Attributes struct {
+	VolumeML  *int       `json:"volume_ml,omitempty"`
+	Calories  *int       `json:"calories,omitempty"`
+	Vegan     *bool      `json:"vegan,omitempty"`
+	Allergens StringList `json:"allergens" gorm:"type:varchar(255)"`
+}
+
+// Normalize checks the attributes and returns them with the allergens
+// lower-cased and sorted as in Allergens.
+func (a Attributes) Normalize() (Attributes, error) {
+	if a.VolumeML != nil && (*a.VolumeML <= 0 || *a.VolumeML > 10000) {
+		return a, ErrInvalidAttribute
+	}
+	if a.Calories != nil && (*a.Calories < 0 || *a.Calories > 10000) {
+		return a, ErrInvalidAttribute
+	}
+	declared := map[string]bool{}
+	for _, allergen := range a.Allergens {
+		allergen = strings.ToLower(strings.TrimSpace(allergen))
+		if !KnownAllergen(allergen) {
+			return a, ErrInvalidAttribute
+		}
+		declared[allergen] = true
+	}
+	a.Allergens = StringList{}
+	for _, allergen := range Allergens {
+		if declared[allergen] {
+			a.Allergens = append(a.Allergens, allergen)
+		}
+	}
+	return a, nil
+}
+
+func KnownAllergen(allergen string) bool {
+	for _, known := range Allergens {
+		if allergen == known {
+			return true
+		}
+	}
+	return false
+}
+
+// StringList is stored as a comma separated column. Its entries must not
+// contain commas.
+type StringList []string
+
+func (l StringList) Value() (driver.Value, error) {
+	return strings.Join(l, ","), nil
+}
+
+func (l *StringList) Scan(value interface{}) error {
+	var joined string
+	switch value := value.(type) {
+	case nil:
+	case []byte:
+		joined = string(value)
+	case string:
+		joined = value
+	default:
+		return fmt.Errorf("cannot scan %T into a string list", value)
+	}
+	*l = StringList{}
+	if joined != "" {
+		*l = strings.Split(joined, ",")
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package resource
+
+import "time"
+
+const (
+	AuditLogin                = "auth.login"
+	AuditLoginMFA             = "auth.login_mfa"
+	AuditProductCreate        = "product.create"
+	AuditProductUpdate        = "product.update"
+	AuditProductDelete        = "product.delete"
+	AuditCategoryCreate       = "category.create"
+	AuditCategoryUpdate       = "category.update"
+	AuditCategoryDelete       = "category.delete"
+	AuditDeposit              = "deposit.make"
+	AuditDepositReset         = "deposit.reset"
+	AuditPurchase             = "order.create"
+	AuditRoleChange           = "user.role_change"
+	AuditUserUnlock           = "user.unlock"
+	AuditPasswordChange       = "user.password_change"
+	AuditPasswordResetRequest = "user.password_reset_request"
+	AuditPasswordReset        = "user.password_reset"
+	AuditProfileUpdate        = "user.update"
+	AuditAccountDelete        = "user.delete"
+	AuditAPIKeyCreate         = "api_key.create"
+	AuditAPIKeyRevoke         = "api_key.revoke"
+	AuditMFAEnable            = "user.mfa_enable"
+	AuditMFADisable           = "user.mfa_disable"
+	AuditMFARecoveryCodes     = "user.mfa_recovery_codes"
+	AuditMFAReset             = "user.mfa_reset"
+)
+
+// AuditEntry records who attempted a privileged or financial action, on
+// what, and with which outcome. Entries are never updated or deleted.
+type AuditEntry struct {
+	AuditID       uint   `json:"audit_id" gorm:"primaryKey;autoIncrement"`
+	ActorID       uint   `json:"actor_id" gorm:"index"`
+	ActorUsername string `json:"actor_username"`
+	ActorRoleID   uint   `json:"actor_role_id"`
+	Action        string `json:"action" gorm:"index"`
+	TargetType    string `json:"target_type" gorm:"index:idx_audit_target"`
+	TargetID      string `json:"target_id" gorm:"index:idx_audit_target"`
+	// Before and After hold the JSON encoded state of the target around the
+	// change, where it is known.
+	Before    string    `json:"before" gorm:"type:text"`
+	After     string    `json:"after" gorm:"type:text"`
+	Succeeded bool      `json:"succeeded"`
+	Status    string    `json:"status"`
+	IP        string    `json:"ip"`
+	RequestID string    `json:"request_id"`
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+}
+
+// AuditFilter selects audit entries. Zero fields match everything.
+type AuditFilter struct {
+	ActorID    int
+	Action     string
+	TargetType string
+	TargetID   string
+	From       time.Time
+	To         time.Time
+	Limit      int
+	Offset     int
+}
@@ -0,0 +1,45 @@
+package resource
+
+import "time"
+
+// CatalogueProduct is what the public catalogue shows of a product. It
+// leaves out the seller and the exact stock, and names the categories.
+type CatalogueProduct struct {
+	ProductID   uint       `json:"product_id"`
+	ProductName string     `json:"product_name"`
+	Cost        int        `json:"cost"`
+	InStock     bool       `json:"in_stock"`
+	Categories  StringList `json:"categories"`
+	Tags        StringList `json:"tags"`
+	Attributes  Attributes `json:"attributes"`
+}
+
+// CatalogueProductOf returns the public view of a product.
+func CatalogueProductOf(product Product) CatalogueProduct {
+	attributes := product.Attributes
+	attributes.Allergens = append(StringList{}, attributes.Allergens...)
+	return CatalogueProduct{
+		ProductID:   product.ProductID,
+		ProductName: product.ProductName,
+		Cost:        product.Cost,
+		InStock:     product.AmountAvailable > 0,
+		Categories:  categoryNames(product.Categories),
+		Tags:        append(StringList{}, product.Tags...),
+		Attributes:  attributes,
+	}
+}
+
+func categoryNames(categories []Category) StringList {
+	names := StringList{}
+	for _, category := range categories {
+		names = append(names, category.Name)
+	}
+	return names
+}
+
+// Catalogue lists the products anyone may see. ModifiedAt is when a
+// product was last created, changed, sold or deleted.
+type Catalogue struct {
+	Products   []CatalogueProduct
+	ModifiedAt time.Time
+}
@@ -0,0 +1,73 @@
+package resource
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	MaxTags      = 10
+	MaxTagLength = 24
+)
+
+// Category groups products, such as drinks, snacks or healthy. A product
+// can be in several categories.
+type Category struct {
+	CategoryID uint      `json:"category_id" gorm:"primaryKey;autoIncrement"`
+	Name       string    `json:"name" gorm:"size:32;not null;unique"`
+	UpdatedAt  time.Time `json:"-" gorm:"index"`
+}
+
+// ProductFilter selects products. Zero fields match everything, Tags must
+// all be present and products that declare any of WithoutAllergens are
+// left out. Products that do not give an attribute do not match a filter
+// on it.
+type ProductFilter struct {
+	Category         string
+	Tags             []string
+	Vegan            *bool
+	MaxCalories      *int
+	MinVolumeML      *int
+	MaxVolumeML      *int
+	WithoutAllergens []string
+}
+
+var labelPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// NormalizeLabel lower-cases and trims category names and tags, which are
+// lower-case words joined by dashes, such as sugar-free.
+func NormalizeLabel(label string) (string, bool) {
+	label = strings.ToLower(strings.TrimSpace(label))
+	return label, labelPattern.MatchString(label)
+}
+
+// NormalizeCategoryName checks a category name and returns it normalised.
+func NormalizeCategoryName(name string) (string, error) {
+	name, ok := NormalizeLabel(name)
+	if !ok || len(name) > 32 {
+		return "", ErrInvalidCategory
+	}
+	return name, nil
+}
+
+// NormalizeTags checks tags and returns them normalised, without
+// duplicates.
+func NormalizeTags(tags []string) (StringList, error) {
+	normalized := StringList{}
+	seen := map[string]bool{}
+	for _, tag := range tags {
+		tag, ok := NormalizeLabel(tag)
+		if !ok || len(tag) > MaxTagLength {
+			return nil, ErrInvalidTag
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			normalized = append(normalized, tag)
+		}
+	}
+	if len(normalized) > MaxTags {
+		return nil, ErrInvalidTag
+	}
+	return normalized, nil
+}
@@ -0,0 +1,53 @@
+package resource
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	tags, err := NormalizeTags([]string{" Fizzy", "sugar-free", "fizzy"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(tags, StringList{"fizzy", "sugar-free"}) {
+		t.Errorf("Unexpected tags %v", tags)
+	}
+
+	for _, invalid := range [][]string{{""}, {"a,b"}, {"-cold"}, {"ice cold"}, {"a-tag-that-is-far-too-long"}, {"a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k"}} {
+		if _, err := NormalizeTags(invalid); !errors.Is(err, ErrInvalidTag) {
+			t.Errorf("Expected %v for %v, got %v", ErrInvalidTag, invalid, err)
+		}
+	}
+}
+
+func TestAttributes_Normalize(t *testing.T) {
+	volume, calories := 330, 0
+	attributes, err := Attributes{VolumeML: &volume, Calories: &calories, Allergens: StringList{"Milk", "gluten", "milk"}}.Normalize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(attributes.Allergens, StringList{"gluten", "milk"}) {
+		t.Errorf("Unexpected allergens %v", attributes.Allergens)
+	}
+
+	negative := -1
+	for _, invalid := range []Attributes{{VolumeML: &calories}, {Calories: &negative}, {Allergens: StringList{"chocolate"}}} {
+		if _, err := invalid.Normalize(); !errors.Is(err, ErrInvalidAttribute) {
+			t.Errorf("Expected %v for %+v, got %v", ErrInvalidAttribute, invalid, err)
+		}
+	}
+}
+
+func TestStringList_Scan(t *testing.T) {
+	var list StringList
+	for value, expected := range map[interface{}]StringList{nil: {}, "": {}, "a,b": {"a", "b"}} {
+		if err := list.Scan(value); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(list, expected) {
+			t.Errorf("Expected %v for %v, got %v", expected, value, list)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package resource
+
+// Coins lists the accepted coin values in cents, largest first.
+var Coins = []int{100, 50, 20, 10, 5}
+
+func IsValidCoin(coin int) bool {
+	for _, c := range Coins {
+		if c == coin {
+			return true
+		}
+	}
+	return false
+}
+
+// Change splits an amount into the fewest accepted coins.
+func Change(amount int) []int {
+	change := []int{}
+
+	for _, coin := range Coins {
+		for amount >= coin {
+			amount -= coin
+			change = append(change, coin)
+		}
+	}
+
+	return change
+}
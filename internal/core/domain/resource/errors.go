@@ -0,0 +1,50 @@
+package resource
+
+import "errors"
+
+var (
+	ErrUserNotFound      = errors.New("user does not exist")
+	ErrUserExists        = errors.New("user already exists")
+	ErrProductNotFound   = errors.New("product does not exist")
+	ErrOrderNotFound     = errors.New("order does not exist")
+	ErrInvalidQuantity   = errors.New("quantity must be greater than zero")
+	ErrInsufficientFunds = errors.New("user does not have enough money")
+	ErrInsufficientStock = errors.New("product quantity is not enough")
+	ErrWebhookNotFound   = errors.New("webhook does not exist")
+	ErrDeliveryNotFound  = errors.New("webhook delivery does not exist")
+	ErrDeliveryExists    = errors.New("webhook delivery is already queued")
+	ErrInvalidWebhook    = errors.New("webhook needs an http(s) url and known event types")
+	ErrInvalidRole       = errors.New("role does not exist")
+	ErrRoleNotAllowed    = errors.New("users can only register as buyer or seller")
+	// ErrInvalidCredentials is returned for unknown users, wrong passwords
+	// and locked accounts alike, so that callers cannot tell them apart.
+	ErrInvalidCredentials = errors.New("invalid username or password")
+	// ErrInvalidToken is returned for login tokens that are malformed,
+	// expired or revoked, or whose user no longer exists.
+	ErrInvalidToken       = errors.New("token is invalid or has been revoked")
+	ErrPasswordTooShort   = errors.New("password is too short")
+	ErrPasswordTooLong    = errors.New("password is too long")
+	ErrPasswordBreached   = errors.New("password appears in a list of breached passwords")
+	ErrInvalidResetToken  = errors.New("reset token is invalid or has expired")
+	ErrResetUnavailable   = errors.New("password reset is not available")
+	ErrInvalidUsername    = errors.New("username must not be empty")
+	ErrDepositOutstanding = errors.New("user still has a deposit, ask for a refund to delete the account")
+	ErrSellerHasProducts  = errors.New("seller still has products, delete them first")
+	ErrAPIKeyNotFound     = errors.New("api key does not exist")
+	ErrInvalidAPIKey      = errors.New("api key is invalid, revoked or expired")
+	ErrInvalidAPIKeyInput = errors.New("api key needs a name, known scopes and an expiry in the future")
+	// ErrMFARequired is returned by Login together with a challenge token
+	// when the user has to give a second factor.
+	ErrMFARequired         = errors.New("a second factor is required")
+	ErrInvalidMFAChallenge = errors.New("mfa challenge is invalid or has expired")
+	ErrInvalidMFACode      = errors.New("code is invalid")
+	ErrMFANotEnabled       = errors.New("two-factor authentication is not enabled")
+	ErrMFAAlreadyEnabled   = errors.New("two-factor authentication is already enabled")
+	ErrMFAEnforced         = errors.New("two-factor authentication is required for this role")
+	ErrCategoryNotFound    = errors.New("category does not exist")
+	ErrCategoryExists      = errors.New("category already exists")
+	ErrCategoryInUse       = errors.New("category still has products")
+	ErrInvalidCategory     = errors.New("categories need a name of up to 32 lower-case letters, digits and dashes and must exist")
+	ErrInvalidTag          = errors.New("tags are up to 24 lower-case letters, digits and dashes, at most 10 per product")
+	ErrInvalidAttribute    = errors.New("volume_ml must be 1 to 10000, calories 0 to 10000 and allergens known")
+)
@@ -0,0 +1,11 @@
+package resource
+
+// DependencyStatus is the outcome of probing one dependency, such as the
+// database, for readiness.
+type DependencyStatus struct {
+	Name      string  `json:"name"`
+	Healthy   bool    `json:"healthy"`
+	LatencyMS float64 `json:"latency_ms"`
+	Detail    string  `json:"detail,omitempty"`
+	Error     string  `json:"error,omitempty"`
+}
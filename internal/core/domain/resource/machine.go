@@ -1,11 +1,26 @@
 package resource
 
+import "time"
+
+const (
+	BuyerRoleID  = 1
+	SellerRoleID = 2
+	AdminRoleID  = 3
+)
+
 type Product struct {
 	ProductID       uint   `json:"product_id" gorm:"primary_key;autoIncrement"`
 	AmountAvailable int    `json:"amount_available"`
 	Cost            int    `json:"cost"`
 	ProductName     string `json:"product_name"`
 	SellerID        uint   `json:"seller_id" gorm:"foreignKey:UserID"`
+	// Categories are loaded with the product and replaced when it is
+	// saved. Tags are normalised labels, see NormalizeTags.
+	Categories []Category `json:"categories" gorm:"many2many:product_categories;joinForeignKey:ProductID;joinReferences:CategoryID"`
+	Tags       StringList `json:"tags" gorm:"type:varchar(255)"`
+	Attributes Attributes `json:"attributes" gorm:"embedded;embeddedPrefix:attr_"`
+	// UpdatedAt is set whenever the product is saved, including sales.
+	UpdatedAt time.Time `json:"updated_at" gorm:"not null;default:CURRENT_TIMESTAMP(3);index"`
 }
 
 type User struct {
@@ -14,6 +29,19 @@ type User struct {
 	Password string `json:"password" gorm:"not null"`
 	Deposit  int    `json:"deposit"`
 	RoleID   uint   `json:"role_id" gorm:"foreignKey:RoleId"`
+	// FailedLogins counts failed logins since the last successful one or
+	// lockout. The account is locked until LockedUntil.
+	FailedLogins int        `json:"-"`
+	LockedUntil  *time.Time `json:"-"`
+	// TokenVersion is carried in login tokens. It is raised when the
+	// password or role changes and on logout, which revokes the tokens
+	// issued before.
+	TokenVersion int `json:"-" gorm:"not null;default:0"`
+}
+
+// Locked reports whether the account is locked at now.
+func (u User) Locked(now time.Time) bool {
+	return u.LockedUntil != nil && now.Before(*u.LockedUntil)
 }
 
 type Role struct {
@@ -30,3 +58,12 @@ type RolePermission struct {
 	RoleID       uint `json:"role_id" gorm:"foreignKey:RoleId"`
 	PermissionID uint `json:"permission_id" gorm:"foreignKey:PermissionId"`
 }
+
+type Order struct {
+	OrderID    uint      `json:"order_id" gorm:"primaryKey;autoIncrement"`
+	BuyerID    uint      `json:"buyer_id" gorm:"index"`
+	ProductID  uint      `json:"product_id"`
+	Quantity   int       `json:"quantity"`
+	TotalPrice int       `json:"total_price"`
+	CreatedAt  time.Time `json:"created_at"`
+}
@@ -0,0 +1,37 @@
+package resource
+
+import "time"
+
+// MFA is a user's TOTP second factor. It is created when the user starts
+// enrolling and enabled once they confirm it with a first code. The secret
+// has to be kept, unlike passwords, to compute the expected codes.
+type MFA struct {
+	UserID    uint       `json:"user_id" gorm:"primaryKey;autoIncrement:false"`
+	Secret    string     `json:"-" gorm:"size:64;not null"`
+	EnabledAt *time.Time `json:"enabled_at"`
+	// LastStep is the time step of the last accepted code, so that a code
+	// cannot be used twice.
+	LastStep  int64     `json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Enabled reports whether logins need the second factor.
+func (m MFA) Enabled() bool {
+	return m.EnabledAt != nil
+}
+
+// RecoveryCode stands in for a TOTP code once, for users who lost their
+// authenticator. Only a hash of the code is stored.
+type RecoveryCode struct {
+	CodeID uint       `json:"code_id" gorm:"primaryKey;autoIncrement"`
+	UserID uint       `json:"user_id" gorm:"index"`
+	Hash   string     `json:"-" gorm:"size:64;not null"`
+	UsedAt *time.Time `json:"used_at"`
+}
+
+// MFAEnrollment is what authenticator apps need to set up the second
+// factor: the secret, and the otpauth:// URI to show as QR code.
+type MFAEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
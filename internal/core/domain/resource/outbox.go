@@ -0,0 +1,24 @@
+package resource
+
+import "time"
+
+// OutboxEvent is a domain event stored in the same transaction as the state
+// change it describes, waiting to be relayed to the configured sinks.
+type OutboxEvent struct {
+	OutboxEventID uint `json:"outbox_event_id" gorm:"primaryKey;autoIncrement"`
+	// EventID identifies the event across redeliveries so that consumers
+	// can discard duplicates.
+	EventID string `json:"event_id" gorm:"size:32;uniqueIndex"`
+	// EventName and CreatedAt are indexed together for looking up the
+	// latest event of a kind, such as the last product deletion.
+	EventName string `json:"event_name" gorm:"size:64;index:idx_outbox_events_name_created,priority:1"`
+	Payload   string `json:"payload" gorm:"type:text"`
+	Attempts  int    `json:"attempts"`
+	LastError string `json:"last_error"`
+	// Sinks lists the sinks that accepted the event, so a retry after one
+	// sink failed skips the others.
+	Sinks         StringList `json:"sinks" gorm:"type:varchar(255)"`
+	NextAttemptAt time.Time  `json:"next_attempt_at" gorm:"index"`
+	PublishedAt   *time.Time `json:"published_at" gorm:"index"`
+	CreatedAt     time.Time  `json:"created_at" gorm:"index:idx_outbox_events_name_created,priority:2"`
+}
@@ -0,0 +1,20 @@
+package resource
+
+import "time"
+
+// PasswordReset is a single-use, time-limited grant to set a new password
+// without knowing the current one. Only a hash of the token is stored; the
+// token itself is sent to the user and never kept.
+type PasswordReset struct {
+	ResetID   uint       `json:"reset_id" gorm:"primaryKey;autoIncrement"`
+	UserID    uint       `json:"user_id" gorm:"index"`
+	TokenHash string     `json:"-" gorm:"size:64;uniqueIndex"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// Usable reports whether the reset can still be used at now.
+func (r PasswordReset) Usable(now time.Time) bool {
+	return r.UsedAt == nil && now.Before(r.ExpiresAt)
+}
@@ -0,0 +1,12 @@
+package resource
+
+// Profile is what users see of their own account.
+type Profile struct {
+	UserID      uint     `json:"user_id"`
+	Username    string   `json:"username"`
+	RoleID      uint     `json:"role_id"`
+	Role        string   `json:"role"`
+	Deposit     int      `json:"deposit"`
+	Permissions []string `json:"permissions"`
+	MFAEnabled  bool     `json:"mfa_enabled"`
+}
@@ -0,0 +1,78 @@
+package resource
+
+import (
+	"net"
+	"time"
+)
+
+const (
+	WebhookOrderCompleted  = "order.completed"
+	WebhookProductLowStock = "product.low_stock"
+	WebhookProductSoldOut  = "product.sold_out"
+)
+
+// WebhookEventTypes lists the event types sellers can subscribe to.
+var WebhookEventTypes = []string{WebhookOrderCompleted, WebhookProductLowStock, WebhookProductSoldOut}
+
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
+
+type Webhook struct {
+	WebhookID uint   `json:"webhook_id" gorm:"primaryKey;autoIncrement"`
+	SellerID  uint   `json:"seller_id" gorm:"index"`
+	URL       string `json:"url" gorm:"not null"`
+	Secret    string `json:"secret,omitempty" gorm:"not null"`
+	// EventTypes is a comma separated list of subscribed event types.
+	EventTypes string `json:"event_types"`
+	// LowStockThreshold is the stock level at or below which
+	// product.low_stock is sent.
+	LowStockThreshold int       `json:"low_stock_threshold"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+// WebhookDelivery is one event queued for one webhook. Webhook, event and
+// replay number are unique, so an event that is queued again after a failed
+// relay attempt does not create a second delivery.
+type WebhookDelivery struct {
+	DeliveryID uint   `json:"delivery_id" gorm:"primaryKey;autoIncrement"`
+	WebhookID  uint   `json:"webhook_id" gorm:"index;uniqueIndex:idx_webhook_deliveries_event"`
+	EventID    string `json:"event_id" gorm:"size:32;uniqueIndex:idx_webhook_deliveries_event"`
+	// Replay counts the replays of the event to the webhook; the delivery
+	// queued by the dispatcher is 0.
+	Replay         int        `json:"replay" gorm:"not null;default:0;uniqueIndex:idx_webhook_deliveries_event"`
+	EventType      string     `json:"event_type"`
+	Payload        string     `json:"payload" gorm:"type:text"`
+	Status         string     `json:"status" gorm:"index"`
+	Attempts       int        `json:"attempts"`
+	LastStatusCode int        `json:"last_status_code"`
+	LastError      string     `json:"last_error"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at" gorm:"index"`
+	DeliveredAt    *time.Time `json:"delivered_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// nonPublicNetworks are ranges that net.IP has no predicate for: "this
+// network" and carrier-grade NAT.
+var nonPublicNetworks = []*net.IPNet{
+	{IP: net.IPv4(0, 0, 0, 0), Mask: net.CIDRMask(8, 32)},
+	{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)},
+}
+
+// PublicIP reports whether webhooks may be sent to ip. Loopback, private,
+// link-local, which includes cloud metadata services such as
+// 169.254.169.254, multicast and unspecified addresses are not public.
+func PublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return false
+	}
+	for _, network := range nonPublicNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,28 @@
+package resource
+
+import (
+	"net"
+	"testing"
+)
+
+func TestPublicIP(t *testing.T) {
+	for address, public := range map[string]bool{
+		"93.184.216.34":    true,
+		"2606:2800:220::1": true,
+		"127.0.0.1":        false,
+		"::1":              false,
+		"10.1.2.3":         false,
+		"172.16.0.1":       false,
+		"192.168.1.1":      false,
+		"169.254.169.254":  false,
+		"100.64.0.1":       false,
+		"0.0.0.0":          false,
+		"fd00:ec2::254":    false,
+		"fe80::1":          false,
+		"::ffff:127.0.0.1": false,
+	} {
+		if actual := PublicIP(net.ParseIP(address)); actual != public {
+			t.Errorf("Expected PublicIP(%s) to be %v", address, public)
+		}
+	}
+}
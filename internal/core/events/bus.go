@@ -0,0 +1,172 @@
+package events
+
+import (
+	"fmt"
+	"sync"
+	"verkaufsautomat/internal/core/logger"
+)
+
+type Handler func(event Event)
+
+// Bus is an in-process event publisher. Subscribers choose how they are
+// called:
+//
+//   - Subscribe runs the handler in the publishing goroutine before Publish
+//     returns, in subscription order.
+//   - SubscribeAsync runs the handler in its own goroutine, in publish order.
+//     Publish blocks when its queue is full, so no event is lost.
+//   - Stream hands events to a channel and drops them when the channel is
+//     full, for consumers such as open client connections that must never
+//     hold up the service.
+//
+// A handler that panics is logged and does not affect the publisher.
+type Bus struct {
+	mu          sync.RWMutex
+	nextID      int
+	subscribers []*subscriber
+	workers     sync.WaitGroup
+}
+
+type subscriber struct {
+	id     int
+	names  map[string]bool
+	handle Handler
+
+	// queue is set for async subscribers and streams.
+	mu     sync.Mutex
+	queue  chan Event
+	lossy  bool
+	closed bool
+}
+
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// Subscribe registers a synchronous handler for the named events, or for
+// every event when no name is given. It returns a function that removes the
+// subscription.
+func (b *Bus) Subscribe(handler Handler, names ...string) func() {
+	return b.add(&subscriber{names: nameSet(names), handle: handler})
+}
+
+// SubscribeAsync registers a handler that is called from a dedicated
+// goroutine with a queue of the given size.
+func (b *Bus) SubscribeAsync(handler Handler, buffer int, names ...string) func() {
+	s := &subscriber{names: nameSet(names), handle: handler, queue: make(chan Event, buffer)}
+
+	b.workers.Add(1)
+	go func() {
+		defer b.workers.Done()
+		for event := range s.queue {
+			call(handler, event)
+		}
+	}()
+
+	return b.add(s)
+}
+
+// Stream returns a channel receiving the named events and a function that
+// cancels the subscription and closes the channel. Events are dropped while
+// the channel is full.
+func (b *Bus) Stream(buffer int, names ...string) (<-chan Event, func()) {
+	s := &subscriber{names: nameSet(names), queue: make(chan Event, buffer), lossy: true}
+	return s.queue, b.add(s)
+}
+
+func (b *Bus) Publish(event Event) {
+	b.mu.RLock()
+	subscribers := make([]*subscriber, len(b.subscribers))
+	copy(subscribers, b.subscribers)
+	b.mu.RUnlock()
+
+	for _, s := range subscribers {
+		if len(s.names) > 0 && !s.names[event.EventName()] {
+			continue
+		}
+		if s.queue == nil {
+			call(s.handle, event)
+			continue
+		}
+		s.enqueue(event)
+	}
+}
+
+// Close removes every subscription and waits for async handlers to finish
+// the events already queued.
+func (b *Bus) Close() {
+	b.mu.Lock()
+	subscribers := b.subscribers
+	b.subscribers = nil
+	b.mu.Unlock()
+
+	for _, s := range subscribers {
+		s.close()
+	}
+	b.workers.Wait()
+}
+
+func (b *Bus) add(s *subscriber) func() {
+	b.mu.Lock()
+	b.nextID++
+	s.id = b.nextID
+	b.subscribers = append(b.subscribers, s)
+	b.mu.Unlock()
+
+	return func() {
+		b.mu.Lock()
+		for i, existing := range b.subscribers {
+			if existing.id == s.id {
+				b.subscribers = append(b.subscribers[:i:i], b.subscribers[i+1:]...)
+				break
+			}
+		}
+		b.mu.Unlock()
+		s.close()
+	}
+}
+
+func (s *subscriber) enqueue(event Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	if !s.lossy {
+		s.queue <- event
+		return
+	}
+	select {
+	case s.queue <- event:
+	default:
+	}
+}
+
+func (s *subscriber) close() {
+	if s.queue == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.closed {
+		s.closed = true
+		close(s.queue)
+	}
+}
+
+func call(handler Handler, event Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error(fmt.Sprintf("Event handler for %s panicked: %v", event.EventName(), r))
+		}
+	}()
+	handler(event)
+}
+
+func nameSet(names []string) map[string]bool {
+	set := map[string]bool{}
+	for _, name := range names {
+		set[name] = true
+	}
+	return set
+}
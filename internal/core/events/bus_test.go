@@ -0,0 +1,101 @@
+package events
+
+import (
+	"reflect"
+	"testing"
+	"time"
+	"verkaufsautomat/internal/core/domain/resource"
+)
+
+func TestBus(t *testing.T) {
+	t.Run("Synchronous handlers run in order", func(t *testing.T) {
+		bus := NewBus()
+		var calls []string
+		bus.Subscribe(func(Event) { calls = append(calls, "first") })
+		bus.Subscribe(func(Event) { calls = append(calls, "second") })
+
+		bus.Publish(DepositMade{UserID: 1, Amount: 5, Deposit: 5})
+
+		if !reflect.DeepEqual(calls, []string{"first", "second"}) {
+			t.Errorf("Unexpected calls %v", calls)
+		}
+	})
+
+	t.Run("Handlers only receive the named events", func(t *testing.T) {
+		bus := NewBus()
+		var received []string
+		bus.Subscribe(func(event Event) { received = append(received, event.EventName()) }, StockChangedName)
+
+		bus.Publish(DepositMade{UserID: 1})
+		bus.Publish(StockChanged{Product: resource.Product{ProductID: 7}})
+
+		if !reflect.DeepEqual(received, []string{StockChangedName}) {
+			t.Errorf("Unexpected events %v", received)
+		}
+	})
+
+	t.Run("Unsubscribed handlers are not called", func(t *testing.T) {
+		bus := NewBus()
+		calls := 0
+		unsubscribe := bus.Subscribe(func(Event) { calls++ })
+		unsubscribe()
+
+		bus.Publish(DepositMade{UserID: 1})
+
+		if calls != 0 {
+			t.Errorf("Expected no calls, got %d", calls)
+		}
+	})
+
+	t.Run("Async handlers receive every event", func(t *testing.T) {
+		bus := NewBus()
+		received := make(chan Event, 10)
+		bus.SubscribeAsync(func(event Event) { received <- event }, 1)
+
+		for i := 1; i <= 3; i++ {
+			bus.Publish(DepositMade{UserID: i})
+		}
+		bus.Close()
+
+		if len(received) != 3 {
+			t.Fatalf("Expected 3 events, got %d", len(received))
+		}
+		for i := 1; i <= 3; i++ {
+			if event := (<-received).(DepositMade); event.UserID != i {
+				t.Errorf("Expected user %d, got %d", i, event.UserID)
+			}
+		}
+	})
+
+	t.Run("Streams drop events when full", func(t *testing.T) {
+		bus := NewBus()
+		stream, cancel := bus.Stream(1)
+
+		bus.Publish(DepositMade{UserID: 1})
+		bus.Publish(DepositMade{UserID: 2})
+		cancel()
+
+		var received []Event
+		for event := range stream {
+			received = append(received, event)
+		}
+		if len(received) != 1 || received[0].(DepositMade).UserID != 1 {
+			t.Errorf("Expected only the first event, got %v", received)
+		}
+	})
+
+	t.Run("Panicking handlers do not affect the publisher", func(t *testing.T) {
+		bus := NewBus()
+		called := make(chan struct{}, 1)
+		bus.Subscribe(func(Event) { panic("boom") })
+		bus.Subscribe(func(Event) { called <- struct{}{} })
+
+		bus.Publish(DepositMade{UserID: 1})
+
+		select {
+		case <-called:
+		case <-time.After(time.Second):
+			t.Error("Expected the second handler to be called")
+		}
+	})
+}
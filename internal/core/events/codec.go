@@ -0,0 +1,48 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// types maps every event name to the struct carrying it, so that stored
+// events can be decoded back into their typed form.
+var types = typesOf(
+	UserRegistered{},
+	UserUpdated{},
+	RoleChanged{},
+	UserDeleted{},
+	ProductCreated{},
+	ProductUpdated{},
+	PriceChanged{},
+	StockChanged{},
+	ProductDeleted{},
+	DepositMade{},
+	DepositReset{},
+	PurchaseCompleted{},
+	WebhookCreated{},
+	WebhookDeleted{},
+	WebhookDeliveryReplayed{},
+)
+
+func typesOf(events ...Event) map[string]reflect.Type {
+	types := map[string]reflect.Type{}
+	for _, event := range events {
+		types[event.EventName()] = reflect.TypeOf(event)
+	}
+	return types
+}
+
+// Decode turns the JSON encoding of the named event back into its typed form.
+func Decode(name string, data []byte) (Event, error) {
+	t, ok := types[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown event %q", name)
+	}
+	value := reflect.New(t)
+	if err := json.Unmarshal(data, value.Interface()); err != nil {
+		return nil, err
+	}
+	return value.Elem().Interface().(Event), nil
+}
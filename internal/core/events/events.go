@@ -0,0 +1,140 @@
+package events
+
+import "verkaufsautomat/internal/core/domain/resource"
+
+// Event is a state change published by the service layer.
+type Event interface {
+	EventName() string
+}
+
+const (
+	UserRegisteredName          = "user.registered"
+	UserUpdatedName             = "user.updated"
+	RoleChangedName             = "user.role_changed"
+	UserDeletedName             = "user.deleted"
+	ProductCreatedName          = "product.created"
+	ProductUpdatedName          = "product.updated"
+	PriceChangedName            = "product.price_changed"
+	StockChangedName            = "product.stock_changed"
+	ProductDeletedName          = "product.deleted"
+	DepositMadeName             = "deposit.made"
+	DepositResetName            = "deposit.reset"
+	PurchaseCompletedName       = "purchase.completed"
+	PurchaseFailedName          = "purchase.failed"
+	WebhookCreatedName          = "webhook.created"
+	WebhookDeletedName          = "webhook.deleted"
+	WebhookDeliveryReplayedName = "webhook.delivery_replayed"
+)
+
+type UserRegistered struct {
+	UserID   uint   `json:"user_id"`
+	Username string `json:"username"`
+	RoleID   uint   `json:"role_id"`
+}
+
+// UserUpdated is published by UpdateUser and UpdateProfile. Only the fields
+// that are safe to hand to other subsystems are included. Neither changes
+// the deposit, which DepositMade, DepositReset and PurchaseCompleted report.
+type UserUpdated struct {
+	UserID uint `json:"user_id"`
+	RoleID uint `json:"role_id"`
+}
+
+type RoleChanged struct {
+	UserID         uint `json:"user_id"`
+	RoleID         uint `json:"role_id"`
+	PreviousRoleID uint `json:"previous_role_id"`
+}
+
+// UserDeleted is published when users delete their account. Refunded is
+// the deposit paid out on deletion.
+type UserDeleted struct {
+	UserID   uint `json:"user_id"`
+	RoleID   uint `json:"role_id"`
+	Refunded int  `json:"refunded"`
+}
+
+type ProductCreated struct {
+	Product resource.Product `json:"product"`
+}
+
+type ProductUpdated struct {
+	Product  resource.Product `json:"product"`
+	Previous resource.Product `json:"previous"`
+}
+
+type PriceChanged struct {
+	Product      resource.Product `json:"product"`
+	PreviousCost int              `json:"previous_cost"`
+}
+
+// StockChanged is published whenever the amount available of a product
+// changes, whether by an update or a purchase.
+type StockChanged struct {
+	Product        resource.Product `json:"product"`
+	PreviousAmount int              `json:"previous_amount"`
+}
+
+type ProductDeleted struct {
+	Product resource.Product `json:"product"`
+}
+
+type DepositMade struct {
+	UserID  int `json:"user_id"`
+	Amount  int `json:"amount"`
+	Deposit int `json:"deposit"`
+}
+
+type DepositReset struct {
+	UserID   int `json:"user_id"`
+	Refunded int `json:"refunded"`
+}
+
+type PurchaseCompleted struct {
+	Order            resource.Order   `json:"order"`
+	Product          resource.Product `json:"product"`
+	RemainingDeposit int              `json:"remaining_deposit"`
+}
+
+// PurchaseFailed is published in-process when BuyProduct rejects a purchase.
+// Nothing changed, so it is not written to the outbox.
+type PurchaseFailed struct {
+	BuyerID   int   `json:"buyer_id"`
+	ProductID int   `json:"product_id"`
+	Quantity  int   `json:"quantity"`
+	Err       error `json:"-"`
+}
+
+type WebhookCreated struct {
+	WebhookID  uint   `json:"webhook_id"`
+	SellerID   uint   `json:"seller_id"`
+	URL        string `json:"url"`
+	EventTypes string `json:"event_types"`
+}
+
+type WebhookDeleted struct {
+	WebhookID uint `json:"webhook_id"`
+	SellerID  uint `json:"seller_id"`
+}
+
+type WebhookDeliveryReplayed struct {
+	Delivery       resource.WebhookDelivery `json:"delivery"`
+	ReplayedFromID uint                     `json:"replayed_from_id"`
+}
+
+func (UserRegistered) EventName() string          { return UserRegisteredName }
+func (UserUpdated) EventName() string             { return UserUpdatedName }
+func (RoleChanged) EventName() string             { return RoleChangedName }
+func (UserDeleted) EventName() string             { return UserDeletedName }
+func (ProductCreated) EventName() string          { return ProductCreatedName }
+func (ProductUpdated) EventName() string          { return ProductUpdatedName }
+func (PriceChanged) EventName() string            { return PriceChangedName }
+func (StockChanged) EventName() string            { return StockChangedName }
+func (ProductDeleted) EventName() string          { return ProductDeletedName }
+func (DepositMade) EventName() string             { return DepositMadeName }
+func (DepositReset) EventName() string            { return DepositResetName }
+func (PurchaseCompleted) EventName() string       { return PurchaseCompletedName }
+func (PurchaseFailed) EventName() string          { return PurchaseFailedName }
+func (WebhookCreated) EventName() string          { return WebhookCreatedName }
+func (WebhookDeleted) EventName() string          { return WebhookDeletedName }
+func (WebhookDeliveryReplayed) EventName() string { return WebhookDeliveryReplayedName }
@@ -1,34 +1,182 @@
 package logger
 
 import (
-	"fmt"
+	"context"
+	"errors"
 	log "github.com/sirupsen/logrus"
+	"io"
 	"os"
-	"path"
+	"strings"
+	"sync"
 )
 
-func init() {
-	fileName := path.Join("logs", "verkaufsautomat.log")
-	// Create the log file if doesn't exist. And append to it if it already exists.
-	f, err := os.OpenFile(fileName, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
-	Formatter := new(log.TextFormatter)
+// Fields are structured values attached to log entries.
+type Fields map[string]interface{}
 
-	Formatter.TimestampFormat = "02-01-2006 15:04:05"
-	Formatter.FullTimestamp = true
-	log.SetFormatter(Formatter)
+// Config selects the level, format and destination of the log.
+type Config struct {
+	// Level is debug, info, warn or error.
+	Level string
+	// Format is text or json.
+	Format string
+	// Output is stdout, stderr or the path of a file to append to.
+	Output string
+}
+
+// DefaultConfig logs info level text to stdout.
+func DefaultConfig() Config {
+	return Config{Level: "info", Format: "text", Output: "stdout"}
+}
+
+var (
+	mu   sync.Mutex
+	file *os.File
+)
+
+// Configure applies the config to the standard logger. A file output is
+// kept open until Close is called.
+func Configure(config Config) error {
+	level, err := log.ParseLevel(config.Level)
 	if err != nil {
+		return err
+	}
+
+	var formatter log.Formatter
+	switch config.Format {
+	case "text":
+		formatter = &log.TextFormatter{FullTimestamp: true, TimestampFormat: "02-01-2006 15:04:05"}
+	case "json":
+		formatter = &log.JSONFormatter{}
+	default:
+		return errors.New("unknown log format " + config.Format)
+	}
 
-		fmt.Println(err)
-	} else {
-		log.SetOutput(f)
+	var output io.Writer
+	var opened *os.File
+	switch config.Output {
+	case "stdout":
+		output = os.Stdout
+	case "stderr":
+		output = os.Stderr
+	default:
+		opened, err = os.OpenFile(config.Output, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+		if err != nil {
+			return err
+		}
+		output = opened
 	}
 
+	mu.Lock()
+	defer mu.Unlock()
+	log.SetLevel(level)
+	log.SetFormatter(redactor{formatter: formatter})
+	log.SetOutput(output)
+	if file != nil {
+		file.Close()
+	}
+	file = opened
+	return nil
+}
+
+// Close flushes and closes the log file, if any, and sends further entries
+// to stderr.
+func Close() error {
+	mu.Lock()
+	defer mu.Unlock()
+	if file == nil {
+		return nil
+	}
+	log.SetOutput(os.Stderr)
+	err := file.Sync()
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	file = nil
+	return err
+}
+
+// Logger writes entries carrying a fixed set of fields.
+type Logger struct {
+	entry *log.Entry
+}
+
+// With returns a logger that adds the fields to every entry.
+func With(fields Fields) Logger {
+	return Logger{entry: log.WithFields(log.Fields(fields))}
+}
+
+func (l Logger) With(fields Fields) Logger {
+	return Logger{entry: l.entry.WithFields(log.Fields(fields))}
+}
+
+func (l Logger) Debug(msg string) {
+	l.entry.Debug(msg)
+}
+
+func (l Logger) Info(msg string) {
+	l.entry.Info(msg)
+}
+
+func (l Logger) Warn(msg string) {
+	l.entry.Warn(msg)
+}
+
+func (l Logger) Error(msg string) {
+	l.entry.Error(msg)
+}
+
+type fieldsKey struct{}
+
+// NewContext returns a context carrying the fields in addition to those
+// already stored in ctx. Loggers obtained with Ctx add them to every entry.
+func NewContext(ctx context.Context, fields Fields) context.Context {
+	merged := Fields{}
+	for key, value := range fieldsOf(ctx) {
+		merged[key] = value
+	}
+	for key, value := range fields {
+		merged[key] = value
+	}
+	return context.WithValue(ctx, fieldsKey{}, merged)
+}
+
+// Ctx returns a logger enriched with the fields stored in ctx, such as the
+// request and user IDs.
+func Ctx(ctx context.Context) Logger {
+	return With(fieldsOf(ctx))
+}
+
+func fieldsOf(ctx context.Context) Fields {
+	if ctx == nil {
+		return nil
+	}
+	fields, _ := ctx.Value(fieldsKey{}).(Fields)
+	return fields
+}
+
+func Debug(msg interface{}) {
+	log.Debug(msg)
 }
 
 func Info(msg interface{}) {
 	log.Info(msg)
 }
 
+func Warn(msg interface{}) {
+	log.Warn(msg)
+}
+
 func Error(msg interface{}) {
 	log.Error(msg)
 }
+
+// isSecretKey reports whether a field of that name must never be logged.
+func isSecretKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, secret := range []string{"password", "passwd", "secret", "token", "authorization", "api_key", "apikey", "cookie"} {
+		if strings.Contains(key, secret) {
+			return true
+		}
+	}
+	return false
+}
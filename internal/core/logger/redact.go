@@ -0,0 +1,48 @@
+package logger
+
+import (
+	log "github.com/sirupsen/logrus"
+	"regexp"
+)
+
+const redacted = "[REDACTED]"
+
+var secretPatterns = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	{regexp.MustCompile(`(?i)(bearer\s+)[^\s"',;]+`), "${1}" + redacted},
+	{regexp.MustCompile(`eyJ[\w-]*\.[\w-]+\.[\w-]*`), redacted},
+	{regexp.MustCompile(`(?i)("(?:password|token|secret)"\s*:\s*)"[^"]*"`), `${1}"` + redacted + `"`},
+	{regexp.MustCompile(`(?i)\b(password|token|secret)(\s*[=:]\s*)[^\s&"',;]+`), "${1}${2}" + redacted},
+}
+
+// Redact removes bearer tokens, JWTs and password or token assignments from
+// a string.
+func Redact(s string) string {
+	for _, secret := range secretPatterns {
+		s = secret.pattern.ReplaceAllString(s, secret.replacement)
+	}
+	return s
+}
+
+// redactor scrubs secrets from an entry before handing it to the formatter,
+// so that neither messages nor fields ever reach the output unredacted.
+type redactor struct {
+	formatter log.Formatter
+}
+
+func (r redactor) Format(entry *log.Entry) ([]byte, error) {
+	scrubbed := *entry
+	scrubbed.Message = Redact(entry.Message)
+	scrubbed.Data = make(log.Fields, len(entry.Data))
+	for key, value := range entry.Data {
+		if isSecretKey(key) {
+			value = redacted
+		} else if s, ok := value.(string); ok {
+			value = Redact(s)
+		}
+		scrubbed.Data[key] = value
+	}
+	return r.formatter.Format(&scrubbed)
+}
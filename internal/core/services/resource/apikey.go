@@ -0,0 +1,136 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+	"verkaufsautomat/internal/core/domain/resource"
+	"verkaufsautomat/internal/core/logger"
+)
+
+// apiKeyTouchInterval limits how often the last use of a key is written,
+// so that busy machines do not cause a write per request.
+const apiKeyTouchInterval = time.Minute
+
+// CreateAPIKey generates a key of the form vk_<prefix id>_<secret> for the
+// user and stores its hash. The key is returned in Key and cannot be
+// retrieved later.
+func (s service) CreateAPIKey(ctx context.Context, key *resource.APIKey) (err error) {
+	ctx, span := startSpan(ctx, "CreateAPIKey")
+	defer endSpan(span, &err)
+
+	key.Name = strings.TrimSpace(key.Name)
+	if key.Name == "" || !key.ExpiresAt.After(time.Now()) {
+		return resource.ErrInvalidAPIKeyInput
+	}
+	scopes := strings.Split(key.Scopes, ",")
+	for i, scope := range scopes {
+		scopes[i] = strings.TrimSpace(scope)
+		if !isAPIKeyScope(scopes[i]) {
+			return resource.ErrInvalidAPIKeyInput
+		}
+	}
+	key.Scopes = strings.Join(scopes, ",")
+	if _, err := s.MachineRepository.GetUserById(ctx, int(key.UserID)); err != nil {
+		return err
+	}
+
+	id := make([]byte, 6)
+	secret := make([]byte, 32)
+	if _, err := rand.Read(id); err != nil {
+		return err
+	}
+	if _, err := rand.Read(secret); err != nil {
+		return err
+	}
+	key.Prefix = resource.APIKeyPrefix + hex.EncodeToString(id)
+	key.Key = key.Prefix + "_" + hex.EncodeToString(secret)
+	key.Hash = hashAPIKey(key.Key)
+	key.RevokedAt = nil
+	key.LastUsedAt = nil
+	return s.MachineRepository.CreateAPIKey(ctx, key)
+}
+
+func (s service) GetAPIKeys(ctx context.Context) (keys []resource.APIKey, err error) {
+	ctx, span := startSpan(ctx, "GetAPIKeys")
+	defer endSpan(span, &err)
+
+	return s.MachineRepository.GetAPIKeys(ctx)
+}
+
+// RevokeAPIKey makes the key unusable at once. Revoked keys are kept for
+// the record.
+func (s service) RevokeAPIKey(ctx context.Context, id int) (key resource.APIKey, err error) {
+	ctx, span := startSpan(ctx, "RevokeAPIKey")
+	defer endSpan(span, &err)
+
+	key, err = s.MachineRepository.GetAPIKeyById(ctx, id)
+	if err != nil {
+		return resource.APIKey{}, err
+	}
+	if key.RevokedAt != nil {
+		return key, nil
+	}
+	now := time.Now()
+	if err := s.MachineRepository.RevokeAPIKey(ctx, id, now); err != nil {
+		return resource.APIKey{}, err
+	}
+	key.RevokedAt = &now
+	return key, nil
+}
+
+func (s service) AuthenticateAPIKey(ctx context.Context, credential string) (key resource.APIKey, user resource.User, err error) {
+	ctx, span := startSpan(ctx, "AuthenticateAPIKey")
+	defer endSpan(span, &err)
+
+	separator := strings.LastIndex(credential, "_")
+	if !resource.IsAPIKey(credential) || separator < len(resource.APIKeyPrefix) {
+		return resource.APIKey{}, resource.User{}, resource.ErrInvalidAPIKey
+	}
+	key, err = s.MachineRepository.GetAPIKeyByPrefix(ctx, credential[:separator])
+	if err != nil {
+		return resource.APIKey{}, resource.User{}, err
+	}
+	now := time.Now()
+	if subtle.ConstantTimeCompare([]byte(key.Hash), []byte(hashAPIKey(credential))) != 1 || !key.Usable(now) {
+		return resource.APIKey{}, resource.User{}, resource.ErrInvalidAPIKey
+	}
+
+	user, err = s.MachineRepository.GetUserById(ctx, int(key.UserID))
+	if errors.Is(err, resource.ErrUserNotFound) {
+		return resource.APIKey{}, resource.User{}, resource.ErrInvalidAPIKey
+	}
+	if err != nil {
+		return resource.APIKey{}, resource.User{}, err
+	}
+	user.Password = ""
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyTouchInterval {
+		if err := s.MachineRepository.TouchAPIKey(ctx, int(key.APIKeyID), now); err != nil {
+			logger.Ctx(ctx).Warn("Error recording api key use: " + err.Error())
+		}
+		key.LastUsedAt = &now
+	}
+	return key, user, nil
+}
+
+func isAPIKeyScope(scope string) bool {
+	for _, known := range resource.APIKeyScopes {
+		if scope == known {
+			return true
+		}
+	}
+	return false
+}
+
+// hashAPIKey hashes a key for storage. Keys carry 256 random bits, so a
+// fast hash is enough.
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"context"
+	"time"
+	"verkaufsautomat/internal/core/domain/resource"
+	"verkaufsautomat/internal/core/events"
+	ports "verkaufsautomat/internal/ports/resource"
+)
+
+const (
+	maxAuditEntries = 1000
+	auditTimeout    = 5 * time.Second
+)
+
+// RecordAudit stores the entry even when ctx has been cancelled or its
+// deadline has passed, since requests that timed out must be audited too.
+func (s service) RecordAudit(ctx context.Context, entry *resource.AuditEntry) (err error) {
+	ctx, cancel := context.WithTimeout(detached{ctx}, auditTimeout)
+	defer cancel()
+	ctx, span := startSpan(ctx, "RecordAudit")
+	defer endSpan(span, &err)
+
+	return s.MachineRepository.CreateAuditEntry(ctx, entry)
+}
+
+func (s service) GetAuditEntries(ctx context.Context, filter resource.AuditFilter) (entries []resource.AuditEntry, err error) {
+	ctx, span := startSpan(ctx, "GetAuditEntries")
+	defer endSpan(span, &err)
+
+	if filter.Limit <= 0 || filter.Limit > maxAuditEntries {
+		filter.Limit = maxAuditEntries
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+	return s.MachineRepository.GetAuditEntries(ctx, filter)
+}
+
+// ChangeUserRole moves a user to another role and returns the updated user.
+func (s service) ChangeUserRole(ctx context.Context, userID, roleID int) (user resource.User, err error) {
+	ctx, span := startSpan(ctx, "ChangeUserRole")
+	defer endSpan(span, &err)
+
+	if roleID != resource.BuyerRoleID && roleID != resource.SellerRoleID && roleID != resource.AdminRoleID {
+		return resource.User{}, resource.ErrInvalidRole
+	}
+
+	err = s.commit(ctx, func(repository ports.MachineRepository) ([]events.Event, error) {
+		var err error
+		user, err = repository.GetUserById(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+
+		previous := user.RoleID
+		user.RoleID = uint(roleID)
+		if err := repository.UpdateUser(ctx, user); err != nil {
+			return nil, err
+		}
+		return []events.Event{events.RoleChanged{UserID: user.UserID, RoleID: user.RoleID, PreviousRoleID: previous}}, nil
+	})
+	return user, err
+}
+
+// detached keeps the values of a context, such as the trace and the log
+// fields, but not its cancellation or deadline.
+type detached struct {
+	context.Context
+}
+
+func (detached) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detached) Done() <-chan struct{} {
+	return nil
+}
+
+func (detached) Err() error {
+	return nil
+}
@@ -0,0 +1,171 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+	"verkaufsautomat/internal/core/domain/resource"
+	"verkaufsautomat/internal/core/events"
+	"verkaufsautomat/internal/core/logger"
+	ports "verkaufsautomat/internal/ports/resource"
+)
+
+// LockoutPolicy locks an account for Duration after Threshold failed logins
+// in a row. A zero Threshold disables lockout.
+type LockoutPolicy struct {
+	Threshold int
+	Duration  time.Duration
+}
+
+var DefaultLockoutPolicy = LockoutPolicy{Threshold: 5, Duration: 15 * time.Minute}
+
+// Login checks the credentials, applies the lockout policy and only then
+// issues a token for the user. On failure the user is still returned when
+// the username exists, so that the attempt can be attributed to it; the
+// error is ErrInvalidCredentials whatever went wrong with the credentials.
+// Users who need a second factor get ErrMFARequired with a challenge token
+// for VerifyMFA instead of a login token.
+func (s service) Login(ctx context.Context, username, password string) (user resource.User, tokenString string, err error) {
+	ctx, span := startSpan(ctx, "Login")
+	defer endSpan(span, &err)
+
+	user = resource.User{Username: username, Password: password}
+	err = s.MachineRepository.Login(ctx, &user)
+	user.Password = ""
+	if user.UserID == 0 {
+		return resource.User{Username: username}, "", err
+	}
+	// Failed logins are only forgotten once the second factor is given, so
+	// that logging in again does not reset the lockout for guessed codes.
+	if err == nil && !user.Locked(time.Now()) {
+		challenge, err := s.mfaChallenge(ctx, user)
+		if err != nil {
+			return user, "", err
+		}
+		if challenge != "" {
+			return user, challenge, resource.ErrMFARequired
+		}
+	}
+	if err := s.recordLogin(ctx, user, err); err != nil {
+		return user, "", err
+	}
+
+	tokenString, err = s.Tokens.Generate(&user)
+	if err != nil {
+		return user, "", err
+	}
+	return user, tokenString, nil
+}
+
+// AuthenticateToken verifies a login token and returns its user as
+// currently stored, so that the role of a token is never stale.
+func (s service) AuthenticateToken(ctx context.Context, tokenString string) (user resource.User, err error) {
+	ctx, span := startSpan(ctx, "AuthenticateToken")
+	defer endSpan(span, &err)
+
+	claims, err := s.Tokens.Parse(tokenString)
+	if err != nil {
+		return resource.User{}, fmt.Errorf("%w: %v", resource.ErrInvalidToken, err)
+	}
+	user, err = s.MachineRepository.GetUserById(ctx, claims.UserID)
+	if errors.Is(err, resource.ErrUserNotFound) {
+		return resource.User{}, resource.ErrInvalidToken
+	}
+	if err != nil {
+		return resource.User{}, err
+	}
+	if user.TokenVersion != claims.TokenVersion {
+		return resource.User{}, resource.ErrInvalidToken
+	}
+	user.Password = ""
+	return user, nil
+}
+
+// Logout raises the user's token version, which revokes every login token
+// issued to the user so far.
+func (s service) Logout(ctx context.Context, userID int) (err error) {
+	ctx, span := startSpan(ctx, "Logout")
+	defer endSpan(span, &err)
+
+	return s.MachineRepository.RevokeTokens(ctx, userID)
+}
+
+// recordLogin applies the lockout policy to a login attempt on an existing
+// account. A locked account is refused even with the right password.
+func (s service) recordLogin(ctx context.Context, user resource.User, err error) error {
+	now := time.Now()
+	log := logger.Ctx(ctx).With(logger.Fields{"user_id": user.UserID})
+	if user.Locked(now) {
+		log.Warn("Login attempt on a locked account")
+		return resource.ErrInvalidCredentials
+	}
+
+	if err == nil {
+		if user.FailedLogins == 0 && user.LockedUntil == nil {
+			return nil
+		}
+		return s.MachineRepository.UpdateLoginState(ctx, int(user.UserID), 0, nil)
+	}
+	if !errors.Is(err, resource.ErrInvalidCredentials) || s.Lockout.Threshold <= 0 {
+		return err
+	}
+
+	// The count is read under a row lock, so that parallel guesses are
+	// all counted and cannot get past the threshold.
+	updateErr := s.commit(ctx, func(repository ports.MachineRepository) ([]events.Event, error) {
+		current, err := repository.GetUserForUpdate(ctx, int(user.UserID))
+		if err != nil {
+			return nil, err
+		}
+		if current.Locked(now) {
+			return nil, nil
+		}
+
+		failed := current.FailedLogins + 1
+		var lockedUntil *time.Time
+		if failed >= s.Lockout.Threshold {
+			until := now.Add(s.Lockout.Duration)
+			lockedUntil = &until
+			failed = 0
+			log.Warn("Account locked after " + strconv.Itoa(s.Lockout.Threshold) + " failed logins")
+		}
+		return nil, repository.UpdateLoginState(ctx, int(user.UserID), failed, lockedUntil)
+	})
+	if updateErr != nil {
+		return updateErr
+	}
+	return err
+}
+
+// reauthenticate checks the password of a logged in user before a
+// sensitive change. Wrong passwords count towards the lockout like failed
+// logins and yield ErrInvalidCredentials.
+func (s service) reauthenticate(ctx context.Context, userID int, password string) error {
+	user, err := s.MachineRepository.GetUserById(ctx, userID)
+	if err != nil {
+		return err
+	}
+	check := resource.User{Username: user.Username, Password: password}
+	err = s.MachineRepository.Login(ctx, &check)
+	check.Password = ""
+	return s.recordLogin(ctx, check, err)
+}
+
+// UnlockUser lifts a lockout and resets the failed login count.
+func (s service) UnlockUser(ctx context.Context, userID int) (user resource.User, err error) {
+	ctx, span := startSpan(ctx, "UnlockUser")
+	defer endSpan(span, &err)
+
+	user, err = s.MachineRepository.GetUserById(ctx, userID)
+	if err != nil {
+		return resource.User{}, err
+	}
+	if err := s.MachineRepository.UpdateLoginState(ctx, userID, 0, nil); err != nil {
+		return resource.User{}, err
+	}
+	user.FailedLogins = 0
+	user.LockedUntil = nil
+	return user, nil
+}
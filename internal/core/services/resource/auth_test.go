@@ -0,0 +1,191 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"testing"
+	"time"
+	"verkaufsautomat/internal/core/domain/resource"
+)
+
+func TestService_Lockout(t *testing.T) {
+	// login makes the repository accept or refuse the password of sally.
+	login := func(failedLogins int, lockedUntil *time.Time, err error) func(ctx context.Context, user *resource.User) error {
+		return func(_ context.Context, user *resource.User) error {
+			*user = resource.User{UserID: 2, Username: "sally", RoleID: resource.BuyerRoleID, FailedLogins: failedLogins, LockedUntil: lockedUntil}
+			return err
+		}
+	}
+
+	t.Run("Failed logins below the threshold are counted", func(t *testing.T) {
+		s, repository, _ := newTestService(t)
+		s.Lockout = LockoutPolicy{Threshold: 3, Duration: time.Minute}
+		repository.EXPECT().Login(gomock.Any(), gomock.Any()).DoAndReturn(login(1, nil, resource.ErrInvalidCredentials))
+		repository.EXPECT().GetUserForUpdate(gomock.Any(), 2).Return(resource.User{UserID: 2, FailedLogins: 1}, nil)
+		repository.EXPECT().UpdateLoginState(gomock.Any(), 2, 2, nil).Return(nil)
+
+		_, tokenString, err := s.Login(context.Background(), "sally", "guess")
+
+		if !errors.Is(err, resource.ErrInvalidCredentials) || tokenString != "" {
+			t.Errorf("Expected ErrInvalidCredentials without a token, got %q %v", tokenString, err)
+		}
+	})
+
+	t.Run("Reaching the threshold locks the account", func(t *testing.T) {
+		s, repository, _ := newTestService(t)
+		s.Lockout = LockoutPolicy{Threshold: 3, Duration: time.Minute}
+		repository.EXPECT().Login(gomock.Any(), gomock.Any()).DoAndReturn(login(2, nil, resource.ErrInvalidCredentials))
+		repository.EXPECT().GetUserForUpdate(gomock.Any(), 2).Return(resource.User{UserID: 2, FailedLogins: 2}, nil)
+		var locked *time.Time
+		repository.EXPECT().UpdateLoginState(gomock.Any(), 2, 0, gomock.Any()).DoAndReturn(func(_ context.Context, _ int, _ int, lockedUntil *time.Time) error {
+			locked = lockedUntil
+			return nil
+		})
+
+		before := time.Now()
+		_, _, err := s.Login(context.Background(), "sally", "guess")
+
+		if !errors.Is(err, resource.ErrInvalidCredentials) {
+			t.Errorf("Expected ErrInvalidCredentials, got %v", err)
+		}
+		if locked == nil || locked.Before(before.Add(time.Minute)) || locked.After(time.Now().Add(time.Minute)) {
+			t.Errorf("Expected the account to be locked for a minute, got %v", locked)
+		}
+	})
+
+	t.Run("Guesses counted in parallel are read under the lock", func(t *testing.T) {
+		s, repository, _ := newTestService(t)
+		s.Lockout = LockoutPolicy{Threshold: 3, Duration: time.Minute}
+		// The login read a stale count of 0, another guess raised it to 2
+		// in the meantime.
+		repository.EXPECT().Login(gomock.Any(), gomock.Any()).DoAndReturn(login(0, nil, resource.ErrInvalidCredentials))
+		repository.EXPECT().GetUserForUpdate(gomock.Any(), 2).Return(resource.User{UserID: 2, FailedLogins: 2}, nil)
+		repository.EXPECT().UpdateLoginState(gomock.Any(), 2, 0, gomock.Not(gomock.Nil())).Return(nil)
+
+		if _, _, err := s.Login(context.Background(), "sally", "guess"); !errors.Is(err, resource.ErrInvalidCredentials) {
+			t.Errorf("Expected ErrInvalidCredentials, got %v", err)
+		}
+	})
+
+	t.Run("Guesses on an account locked meanwhile are not counted", func(t *testing.T) {
+		s, repository, _ := newTestService(t)
+		until := time.Now().Add(time.Minute)
+		repository.EXPECT().Login(gomock.Any(), gomock.Any()).DoAndReturn(login(4, nil, resource.ErrInvalidCredentials))
+		repository.EXPECT().GetUserForUpdate(gomock.Any(), 2).Return(resource.User{UserID: 2, LockedUntil: &until}, nil)
+
+		if _, _, err := s.Login(context.Background(), "sally", "guess"); !errors.Is(err, resource.ErrInvalidCredentials) {
+			t.Errorf("Expected ErrInvalidCredentials, got %v", err)
+		}
+	})
+
+	t.Run("Locked accounts are refused with the right password", func(t *testing.T) {
+		s, repository, _ := newTestService(t)
+		until := time.Now().Add(time.Minute)
+		repository.EXPECT().Login(gomock.Any(), gomock.Any()).DoAndReturn(login(0, &until, nil))
+
+		_, tokenString, err := s.Login(context.Background(), "sally", "secret")
+
+		if !errors.Is(err, resource.ErrInvalidCredentials) || tokenString != "" {
+			t.Errorf("Expected ErrInvalidCredentials without a token, got %q %v", tokenString, err)
+		}
+	})
+
+	t.Run("A successful login resets the count", func(t *testing.T) {
+		s, repository, _ := newTestService(t)
+		expired := time.Now().Add(-time.Minute)
+		repository.EXPECT().Login(gomock.Any(), gomock.Any()).DoAndReturn(login(2, &expired, nil))
+		repository.EXPECT().GetMFA(gomock.Any(), 2).Return(resource.MFA{}, resource.ErrMFANotEnabled)
+		repository.EXPECT().UpdateLoginState(gomock.Any(), 2, 0, nil).Return(nil)
+
+		_, tokenString, err := s.Login(context.Background(), "sally", "secret")
+
+		if err != nil || tokenString == "" {
+			t.Errorf("Expected a token, got %q %v", tokenString, err)
+		}
+	})
+
+	t.Run("A zero threshold disables lockout", func(t *testing.T) {
+		s, repository, _ := newTestService(t)
+		s.Lockout = LockoutPolicy{}
+		repository.EXPECT().Login(gomock.Any(), gomock.Any()).DoAndReturn(login(10, nil, resource.ErrInvalidCredentials))
+
+		if _, _, err := s.Login(context.Background(), "sally", "guess"); !errors.Is(err, resource.ErrInvalidCredentials) {
+			t.Errorf("Expected ErrInvalidCredentials, got %v", err)
+		}
+	})
+}
+
+func TestService_AuthenticateToken(t *testing.T) {
+	s, repository, _ := newTestService(t)
+	user := resource.User{UserID: 2, Username: "sally", RoleID: resource.BuyerRoleID, TokenVersion: 1}
+	tokenString, err := s.Tokens.Generate(&user)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("Tokens of the current version are accepted", func(t *testing.T) {
+		repository.EXPECT().GetUserById(gomock.Any(), 2).Return(user, nil)
+
+		authenticated, err := s.AuthenticateToken(context.Background(), tokenString)
+
+		if err != nil || authenticated.UserID != 2 {
+			t.Errorf("Expected user 2, got %+v %v", authenticated, err)
+		}
+	})
+
+	t.Run("Tokens issued before a password or role change are refused", func(t *testing.T) {
+		changed := user
+		changed.TokenVersion = 2
+		repository.EXPECT().GetUserById(gomock.Any(), 2).Return(changed, nil)
+
+		if _, err := s.AuthenticateToken(context.Background(), tokenString); !errors.Is(err, resource.ErrInvalidToken) {
+			t.Errorf("Expected ErrInvalidToken, got %v", err)
+		}
+	})
+
+	t.Run("Tokens of deleted users are refused", func(t *testing.T) {
+		repository.EXPECT().GetUserById(gomock.Any(), 2).Return(resource.User{}, resource.ErrUserNotFound)
+
+		if _, err := s.AuthenticateToken(context.Background(), tokenString); !errors.Is(err, resource.ErrInvalidToken) {
+			t.Errorf("Expected ErrInvalidToken, got %v", err)
+		}
+	})
+
+	t.Run("Challenge tokens are refused", func(t *testing.T) {
+		challenge, err := s.Tokens.GenerateChallenge(&user, time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := s.AuthenticateToken(context.Background(), challenge); !errors.Is(err, resource.ErrInvalidToken) {
+			t.Errorf("Expected ErrInvalidToken, got %v", err)
+		}
+	})
+}
+
+func TestService_Logout(t *testing.T) {
+	s, repository, _ := newTestService(t)
+	user := resource.User{UserID: 2, Username: "sally", RoleID: resource.BuyerRoleID, TokenVersion: 1}
+	tokenString, err := s.Tokens.Generate(&user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repository.EXPECT().GetUserById(gomock.Any(), 2).DoAndReturn(func(context.Context, int) (resource.User, error) {
+		return user, nil
+	}).AnyTimes()
+	repository.EXPECT().RevokeTokens(gomock.Any(), 2).DoAndReturn(func(context.Context, int) error {
+		user.TokenVersion++
+		return nil
+	})
+
+	if _, err := s.AuthenticateToken(context.Background(), tokenString); err != nil {
+		t.Fatalf("Expected the token to be accepted before the logout, got %v", err)
+	}
+	if err := s.Logout(context.Background(), 2); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.AuthenticateToken(context.Background(), tokenString); !errors.Is(err, resource.ErrInvalidToken) {
+		t.Errorf("Expected ErrInvalidToken after the logout, got %v", err)
+	}
+}
@@ -0,0 +1,41 @@
+package services
+
+import (
+	"context"
+	"time"
+	"verkaufsautomat/internal/core/domain/resource"
+)
+
+// GetCatalogue reads the modification time before the products, so that a
+// change in between yields an older time and clients revalidate sooner
+// rather than keep a stale list.
+func (s service) GetCatalogue(ctx context.Context, filter resource.ProductFilter) (catalogue resource.Catalogue, err error) {
+	ctx, span := startSpan(ctx, "GetCatalogue")
+	defer endSpan(span, &err)
+
+	modifiedAt, err := s.MachineRepository.GetCatalogueModifiedAt(ctx)
+	if err != nil {
+		return resource.Catalogue{}, err
+	}
+	products, err := s.MachineRepository.SearchProducts(ctx, filter)
+	if err != nil {
+		return resource.Catalogue{}, err
+	}
+
+	catalogue = resource.Catalogue{Products: []resource.CatalogueProduct{}, ModifiedAt: modifiedAt}
+	for _, product := range products {
+		catalogue.Products = append(catalogue.Products, resource.CatalogueProductOf(product))
+	}
+	return catalogue, nil
+}
+
+func (s service) GetCatalogueProduct(ctx context.Context, id int) (product resource.CatalogueProduct, modifiedAt time.Time, err error) {
+	ctx, span := startSpan(ctx, "GetCatalogueProduct")
+	defer endSpan(span, &err)
+
+	stored, err := s.MachineRepository.GetProductById(ctx, id)
+	if err != nil {
+		return resource.CatalogueProduct{}, time.Time{}, err
+	}
+	return resource.CatalogueProductOf(stored), stored.UpdatedAt, nil
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"context"
+	"verkaufsautomat/internal/core/domain/resource"
+	"verkaufsautomat/internal/core/events"
+	ports "verkaufsautomat/internal/ports/resource"
+)
+
+func (s service) CreateCategory(ctx context.Context, category *resource.Category) (err error) {
+	ctx, span := startSpan(ctx, "CreateCategory")
+	defer endSpan(span, &err)
+
+	if category.Name, err = resource.NormalizeCategoryName(category.Name); err != nil {
+		return err
+	}
+	return s.commit(ctx, func(repository ports.MachineRepository) ([]events.Event, error) {
+		return nil, repository.CreateCategory(ctx, category)
+	})
+}
+
+func (s service) GetCategories(ctx context.Context) (categories []resource.Category, err error) {
+	ctx, span := startSpan(ctx, "GetCategories")
+	defer endSpan(span, &err)
+
+	return s.MachineRepository.GetCategories(ctx)
+}
+
+func (s service) GetCategoryById(ctx context.Context, id int) (category resource.Category, err error) {
+	ctx, span := startSpan(ctx, "GetCategoryById")
+	defer endSpan(span, &err)
+
+	return s.MachineRepository.GetCategoryById(ctx, id)
+}
+
+func (s service) UpdateCategory(ctx context.Context, category *resource.Category) (err error) {
+	ctx, span := startSpan(ctx, "UpdateCategory")
+	defer endSpan(span, &err)
+
+	if category.Name, err = resource.NormalizeCategoryName(category.Name); err != nil {
+		return err
+	}
+	return s.commit(ctx, func(repository ports.MachineRepository) ([]events.Event, error) {
+		return nil, repository.UpdateCategory(ctx, category)
+	})
+}
+
+func (s service) DeleteCategory(ctx context.Context, id int) (err error) {
+	ctx, span := startSpan(ctx, "DeleteCategory")
+	defer endSpan(span, &err)
+
+	return s.commit(ctx, func(repository ports.MachineRepository) ([]events.Event, error) {
+		return nil, repository.DeleteCategory(ctx, id)
+	})
+}
+
+// normalizeProduct checks and normalises the tags and attributes of a
+// product and replaces its categories with the stored ones, so that
+// callers only need to give their IDs.
+func normalizeProduct(ctx context.Context, repository ports.MachineRepository, product *resource.Product) error {
+	tags, err := resource.NormalizeTags(product.Tags)
+	if err != nil {
+		return err
+	}
+	product.Tags = tags
+	if product.Attributes, err = product.Attributes.Normalize(); err != nil {
+		return err
+	}
+
+	var ids []uint
+	seen := map[uint]bool{}
+	for _, category := range product.Categories {
+		if !seen[category.CategoryID] {
+			seen[category.CategoryID] = true
+			ids = append(ids, category.CategoryID)
+		}
+	}
+	categories, err := repository.GetCategoriesByIds(ctx, ids)
+	if err != nil {
+		return err
+	}
+	if len(categories) != len(ids) {
+		return resource.ErrInvalidCategory
+	}
+	product.Categories = append([]resource.Category{}, categories...)
+	return nil
+}
@@ -0,0 +1,308 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+	"verkaufsautomat/internal/core/domain/resource"
+	"verkaufsautomat/internal/core/events"
+	"verkaufsautomat/internal/core/totp"
+	ports "verkaufsautomat/internal/ports/resource"
+)
+
+// MFAPolicy configures two-factor authentication. Users whose role is in
+// Required cannot log in without a second factor and have to enrol during
+// their next login. Issuer names the service in authenticator apps, and
+// challenge tokens can be redeemed for ChallengeExpiry.
+type MFAPolicy struct {
+	Issuer          string
+	Required        map[uint]bool
+	ChallengeExpiry time.Duration
+	RecoveryCodes   int
+}
+
+var DefaultMFAPolicy = MFAPolicy{Issuer: "Verkaufsautomat", ChallengeExpiry: 5 * time.Minute, RecoveryCodes: 10}
+
+// mfaSkew accepts codes of the previous and next time step, allowing for
+// clocks that are off by up to 30 seconds.
+const mfaSkew = 1
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// mfaChallenge returns a challenge token if the user has to give a second
+// factor, because it is enabled or required for the role.
+func (s service) mfaChallenge(ctx context.Context, user resource.User) (string, error) {
+	required := s.MFA.Required[user.RoleID]
+	if !required {
+		mfa, err := s.MachineRepository.GetMFA(ctx, int(user.UserID))
+		if err != nil && !errors.Is(err, resource.ErrMFANotEnabled) {
+			return "", err
+		}
+		required = mfa.Enabled()
+	}
+	if !required {
+		return "", nil
+	}
+	return s.Tokens.GenerateChallenge(&user, s.MFA.ChallengeExpiry)
+}
+
+// VerifyMFA completes a login with a challenge token and a TOTP or
+// recovery code, and issues the login token. Users who still have to enrol
+// confirm their second factor this way and get their recovery codes. Wrong
+// codes count towards the lockout like failed logins.
+func (s service) VerifyMFA(ctx context.Context, challenge, code string) (user resource.User, tokenString string, recoveryCodes []string, err error) {
+	ctx, span := startSpan(ctx, "VerifyMFA")
+	defer endSpan(span, &err)
+
+	claims, err := s.Tokens.ParseChallenge(challenge)
+	if err != nil {
+		return resource.User{}, "", nil, resource.ErrInvalidMFAChallenge
+	}
+	user, err = s.MachineRepository.GetUserById(ctx, claims.UserID)
+	if err != nil {
+		return resource.User{}, "", nil, err
+	}
+	user.Password = ""
+	if user.Locked(time.Now()) {
+		return user, "", nil, resource.ErrInvalidCredentials
+	}
+	mfa, err := s.MachineRepository.GetMFA(ctx, claims.UserID)
+	if err != nil {
+		return user, "", nil, err
+	}
+
+	if err := s.checkMFACode(ctx, mfa, code); err != nil {
+		if !errors.Is(err, resource.ErrInvalidMFACode) {
+			return user, "", nil, err
+		}
+		if err := s.recordLogin(ctx, user, resource.ErrInvalidCredentials); !errors.Is(err, resource.ErrInvalidCredentials) {
+			return user, "", nil, err
+		}
+		return user, "", nil, resource.ErrInvalidMFACode
+	}
+	if err := s.recordLogin(ctx, user, nil); err != nil {
+		return user, "", nil, err
+	}
+	if !mfa.Enabled() {
+		if recoveryCodes, err = s.enableMFA(ctx, mfa); err != nil {
+			return user, "", nil, err
+		}
+	}
+
+	tokenString, err = s.Tokens.Generate(&user)
+	if err != nil {
+		return user, "", nil, err
+	}
+	return user, tokenString, recoveryCodes, nil
+}
+
+// EnrollMFA starts enrolling the user, replacing an unconfirmed secret.
+// The second factor is enabled once ConfirmMFA is given a first code.
+func (s service) EnrollMFA(ctx context.Context, userID int) (enrollment resource.MFAEnrollment, err error) {
+	ctx, span := startSpan(ctx, "EnrollMFA")
+	defer endSpan(span, &err)
+
+	user, err := s.MachineRepository.GetUserById(ctx, userID)
+	if err != nil {
+		return resource.MFAEnrollment{}, err
+	}
+	mfa, err := s.MachineRepository.GetMFA(ctx, userID)
+	if err != nil && !errors.Is(err, resource.ErrMFANotEnabled) {
+		return resource.MFAEnrollment{}, err
+	}
+	if mfa.Enabled() {
+		return resource.MFAEnrollment{}, resource.ErrMFAAlreadyEnabled
+	}
+
+	secret, err := totp.NewSecret()
+	if err != nil {
+		return resource.MFAEnrollment{}, err
+	}
+	if err := s.MachineRepository.SaveMFA(ctx, &resource.MFA{UserID: uint(userID), Secret: secret, CreatedAt: time.Now()}); err != nil {
+		return resource.MFAEnrollment{}, err
+	}
+	return resource.MFAEnrollment{Secret: secret, URI: totp.URI(s.MFA.Issuer, user.Username, secret)}, nil
+}
+
+// EnrollMFAWithChallenge lets users who have to enrol before they can log
+// in start enrolling with their challenge token.
+func (s service) EnrollMFAWithChallenge(ctx context.Context, challenge string) (user resource.User, enrollment resource.MFAEnrollment, err error) {
+	ctx, span := startSpan(ctx, "EnrollMFAWithChallenge")
+	defer endSpan(span, &err)
+
+	claims, err := s.Tokens.ParseChallenge(challenge)
+	if err != nil {
+		return resource.User{}, resource.MFAEnrollment{}, resource.ErrInvalidMFAChallenge
+	}
+	user = resource.User{UserID: uint(claims.UserID), Username: claims.Username, RoleID: uint(claims.RoleID)}
+	enrollment, err = s.EnrollMFA(ctx, claims.UserID)
+	return user, enrollment, err
+}
+
+// ConfirmMFA enables the second factor with a first code and returns the
+// recovery codes, which are not shown again.
+func (s service) ConfirmMFA(ctx context.Context, userID int, code string) (recoveryCodes []string, err error) {
+	ctx, span := startSpan(ctx, "ConfirmMFA")
+	defer endSpan(span, &err)
+
+	mfa, err := s.MachineRepository.GetMFA(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if mfa.Enabled() {
+		return nil, resource.ErrMFAAlreadyEnabled
+	}
+	if err := s.checkMFACode(ctx, mfa, code); err != nil {
+		return nil, err
+	}
+	return s.enableMFA(ctx, mfa)
+}
+
+// DisableMFA removes the second factor after checking the password, which
+// counts towards the lockout like a login. Roles that require a second
+// factor cannot disable it.
+func (s service) DisableMFA(ctx context.Context, userID int, password string) (err error) {
+	ctx, span := startSpan(ctx, "DisableMFA")
+	defer endSpan(span, &err)
+
+	user, err := s.MachineRepository.GetUserById(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if s.MFA.Required[user.RoleID] {
+		return resource.ErrMFAEnforced
+	}
+	if err := s.reauthenticate(ctx, userID, password); err != nil {
+		return err
+	}
+	if _, err := s.MachineRepository.GetMFA(ctx, userID); err != nil {
+		return err
+	}
+	return s.commit(ctx, func(repository ports.MachineRepository) ([]events.Event, error) {
+		return nil, repository.DeleteMFA(ctx, userID)
+	})
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes after checking a
+// current code.
+func (s service) RegenerateRecoveryCodes(ctx context.Context, userID int, code string) (recoveryCodes []string, err error) {
+	ctx, span := startSpan(ctx, "RegenerateRecoveryCodes")
+	defer endSpan(span, &err)
+
+	mfa, err := s.MachineRepository.GetMFA(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !mfa.Enabled() {
+		return nil, resource.ErrMFANotEnabled
+	}
+	if err := s.checkMFACode(ctx, mfa, code); err != nil {
+		return nil, err
+	}
+	recoveryCodes, hashes, err := s.newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	err = s.commit(ctx, func(repository ports.MachineRepository) ([]events.Event, error) {
+		return nil, repository.ReplaceRecoveryCodes(ctx, userID, hashes)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return recoveryCodes, nil
+}
+
+// ResetMFA removes the second factor of a user who lost both the
+// authenticator and the recovery codes. Users whose role requires one enrol
+// again on their next login.
+func (s service) ResetMFA(ctx context.Context, userID int) (user resource.User, err error) {
+	ctx, span := startSpan(ctx, "ResetMFA")
+	defer endSpan(span, &err)
+
+	user, err = s.MachineRepository.GetUserById(ctx, userID)
+	if err != nil {
+		return resource.User{}, err
+	}
+	if _, err := s.MachineRepository.GetMFA(ctx, userID); err != nil {
+		return resource.User{}, err
+	}
+	err = s.commit(ctx, func(repository ports.MachineRepository) ([]events.Event, error) {
+		return nil, repository.DeleteMFA(ctx, userID)
+	})
+	if err != nil {
+		return resource.User{}, err
+	}
+	user.Password = ""
+	return user, nil
+}
+
+// checkMFACode accepts a TOTP code that was not used before or, once the
+// second factor is enabled, an unused recovery code.
+func (s service) checkMFACode(ctx context.Context, mfa resource.MFA, code string) error {
+	code = strings.TrimSpace(code)
+	if len(code) == totp.Digits {
+		step, ok := totp.Verify(mfa.Secret, code, time.Now(), mfaSkew)
+		if !ok || step <= mfa.LastStep {
+			return resource.ErrInvalidMFACode
+		}
+		return s.MachineRepository.UseMFAStep(ctx, int(mfa.UserID), step)
+	}
+	if !mfa.Enabled() {
+		return resource.ErrInvalidMFACode
+	}
+	return s.MachineRepository.UseRecoveryCode(ctx, int(mfa.UserID), hashRecoveryCode(code), time.Now())
+}
+
+// enableMFA marks the second factor enabled and stores fresh recovery
+// codes.
+func (s service) enableMFA(ctx context.Context, mfa resource.MFA) ([]string, error) {
+	recoveryCodes, hashes, err := s.newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	err = s.commit(ctx, func(repository ports.MachineRepository) ([]events.Event, error) {
+		current, err := repository.GetMFA(ctx, int(mfa.UserID))
+		if err != nil {
+			return nil, err
+		}
+		now := time.Now()
+		current.EnabledAt = &now
+		if err := repository.SaveMFA(ctx, &current); err != nil {
+			return nil, err
+		}
+		return nil, repository.ReplaceRecoveryCodes(ctx, int(mfa.UserID), hashes)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return recoveryCodes, nil
+}
+
+// newRecoveryCodes returns recovery codes of the form xxxxx-xxxxx and their
+// hashes.
+func (s service) newRecoveryCodes() (codes, hashes []string, err error) {
+	for i := 0; i < s.MFA.RecoveryCodes; i++ {
+		random := make([]byte, 7)
+		if _, err := rand.Read(random); err != nil {
+			return nil, nil, err
+		}
+		code := strings.ToLower(recoveryCodeEncoding.EncodeToString(random)[:10])
+		code = code[:5] + "-" + code[5:]
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+	return codes, hashes, nil
+}
+
+// hashRecoveryCode ignores case, spaces and dashes, which users get wrong
+// when typing codes.
+func hashRecoveryCode(code string) string {
+	code = strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
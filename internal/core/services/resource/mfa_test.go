@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"testing"
+	"time"
+	"verkaufsautomat/internal/core/domain/resource"
+	"verkaufsautomat/internal/core/services/mock"
+	"verkaufsautomat/internal/core/totp"
+)
+
+func TestService_VerifyMFA(t *testing.T) {
+	secret, err := totp.NewSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := resource.User{UserID: 2, Username: "sally", RoleID: resource.BuyerRoleID}
+	enabled := time.Now().Add(-time.Hour)
+
+	// verify expects the lookups of a second factor, whose current step was
+	// already used if used is set, and returns a function submitting the
+	// code of that step.
+	verify := func(t *testing.T, used bool) (*mock.MockMachineRepository, int64, func() (string, error)) {
+		s, repository, _ := newTestService(t)
+		challenge, err := s.Tokens.GenerateChallenge(&user, time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+		step := totp.Step(time.Now())
+		mfa := resource.MFA{UserID: 2, Secret: secret, EnabledAt: &enabled}
+		if used {
+			mfa.LastStep = step
+		}
+		repository.EXPECT().GetUserById(gomock.Any(), 2).Return(user, nil)
+		repository.EXPECT().GetMFA(gomock.Any(), 2).Return(mfa, nil)
+
+		code, err := totp.Code(secret, step)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return repository, step, func() (string, error) {
+			_, tokenString, _, err := s.VerifyMFA(context.Background(), challenge, code)
+			return tokenString, err
+		}
+	}
+
+	t.Run("A fresh code completes the login", func(t *testing.T) {
+		repository, step, submit := verify(t, false)
+		repository.EXPECT().UseMFAStep(gomock.Any(), 2, step).Return(nil)
+
+		tokenString, err := submit()
+
+		if err != nil || tokenString == "" {
+			t.Errorf("Expected a token, got %q %v", tokenString, err)
+		}
+	})
+
+	t.Run("A code that was used before is refused and counted", func(t *testing.T) {
+		repository, _, submit := verify(t, true)
+		repository.EXPECT().GetUserForUpdate(gomock.Any(), 2).Return(user, nil)
+		repository.EXPECT().UpdateLoginState(gomock.Any(), 2, 1, nil).Return(nil)
+
+		tokenString, err := submit()
+
+		if !errors.Is(err, resource.ErrInvalidMFACode) || tokenString != "" {
+			t.Errorf("Expected ErrInvalidMFACode without a token, got %q %v", tokenString, err)
+		}
+	})
+
+	t.Run("A code used by a parallel login is refused", func(t *testing.T) {
+		// The step was unused when the second factor was read, but the
+		// repository only lets one login use it.
+		repository, step, submit := verify(t, false)
+		repository.EXPECT().UseMFAStep(gomock.Any(), 2, step).Return(resource.ErrInvalidMFACode)
+		repository.EXPECT().GetUserForUpdate(gomock.Any(), 2).Return(user, nil)
+		repository.EXPECT().UpdateLoginState(gomock.Any(), 2, 1, nil).Return(nil)
+
+		tokenString, err := submit()
+
+		if !errors.Is(err, resource.ErrInvalidMFACode) || tokenString != "" {
+			t.Errorf("Expected ErrInvalidMFACode without a token, got %q %v", tokenString, err)
+		}
+	})
+}
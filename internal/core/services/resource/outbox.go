@@ -0,0 +1,63 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+	"verkaufsautomat/internal/core/domain/resource"
+	"verkaufsautomat/internal/core/events"
+	ports "verkaufsautomat/internal/ports/resource"
+)
+
+// commit runs fn in a transaction and writes the events it returns to the
+// outbox in that same transaction, so an event is stored if and only if the
+// change it describes is. The events are published in-process once the
+// transaction has committed; the outbox relay delivers them to external
+// sinks.
+func (s service) commit(ctx context.Context, fn func(repository ports.MachineRepository) ([]events.Event, error)) error {
+	var published []events.Event
+	err := s.MachineRepository.Transaction(ctx, func(repository ports.MachineRepository) error {
+		changes, err := fn(repository)
+		if err != nil {
+			return err
+		}
+		for _, event := range changes {
+			record, err := outboxEvent(event)
+			if err != nil {
+				return err
+			}
+			if err := repository.CreateOutboxEvent(ctx, &record); err != nil {
+				return err
+			}
+		}
+		published = changes
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, event := range published {
+		s.Events.Publish(event)
+	}
+	return nil
+}
+
+func outboxEvent(event events.Event) (resource.OutboxEvent, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return resource.OutboxEvent{}, err
+	}
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return resource.OutboxEvent{}, err
+	}
+	return resource.OutboxEvent{
+		EventID:       hex.EncodeToString(id),
+		EventName:     event.EventName(),
+		Payload:       string(payload),
+		NextAttemptAt: time.Now(),
+	}, nil
+}
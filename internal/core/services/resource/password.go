@@ -0,0 +1,206 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"golang.org/x/crypto/bcrypt"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+	"verkaufsautomat/internal/core/domain/resource"
+	"verkaufsautomat/internal/core/events"
+	"verkaufsautomat/internal/core/logger"
+	ports "verkaufsautomat/internal/ports/resource"
+)
+
+// PasswordPolicy decides which passwords users may choose. MinLength
+// counts characters and MaxLength bytes, as bcrypt only looks at the first
+// 72 bytes. Breached holds lower-cased passwords known from breaches.
+// Reset tokens can be redeemed for ResetExpiry after they were issued.
+type PasswordPolicy struct {
+	MinLength   int
+	MaxLength   int
+	Breached    map[string]bool
+	ResetExpiry time.Duration
+}
+
+var DefaultPasswordPolicy = PasswordPolicy{MinLength: 8, MaxLength: 72, ResetExpiry: 30 * time.Minute}
+
+// Check returns why the password is not allowed, or nil.
+func (p PasswordPolicy) Check(password string) error {
+	if utf8.RuneCountInString(password) < p.MinLength {
+		return fmt.Errorf("%w: use at least %d characters", resource.ErrPasswordTooShort, p.MinLength)
+	}
+	if p.MaxLength > 0 && len(password) > p.MaxLength {
+		return fmt.Errorf("%w: use at most %d bytes", resource.ErrPasswordTooLong, p.MaxLength)
+	}
+	if p.Breached[strings.ToLower(password)] {
+		return resource.ErrPasswordBreached
+	}
+	return nil
+}
+
+// LoadBreachedPasswords reads a list of breached passwords, one per line.
+// Blank lines and lines starting with # are skipped.
+func LoadBreachedPasswords(path string) (map[string]bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	breached := map[string]bool{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		breached[strings.ToLower(line)] = true
+	}
+	return breached, scanner.Err()
+}
+
+// hashPassword checks the password against the policy and hashes it.
+func (s service) hashPassword(password string) (string, error) {
+	if err := s.Passwords.Check(password); err != nil {
+		return "", err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// ChangePassword sets a new password after checking the current one,
+// which counts towards the lockout like a login. Outstanding reset tokens
+// are revoked.
+func (s service) ChangePassword(ctx context.Context, userID int, currentPassword, newPassword string) (err error) {
+	ctx, span := startSpan(ctx, "ChangePassword")
+	defer endSpan(span, &err)
+
+	if err := s.reauthenticate(ctx, userID, currentPassword); err != nil {
+		return err
+	}
+
+	hash, err := s.hashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+	return s.commit(ctx, func(repository ports.MachineRepository) ([]events.Event, error) {
+		if err := repository.UpdatePassword(ctx, userID, hash); err != nil {
+			return nil, err
+		}
+		return nil, repository.RevokePasswordResets(ctx, userID)
+	})
+}
+
+// RequestPasswordReset issues a reset token for the user and sends it
+// through the notifier, revoking earlier tokens. Unknown usernames are
+// ignored without an error, so that callers cannot tell whether an account
+// exists. Without a notifier every request gets ErrResetUnavailable.
+func (s service) RequestPasswordReset(ctx context.Context, username string) (err error) {
+	ctx, span := startSpan(ctx, "RequestPasswordReset")
+	defer endSpan(span, &err)
+
+	if s.Notifier == nil {
+		return resource.ErrResetUnavailable
+	}
+	user, err := s.MachineRepository.GetUserByUsername(ctx, username)
+	if errors.Is(err, resource.ErrUserNotFound) {
+		logger.Ctx(ctx).With(logger.Fields{"username": username}).Warn("Password reset requested for an unknown user")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	token, err := newResetToken()
+	if err != nil {
+		return err
+	}
+	reset := resource.PasswordReset{
+		UserID:    user.UserID,
+		TokenHash: hashResetToken(token),
+		ExpiresAt: time.Now().Add(s.Passwords.ResetExpiry),
+	}
+	err = s.commit(ctx, func(repository ports.MachineRepository) ([]events.Event, error) {
+		if err := repository.RevokePasswordResets(ctx, int(user.UserID)); err != nil {
+			return nil, err
+		}
+		return nil, repository.CreatePasswordReset(ctx, &reset)
+	})
+	if err != nil {
+		return err
+	}
+
+	user.Password = ""
+	return s.Notifier.NotifyPasswordReset(ctx, user, token, reset.ExpiresAt)
+}
+
+// ResetPassword redeems a reset token and sets a new password. The reset
+// also lifts a lockout, since the user has proven access to where the token
+// was sent.
+func (s service) ResetPassword(ctx context.Context, token, newPassword string) (user resource.User, err error) {
+	ctx, span := startSpan(ctx, "ResetPassword")
+	defer endSpan(span, &err)
+
+	hash, err := s.hashPassword(newPassword)
+	if err != nil {
+		return resource.User{}, err
+	}
+	err = s.commit(ctx, func(repository ports.MachineRepository) ([]events.Event, error) {
+		reset, err := repository.GetPasswordReset(ctx, hashResetToken(token))
+		if err != nil {
+			return nil, err
+		}
+		now := time.Now()
+		if !reset.Usable(now) {
+			return nil, resource.ErrInvalidResetToken
+		}
+		if err := repository.UsePasswordReset(ctx, int(reset.ResetID), now); err != nil {
+			return nil, err
+		}
+
+		user, err = repository.GetUserById(ctx, int(reset.UserID))
+		if err != nil {
+			return nil, err
+		}
+		if err := repository.UpdatePassword(ctx, int(user.UserID), hash); err != nil {
+			return nil, err
+		}
+		if err := repository.UpdateLoginState(ctx, int(user.UserID), 0, nil); err != nil {
+			return nil, err
+		}
+		return nil, repository.RevokePasswordResets(ctx, int(user.UserID))
+	})
+	if err != nil {
+		return resource.User{}, err
+	}
+	user.Password = ""
+	user.FailedLogins = 0
+	user.LockedUntil = nil
+	return user, nil
+}
+
+// newResetToken returns 32 random bytes, URL-safe encoded.
+func newResetToken() (string, error) {
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(token), nil
+}
+
+func hashResetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
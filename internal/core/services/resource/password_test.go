@@ -0,0 +1,76 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"golang.org/x/crypto/bcrypt"
+	"testing"
+	"time"
+	"verkaufsautomat/internal/core/domain/resource"
+)
+
+func TestService_ResetPassword(t *testing.T) {
+	hash := hashResetToken("token")
+
+	t.Run("A valid token sets the password and lifts the lockout", func(t *testing.T) {
+		s, repository, _ := newTestService(t)
+		until := time.Now().Add(time.Minute)
+		repository.EXPECT().GetPasswordReset(gomock.Any(), hash).Return(resource.PasswordReset{ResetID: 7, UserID: 2, TokenHash: hash, ExpiresAt: time.Now().Add(time.Minute)}, nil)
+		repository.EXPECT().UsePasswordReset(gomock.Any(), 7, gomock.Any()).Return(nil)
+		repository.EXPECT().GetUserById(gomock.Any(), 2).Return(resource.User{UserID: 2, Username: "sally", FailedLogins: 3, LockedUntil: &until}, nil)
+		repository.EXPECT().UpdatePassword(gomock.Any(), 2, gomock.Any()).DoAndReturn(func(_ context.Context, _ int, password string) error {
+			if err := bcrypt.CompareHashAndPassword([]byte(password), []byte("new secret")); err != nil {
+				t.Errorf("Expected a hash of the new password, got %v", err)
+			}
+			return nil
+		})
+		repository.EXPECT().UpdateLoginState(gomock.Any(), 2, 0, nil).Return(nil)
+		repository.EXPECT().RevokePasswordResets(gomock.Any(), 2).Return(nil)
+
+		user, err := s.ResetPassword(context.Background(), "token", "new secret")
+
+		if err != nil || user.UserID != 2 || user.Locked(time.Now()) {
+			t.Errorf("Expected sally to be unlocked, got %+v %v", user, err)
+		}
+	})
+
+	t.Run("Used tokens are refused", func(t *testing.T) {
+		s, repository, _ := newTestService(t)
+		used := time.Now().Add(-time.Minute)
+		repository.EXPECT().GetPasswordReset(gomock.Any(), hash).Return(resource.PasswordReset{ResetID: 7, UserID: 2, ExpiresAt: time.Now().Add(time.Minute), UsedAt: &used}, nil)
+
+		if _, err := s.ResetPassword(context.Background(), "token", "new secret"); !errors.Is(err, resource.ErrInvalidResetToken) {
+			t.Errorf("Expected ErrInvalidResetToken, got %v", err)
+		}
+	})
+
+	t.Run("Tokens redeemed by a parallel reset are refused", func(t *testing.T) {
+		s, repository, _ := newTestService(t)
+		repository.EXPECT().GetPasswordReset(gomock.Any(), hash).Return(resource.PasswordReset{ResetID: 7, UserID: 2, ExpiresAt: time.Now().Add(time.Minute)}, nil)
+		repository.EXPECT().UsePasswordReset(gomock.Any(), 7, gomock.Any()).Return(resource.ErrInvalidResetToken)
+
+		if _, err := s.ResetPassword(context.Background(), "token", "new secret"); !errors.Is(err, resource.ErrInvalidResetToken) {
+			t.Errorf("Expected ErrInvalidResetToken, got %v", err)
+		}
+	})
+
+	t.Run("Expired tokens are refused", func(t *testing.T) {
+		s, repository, _ := newTestService(t)
+		repository.EXPECT().GetPasswordReset(gomock.Any(), hash).Return(resource.PasswordReset{ResetID: 7, UserID: 2, ExpiresAt: time.Now().Add(-time.Second)}, nil)
+
+		if _, err := s.ResetPassword(context.Background(), "token", "new secret"); !errors.Is(err, resource.ErrInvalidResetToken) {
+			t.Errorf("Expected ErrInvalidResetToken, got %v", err)
+		}
+	})
+}
+
+func TestService_RequestPasswordReset(t *testing.T) {
+	t.Run("Requests are refused without a notifier, whether the user exists or not", func(t *testing.T) {
+		s, _, _ := newTestService(t)
+
+		if err := s.RequestPasswordReset(context.Background(), "sally"); !errors.Is(err, resource.ErrResetUnavailable) {
+			t.Errorf("Expected ErrResetUnavailable, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"verkaufsautomat/internal/core/domain/resource"
+	"verkaufsautomat/internal/core/events"
+	ports "verkaufsautomat/internal/ports/resource"
+)
+
+func (s service) GetProfile(ctx context.Context, userID int) (profile resource.Profile, err error) {
+	ctx, span := startSpan(ctx, "GetProfile")
+	defer endSpan(span, &err)
+
+	user, err := s.MachineRepository.GetUserById(ctx, userID)
+	if err != nil {
+		return resource.Profile{}, err
+	}
+	return profileOf(ctx, s.MachineRepository, user)
+}
+
+// UpdateProfile renames the user. Tokens issued before stay valid and
+// authenticate the renamed user, see AuthenticateToken.
+func (s service) UpdateProfile(ctx context.Context, userID int, username string) (profile resource.Profile, err error) {
+	ctx, span := startSpan(ctx, "UpdateProfile")
+	defer endSpan(span, &err)
+
+	username = strings.TrimSpace(username)
+	if username == "" {
+		return resource.Profile{}, resource.ErrInvalidUsername
+	}
+	err = s.commit(ctx, func(repository ports.MachineRepository) ([]events.Event, error) {
+		user, err := repository.GetUserById(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if user.Username != username {
+			existing, err := repository.GetUserByUsername(ctx, username)
+			if err == nil && existing.UserID != user.UserID {
+				return nil, resource.ErrUserExists
+			}
+			if err != nil && !errors.Is(err, resource.ErrUserNotFound) {
+				return nil, err
+			}
+		}
+
+		user.Username = username
+		if err := repository.UpdateUser(ctx, user); err != nil {
+			return nil, err
+		}
+		profile, err = profileOf(ctx, repository, user)
+		if err != nil {
+			return nil, err
+		}
+		return []events.Event{events.UserUpdated{UserID: user.UserID, RoleID: user.RoleID}}, nil
+	})
+	if err != nil {
+		return resource.Profile{}, err
+	}
+	return profile, nil
+}
+
+func (s service) DeleteAccount(ctx context.Context, userID int, password string, refund bool) (refunded int, err error) {
+	ctx, span := startSpan(ctx, "DeleteAccount")
+	defer endSpan(span, &err)
+
+	if err := s.reauthenticate(ctx, userID, password); err != nil {
+		return 0, err
+	}
+	err = s.commit(ctx, func(repository ports.MachineRepository) ([]events.Event, error) {
+		// The lock keeps purchases from spending the deposit that is
+		// refunded.
+		user, err := repository.GetUserForUpdate(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if user.Deposit > 0 && !refund {
+			return nil, resource.ErrDepositOutstanding
+		}
+
+		var changes []events.Event
+		if user.RoleID == resource.SellerRoleID {
+			webhookEvents, err := deleteSellerData(ctx, repository, user.UserID)
+			if err != nil {
+				return nil, err
+			}
+			changes = append(changes, webhookEvents...)
+		}
+		if err := repository.AnonymiseOrders(ctx, userID); err != nil {
+			return nil, err
+		}
+		if err := repository.RevokePasswordResets(ctx, userID); err != nil {
+			return nil, err
+		}
+		if err := repository.DeleteMFA(ctx, userID); err != nil {
+			return nil, err
+		}
+		if err := repository.DeleteUser(ctx, userID); err != nil {
+			return nil, err
+		}
+
+		refunded = user.Deposit
+		if refunded > 0 {
+			changes = append(changes, events.DepositReset{UserID: userID, Refunded: refunded})
+		}
+		return append(changes, events.UserDeleted{UserID: user.UserID, RoleID: user.RoleID, Refunded: refunded}), nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return refunded, nil
+}
+
+// deleteSellerData refuses to delete sellers who still have products and
+// removes their webhooks.
+func deleteSellerData(ctx context.Context, repository ports.MachineRepository, sellerID uint) ([]events.Event, error) {
+	products, err := repository.GetProducts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, product := range products {
+		if product.SellerID == sellerID {
+			return nil, resource.ErrSellerHasProducts
+		}
+	}
+
+	webhooks, err := repository.GetWebhooksBySeller(ctx, int(sellerID))
+	if err != nil {
+		return nil, err
+	}
+	var changes []events.Event
+	for _, webhook := range webhooks {
+		if err := repository.DeleteWebhookByID(ctx, int(webhook.WebhookID)); err != nil {
+			return nil, err
+		}
+		changes = append(changes, events.WebhookDeleted{WebhookID: webhook.WebhookID, SellerID: webhook.SellerID})
+	}
+	return changes, nil
+}
+
+// profileOf adds the role name, its permissions and whether a second
+// factor is enabled to the user.
+func profileOf(ctx context.Context, repository ports.MachineRepository, user resource.User) (resource.Profile, error) {
+	role, err := repository.GetRole(ctx, int(user.RoleID))
+	if err != nil {
+		return resource.Profile{}, err
+	}
+	permissions, err := repository.GetPermissionsByRole(ctx, int(user.RoleID))
+	if err != nil {
+		return resource.Profile{}, err
+	}
+	if permissions == nil {
+		permissions = []string{}
+	}
+	mfa, err := repository.GetMFA(ctx, int(user.UserID))
+	if err != nil && !errors.Is(err, resource.ErrMFANotEnabled) {
+		return resource.Profile{}, err
+	}
+	return resource.Profile{
+		UserID:      user.UserID,
+		Username:    user.Username,
+		RoleID:      user.RoleID,
+		Role:        role.RoleName,
+		Deposit:     user.Deposit,
+		Permissions: permissions,
+		MFAEnabled:  mfa.Enabled(),
+	}, nil
+}
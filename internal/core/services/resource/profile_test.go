@@ -0,0 +1,109 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"testing"
+	"verkaufsautomat/internal/core/domain/resource"
+	"verkaufsautomat/internal/core/events"
+	"verkaufsautomat/internal/core/services/mock"
+)
+
+func TestService_DeleteAccount(t *testing.T) {
+	// deleteAccount expects the password check of the user and returns the
+	// repository with the user locked for the deletion.
+	deleteAccount := func(t *testing.T, user resource.User) (*service, *mock.MockMachineRepository, *[]events.Event) {
+		s, repository, published := newTestService(t)
+		repository.EXPECT().GetUserById(gomock.Any(), int(user.UserID)).Return(user, nil)
+		repository.EXPECT().Login(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, check *resource.User) error {
+			*check = user
+			return nil
+		})
+		repository.EXPECT().GetUserForUpdate(gomock.Any(), int(user.UserID)).Return(user, nil)
+		return s, repository, published
+	}
+	// expectDeletion expects the user's data to be removed.
+	expectDeletion := func(repository *mock.MockMachineRepository, userID int) {
+		repository.EXPECT().AnonymiseOrders(gomock.Any(), userID).Return(nil)
+		repository.EXPECT().RevokePasswordResets(gomock.Any(), userID).Return(nil)
+		repository.EXPECT().DeleteMFA(gomock.Any(), userID).Return(nil)
+		repository.EXPECT().DeleteUser(gomock.Any(), userID).Return(nil)
+	}
+
+	t.Run("Buyers with a deposit are refused unless it is refunded", func(t *testing.T) {
+		s, _, published := deleteAccount(t, resource.User{UserID: 2, RoleID: resource.BuyerRoleID, Deposit: 50})
+
+		refunded, err := s.DeleteAccount(context.Background(), 2, "secret", false)
+
+		if !errors.Is(err, resource.ErrDepositOutstanding) || refunded != 0 {
+			t.Errorf("Expected ErrDepositOutstanding, got %d %v", refunded, err)
+		}
+		if len(*published) != 0 {
+			t.Errorf("Expected no events, got %v", *published)
+		}
+	})
+
+	t.Run("The deposit is refunded with the deletion", func(t *testing.T) {
+		s, repository, published := deleteAccount(t, resource.User{UserID: 2, RoleID: resource.BuyerRoleID, Deposit: 50})
+		expectDeletion(repository, 2)
+
+		refunded, err := s.DeleteAccount(context.Background(), 2, "secret", true)
+
+		if err != nil || refunded != 50 {
+			t.Fatalf("Expected 50 to be refunded, got %d %v", refunded, err)
+		}
+		if len(*published) != 2 {
+			t.Fatalf("Expected two events, got %v", *published)
+		}
+		if reset, ok := (*published)[0].(events.DepositReset); !ok || reset.Refunded != 50 {
+			t.Errorf("Expected the refund to be published, got %+v", (*published)[0])
+		}
+		if deleted, ok := (*published)[1].(events.UserDeleted); !ok || deleted.UserID != 2 || deleted.Refunded != 50 {
+			t.Errorf("Expected the deletion to be published, got %+v", (*published)[1])
+		}
+	})
+
+	t.Run("Sellers with products are refused", func(t *testing.T) {
+		s, repository, _ := deleteAccount(t, resource.User{UserID: 3, RoleID: resource.SellerRoleID})
+		repository.EXPECT().GetProducts(gomock.Any()).Return([]resource.Product{{ProductID: 1, SellerID: 4}, {ProductID: 2, SellerID: 3}}, nil)
+
+		if _, err := s.DeleteAccount(context.Background(), 3, "secret", false); !errors.Is(err, resource.ErrSellerHasProducts) {
+			t.Errorf("Expected ErrSellerHasProducts, got %v", err)
+		}
+	})
+
+	t.Run("Sellers without products lose their webhooks", func(t *testing.T) {
+		s, repository, published := deleteAccount(t, resource.User{UserID: 3, RoleID: resource.SellerRoleID})
+		repository.EXPECT().GetProducts(gomock.Any()).Return([]resource.Product{{ProductID: 1, SellerID: 4}}, nil)
+		repository.EXPECT().GetWebhooksBySeller(gomock.Any(), 3).Return([]resource.Webhook{{WebhookID: 5, SellerID: 3}}, nil)
+		repository.EXPECT().DeleteWebhookByID(gomock.Any(), 5).Return(nil)
+		expectDeletion(repository, 3)
+
+		if _, err := s.DeleteAccount(context.Background(), 3, "secret", false); err != nil {
+			t.Fatal(err)
+		}
+		if len(*published) != 2 {
+			t.Fatalf("Expected two events, got %v", *published)
+		}
+		if _, ok := (*published)[0].(events.WebhookDeleted); !ok {
+			t.Errorf("Expected the webhook deletion to be published, got %+v", (*published)[0])
+		}
+	})
+
+	t.Run("A wrong password is refused before anything is deleted", func(t *testing.T) {
+		s, repository, _ := newTestService(t)
+		user := resource.User{UserID: 2, Username: "sally", RoleID: resource.BuyerRoleID}
+		repository.EXPECT().GetUserById(gomock.Any(), 2).Return(user, nil)
+		repository.EXPECT().Login(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, check *resource.User) error {
+			*check = user
+			return resource.ErrInvalidCredentials
+		})
+		repository.EXPECT().GetUserForUpdate(gomock.Any(), 2).Return(user, nil)
+		repository.EXPECT().UpdateLoginState(gomock.Any(), 2, 1, nil).Return(nil)
+
+		if _, err := s.DeleteAccount(context.Background(), 2, "guess", true); !errors.Is(err, resource.ErrInvalidCredentials) {
+			t.Errorf("Expected ErrInvalidCredentials, got %v", err)
+		}
+	})
+}
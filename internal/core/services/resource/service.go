@@ -1,60 +1,259 @@
 package services
 
 import (
+	"context"
 	"verkaufsautomat/internal/core/domain/resource"
+	"verkaufsautomat/internal/core/events"
+	"verkaufsautomat/internal/core/token"
 	ports "verkaufsautomat/internal/ports/resource"
 )
 
 type service struct {
 	MachineRepository ports.MachineRepository
+	Events            ports.EventPublisher
+	Tokens            *token.Issuer
+	Lockout           LockoutPolicy
+	Passwords         PasswordPolicy
+	Notifier          ports.Notifier
+	MFA               MFAPolicy
+	Webhooks          WebhookPolicy
 }
 
-func (s service) UpdateUser(user resource.User) error {
-	return s.MachineRepository.UpdateUser(user)
+func (s service) UpdateUser(ctx context.Context, user resource.User) (err error) {
+	ctx, span := startSpan(ctx, "UpdateUser")
+	defer endSpan(span, &err)
+
+	return s.commit(ctx, func(repository ports.MachineRepository) ([]events.Event, error) {
+		if _, err := repository.GetUserById(ctx, int(user.UserID)); err != nil {
+			return nil, err
+		}
+		if err := repository.UpdateUser(ctx, user); err != nil {
+			return nil, err
+		}
+		return []events.Event{events.UserUpdated{UserID: user.UserID, RoleID: user.RoleID}}, nil
+	})
 }
 
-func (s service) DeleteProductByID(id int) error {
-	return s.MachineRepository.DeleteProductByID(id)
+func (s service) DeleteProductByID(ctx context.Context, id int) (err error) {
+	ctx, span := startSpan(ctx, "DeleteProductByID")
+	defer endSpan(span, &err)
+
+	return s.commit(ctx, func(repository ports.MachineRepository) ([]events.Event, error) {
+		product, err := repository.GetProductById(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if err := repository.DeleteProductByID(ctx, id); err != nil {
+			return nil, err
+		}
+		return []events.Event{events.ProductDeleted{Product: product}}, nil
+	})
 }
 
-func (s service) GetProducts() ([]resource.Product, error) {
-	return s.MachineRepository.GetProducts()
+func (s service) GetProducts(ctx context.Context) (products []resource.Product, err error) {
+	ctx, span := startSpan(ctx, "GetProducts")
+	defer endSpan(span, &err)
+
+	return s.MachineRepository.GetProducts(ctx)
 }
 
-func (s service) GetProductById(id int) (resource.Product, error) {
-	return s.MachineRepository.GetProductById(id)
+func (s service) SearchProducts(ctx context.Context, filter resource.ProductFilter) (products []resource.Product, err error) {
+	ctx, span := startSpan(ctx, "SearchProducts")
+	defer endSpan(span, &err)
+
+	return s.MachineRepository.SearchProducts(ctx, filter)
 }
 
-func (s service) UpdateProductByID(id int, product *resource.Product) error {
-	return s.MachineRepository.UpdateProductByID(id, product)
+func (s service) GetProductById(ctx context.Context, id int) (product resource.Product, err error) {
+	ctx, span := startSpan(ctx, "GetProductById")
+	defer endSpan(span, &err)
+
+	return s.MachineRepository.GetProductById(ctx, id)
 }
 
-func (s service) DepositMoney(userid, amount int) error {
-	return s.MachineRepository.DepositMoney(userid, amount)
+func (s service) UpdateProductByID(ctx context.Context, id int, product *resource.Product) (err error) {
+	ctx, span := startSpan(ctx, "UpdateProductByID")
+	defer endSpan(span, &err)
+
+	return s.commit(ctx, func(repository ports.MachineRepository) ([]events.Event, error) {
+		previous, err := repository.GetProductById(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		// Products stay with their seller, whoever sends the update, so
+		// that the events reach the stored owner's webhooks.
+		product.SellerID = previous.SellerID
+		if err := normalizeProduct(ctx, repository, product); err != nil {
+			return nil, err
+		}
+		if err := repository.UpdateProductByID(ctx, id, product); err != nil {
+			return nil, err
+		}
+		changes := []events.Event{events.ProductUpdated{Product: *product, Previous: previous}}
+		if previous.Cost != product.Cost {
+			changes = append(changes, events.PriceChanged{Product: *product, PreviousCost: previous.Cost})
+		}
+		if previous.AmountAvailable != product.AmountAvailable {
+			changes = append(changes, events.StockChanged{Product: *product, PreviousAmount: previous.AmountAvailable})
+		}
+		return changes, nil
+	})
 }
 
-func (s service) CreateProduct(product *resource.Product) error {
-	return s.MachineRepository.CreateProduct(product)
+func (s service) DepositMoney(ctx context.Context, userid, amount int) (err error) {
+	ctx, span := startSpan(ctx, "DepositMoney")
+	defer endSpan(span, &err)
+
+	return s.commit(ctx, func(repository ports.MachineRepository) ([]events.Event, error) {
+		user, err := repository.GetUserForUpdate(ctx, userid)
+		if err != nil {
+			return nil, err
+		}
+		if err := repository.DepositMoney(ctx, userid, amount); err != nil {
+			return nil, err
+		}
+		return []events.Event{events.DepositMade{UserID: userid, Amount: amount, Deposit: user.Deposit + amount}}, nil
+	})
 }
 
-func (s service) Login(user *resource.User) error {
-	return s.MachineRepository.Login(user)
+// ResetDeposit sets the user's deposit to zero and returns the amount that
+// was refunded.
+func (s service) ResetDeposit(ctx context.Context, userID int) (refunded int, err error) {
+	ctx, span := startSpan(ctx, "ResetDeposit")
+	defer endSpan(span, &err)
+
+	err = s.commit(ctx, func(repository ports.MachineRepository) ([]events.Event, error) {
+		user, err := repository.GetUserForUpdate(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+
+		refunded = user.Deposit
+		if err := repository.ResetDeposit(ctx, userID); err != nil {
+			return nil, err
+		}
+		return []events.Event{events.DepositReset{UserID: userID, Refunded: refunded}}, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return refunded, nil
+}
+
+func (s service) CreateProduct(ctx context.Context, product *resource.Product) (err error) {
+	ctx, span := startSpan(ctx, "CreateProduct")
+	defer endSpan(span, &err)
+
+	return s.commit(ctx, func(repository ports.MachineRepository) ([]events.Event, error) {
+		if err := normalizeProduct(ctx, repository, product); err != nil {
+			return nil, err
+		}
+		if err := repository.CreateProduct(ctx, product); err != nil {
+			return nil, err
+		}
+		return []events.Event{events.ProductCreated{Product: *product}}, nil
+	})
 }
 
-func (s service) GetUserById(id int) (resource.User, error) {
-	return s.MachineRepository.GetUserById(id)
+func (s service) GetUserById(ctx context.Context, id int) (user resource.User, err error) {
+	ctx, span := startSpan(ctx, "GetUserById")
+	defer endSpan(span, &err)
+
+	return s.MachineRepository.GetUserById(ctx, id)
 }
 
-func (s service) HealthCheck() error {
-	return s.MachineRepository.HealthCheck()
+func (s service) HealthCheck(ctx context.Context) []resource.DependencyStatus {
+	ctx, span := startSpan(ctx, "HealthCheck")
+	defer span.End()
+
+	return s.MachineRepository.HealthCheck(ctx)
 }
 
-func New(MachineRepository ports.MachineRepository) *service {
+func New(MachineRepository ports.MachineRepository, Events ports.EventPublisher, Tokens *token.Issuer) *service {
 	return &service{
 		MachineRepository: MachineRepository,
+		Events:            Events,
+		Tokens:            Tokens,
+		Lockout:           DefaultLockoutPolicy,
+		Passwords:         DefaultPasswordPolicy,
+		MFA:               DefaultMFAPolicy,
+	}
+}
+
+func (s service) Register(ctx context.Context, user *resource.User) (err error) {
+	ctx, span := startSpan(ctx, "Register")
+	defer endSpan(span, &err)
+
+	if user.RoleID != resource.BuyerRoleID && user.RoleID != resource.SellerRoleID {
+		return resource.ErrRoleNotAllowed
+	}
+	hash, err := s.hashPassword(user.Password)
+	if err != nil {
+		return err
 	}
+	user.Password = hash
+	return s.commit(ctx, func(repository ports.MachineRepository) ([]events.Event, error) {
+		if err := repository.Register(ctx, user); err != nil {
+			return nil, err
+		}
+		return []events.Event{events.UserRegistered{UserID: user.UserID, Username: user.Username, RoleID: user.RoleID}}, nil
+	})
 }
 
-func (s service) Register(user *resource.User) error {
-	return s.MachineRepository.Register(user)
+func (s service) BuyProduct(ctx context.Context, buyerID, productID, quantity int) (order resource.Order, remaining int, err error) {
+	ctx, span := startSpan(ctx, "BuyProduct")
+	defer endSpan(span, &err)
+
+	order = resource.Order{
+		BuyerID:   uint(buyerID),
+		ProductID: uint(productID),
+		Quantity:  quantity,
+	}
+	if quantity <= 0 {
+		s.Events.Publish(events.PurchaseFailed{BuyerID: buyerID, ProductID: productID, Quantity: quantity, Err: resource.ErrInvalidQuantity})
+		return order, 0, resource.ErrInvalidQuantity
+	}
+
+	err = s.commit(ctx, func(repository ports.MachineRepository) ([]events.Event, error) {
+		var err error
+		remaining, err = repository.BuyProduct(ctx, &order)
+		if err != nil {
+			return nil, err
+		}
+		product, err := repository.GetProductById(ctx, productID)
+		if err != nil {
+			return nil, err
+		}
+		return []events.Event{
+			events.PurchaseCompleted{Order: order, Product: product, RemainingDeposit: remaining},
+			events.StockChanged{Product: product, PreviousAmount: product.AmountAvailable + quantity},
+		}, nil
+	})
+	if err != nil {
+		s.Events.Publish(events.PurchaseFailed{BuyerID: buyerID, ProductID: productID, Quantity: quantity, Err: err})
+	}
+	return order, remaining, err
+}
+
+// GetTotalDeposit returns the money buyers have deposited and not spent,
+// which the machine must be able to pay out.
+func (s service) GetTotalDeposit(ctx context.Context) (total int, err error) {
+	ctx, span := startSpan(ctx, "GetTotalDeposit")
+	defer endSpan(span, &err)
+
+	return s.MachineRepository.GetTotalDeposit(ctx)
+}
+
+func (s service) GetOrdersByBuyer(ctx context.Context, buyerID int) (orders []resource.Order, err error) {
+	ctx, span := startSpan(ctx, "GetOrdersByBuyer")
+	defer endSpan(span, &err)
+
+	return s.MachineRepository.GetOrdersByBuyer(ctx, buyerID)
+}
+
+func (s service) GetOrderById(ctx context.Context, id int) (order resource.Order, err error) {
+	ctx, span := startSpan(ctx, "GetOrderById")
+	defer endSpan(span, &err)
+
+	return s.MachineRepository.GetOrderById(ctx, id)
 }
@@ -0,0 +1,58 @@
+package services
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	"testing"
+	"time"
+	"verkaufsautomat/internal/core/domain/resource"
+	"verkaufsautomat/internal/core/events"
+	"verkaufsautomat/internal/core/services/mock"
+	"verkaufsautomat/internal/core/token"
+	ports "verkaufsautomat/internal/ports/resource"
+)
+
+// newTestService returns a service on a mocked repository. Transactions
+// run on the same mock, and the events the service commits are collected
+// from the bus.
+func newTestService(t *testing.T) (*service, *mock.MockMachineRepository, *[]events.Event) {
+	ctrl := gomock.NewController(t)
+	repository := mock.NewMockMachineRepository(ctrl)
+	repository.EXPECT().Transaction(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, fn func(repository ports.MachineRepository) error) error {
+		return fn(repository)
+	}).AnyTimes()
+	repository.EXPECT().CreateOutboxEvent(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	bus := events.NewBus()
+	published := &[]events.Event{}
+	bus.Subscribe(func(event events.Event) {
+		*published = append(*published, event)
+	})
+	return New(repository, bus, token.NewIssuer([]byte("secret"), time.Hour)), repository, published
+}
+
+func TestService_UpdateProductByID(t *testing.T) {
+	t.Run("Products and their events keep the stored seller", func(t *testing.T) {
+		s, repository, published := newTestService(t)
+		repository.EXPECT().GetProductById(gomock.Any(), 1).Return(resource.Product{ProductID: 1, SellerID: 3, AmountAvailable: 5}, nil)
+		repository.EXPECT().GetCategoriesByIds(gomock.Any(), gomock.Any()).Return(nil, nil)
+		repository.EXPECT().UpdateProductByID(gomock.Any(), 1, gomock.Any()).DoAndReturn(func(_ context.Context, _ int, product *resource.Product) error {
+			if product.SellerID != 3 {
+				t.Errorf("Expected the product to stay with seller 3, got %d", product.SellerID)
+			}
+			return nil
+		})
+
+		product := resource.Product{SellerID: 4, AmountAvailable: 2}
+		if err := s.UpdateProductByID(context.Background(), 1, &product); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(*published) != 2 {
+			t.Fatalf("Expected two events, got %v", *published)
+		}
+		if changed, ok := (*published)[1].(events.StockChanged); !ok || changed.Product.SellerID != 3 {
+			t.Errorf("Expected the stock change to name seller 3, got %+v", (*published)[1])
+		}
+	})
+}
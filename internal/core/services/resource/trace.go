@@ -0,0 +1,25 @@
+package services
+
+import (
+	"context"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("verkaufsautomat/internal/core/services/resource")
+
+// startSpan starts the span of a MachineService method. Callers defer
+// endSpan with a pointer to their error result so that failures are
+// recorded on the span.
+func startSpan(ctx context.Context, method string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, "MachineService."+method)
+}
+
+func endSpan(span trace.Span, err *error) {
+	if *err != nil {
+		span.RecordError(*err)
+		span.SetStatus(codes.Error, (*err).Error())
+	}
+	span.End()
+}
@@ -0,0 +1,184 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+	"verkaufsautomat/internal/core/domain/resource"
+	"verkaufsautomat/internal/core/events"
+	ports "verkaufsautomat/internal/ports/resource"
+)
+
+const defaultLowStockThreshold = 5
+
+// WebhookPolicy restricts where webhooks may be sent. Unless AllowPrivate
+// is set, which is meant for local development, webhook hosts must only
+// resolve to public addresses.
+type WebhookPolicy struct {
+	AllowPrivate bool
+}
+
+// checkTarget resolves host and refuses it if any of its addresses is not
+// public. The dispatcher checks the address again when it connects, as the
+// DNS answer can change after the webhook was created.
+func (p WebhookPolicy) checkTarget(ctx context.Context, host string) error {
+	if p.AllowPrivate {
+		return nil
+	}
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return fmt.Errorf("%w: cannot resolve %s", resource.ErrInvalidWebhook, host)
+	}
+	for _, addr := range addrs {
+		if !resource.PublicIP(addr.IP) {
+			return fmt.Errorf("%w: %s is not a public address", resource.ErrInvalidWebhook, host)
+		}
+	}
+	return nil
+}
+
+func (s service) CreateWebhook(ctx context.Context, webhook *resource.Webhook) (err error) {
+	ctx, span := startSpan(ctx, "CreateWebhook")
+	defer endSpan(span, &err)
+
+	target, err := url.Parse(webhook.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Hostname() == "" {
+		return resource.ErrInvalidWebhook
+	}
+	if err := s.Webhooks.checkTarget(ctx, target.Hostname()); err != nil {
+		return err
+	}
+
+	types := strings.Split(webhook.EventTypes, ",")
+	if webhook.EventTypes == "" {
+		types = resource.WebhookEventTypes
+	}
+	for i, t := range types {
+		types[i] = strings.TrimSpace(t)
+		if !isWebhookEventType(types[i]) {
+			return resource.ErrInvalidWebhook
+		}
+	}
+	webhook.EventTypes = strings.Join(types, ",")
+
+	if webhook.LowStockThreshold <= 0 {
+		webhook.LowStockThreshold = defaultLowStockThreshold
+	}
+	if webhook.Secret == "" {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return err
+		}
+		webhook.Secret = hex.EncodeToString(secret)
+	}
+
+	return s.commit(ctx, func(repository ports.MachineRepository) ([]events.Event, error) {
+		if err := repository.CreateWebhook(ctx, webhook); err != nil {
+			return nil, err
+		}
+		return []events.Event{events.WebhookCreated{
+			WebhookID:  webhook.WebhookID,
+			SellerID:   webhook.SellerID,
+			URL:        webhook.URL,
+			EventTypes: webhook.EventTypes,
+		}}, nil
+	})
+}
+
+func (s service) GetWebhooksBySeller(ctx context.Context, sellerID int) (webhooks []resource.Webhook, err error) {
+	ctx, span := startSpan(ctx, "GetWebhooksBySeller")
+	defer endSpan(span, &err)
+
+	return s.MachineRepository.GetWebhooksBySeller(ctx, sellerID)
+}
+
+func (s service) GetWebhookById(ctx context.Context, id int) (webhook resource.Webhook, err error) {
+	ctx, span := startSpan(ctx, "GetWebhookById")
+	defer endSpan(span, &err)
+
+	return s.MachineRepository.GetWebhookById(ctx, id)
+}
+
+func (s service) DeleteWebhookByID(ctx context.Context, id int) (err error) {
+	ctx, span := startSpan(ctx, "DeleteWebhookByID")
+	defer endSpan(span, &err)
+
+	return s.commit(ctx, func(repository ports.MachineRepository) ([]events.Event, error) {
+		webhook, err := repository.GetWebhookById(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if err := repository.DeleteWebhookByID(ctx, id); err != nil {
+			return nil, err
+		}
+		return []events.Event{events.WebhookDeleted{WebhookID: webhook.WebhookID, SellerID: webhook.SellerID}}, nil
+	})
+}
+
+func (s service) GetWebhookDeliveries(ctx context.Context, webhookID int) (deliveries []resource.WebhookDelivery, err error) {
+	ctx, span := startSpan(ctx, "GetWebhookDeliveries")
+	defer endSpan(span, &err)
+
+	return s.MachineRepository.GetWebhookDeliveries(ctx, webhookID)
+}
+
+func (s service) GetWebhookDeliveryById(ctx context.Context, id int) (delivery resource.WebhookDelivery, err error) {
+	ctx, span := startSpan(ctx, "GetWebhookDeliveryById")
+	defer endSpan(span, &err)
+
+	return s.MachineRepository.GetWebhookDeliveryById(ctx, id)
+}
+
+// ReplayWebhookDelivery queues a new delivery of the same event. The
+// original delivery is kept in the log unchanged.
+func (s service) ReplayWebhookDelivery(ctx context.Context, id int) (replay resource.WebhookDelivery, err error) {
+	ctx, span := startSpan(ctx, "ReplayWebhookDelivery")
+	defer endSpan(span, &err)
+
+	err = s.commit(ctx, func(repository ports.MachineRepository) ([]events.Event, error) {
+		original, err := repository.GetWebhookDeliveryById(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		deliveries, err := repository.GetWebhookDeliveries(ctx, int(original.WebhookID))
+		if err != nil {
+			return nil, err
+		}
+		replays := 0
+		for _, delivery := range deliveries {
+			if delivery.EventID == original.EventID && delivery.Replay > replays {
+				replays = delivery.Replay
+			}
+		}
+
+		replay = resource.WebhookDelivery{
+			WebhookID:     original.WebhookID,
+			EventID:       original.EventID,
+			Replay:        replays + 1,
+			EventType:     original.EventType,
+			Payload:       original.Payload,
+			Status:        resource.DeliveryPending,
+			NextAttemptAt: time.Now(),
+		}
+		if err := repository.CreateWebhookDelivery(ctx, &replay); err != nil {
+			return nil, err
+		}
+		return []events.Event{events.WebhookDeliveryReplayed{Delivery: replay, ReplayedFromID: original.DeliveryID}}, nil
+	})
+	return replay, err
+}
+
+func isWebhookEventType(t string) bool {
+	for _, known := range resource.WebhookEventTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,133 @@
+package token
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/dgrijalva/jwt-go"
+	"time"
+	"verkaufsautomat/internal/core/domain/resource"
+)
+
+// Claims identifies the user a token was issued to. TokenVersion is the
+// user's token version at the time, see resource.User.
+type Claims struct {
+	UserID       int
+	RoleID       int
+	Username     string
+	TokenVersion int
+}
+
+// Issuer signs and verifies tokens with one secret.
+type Issuer struct {
+	secret []byte
+	expiry time.Duration
+}
+
+// NewIssuer returns an issuer whose tokens are valid for expiry.
+func NewIssuer(secret []byte, expiry time.Duration) *Issuer {
+	return &Issuer{secret: secret, expiry: expiry}
+}
+
+// Expiry is how long issued tokens are valid.
+func (i *Issuer) Expiry() time.Duration {
+	return i.expiry
+}
+
+// Generate signs a token for the user.
+func (i *Issuer) Generate(user *resource.User) (string, error) {
+	t := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"username":      user.Username,
+		"user_id":       user.UserID,
+		"role_id":       user.RoleID,
+		"token_version": user.TokenVersion,
+		"exp":           time.Now().Add(i.expiry).Unix(),
+	})
+
+	return t.SignedString(i.secret)
+}
+
+// GenerateChallenge signs a token that proves the user gave the right
+// password, valid for expiry, while a second factor is outstanding.
+// Challenge tokens are not accepted as login tokens.
+func (i *Issuer) GenerateChallenge(user *resource.User, expiry time.Duration) (string, error) {
+	t := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"username": user.Username,
+		"user_id":  user.UserID,
+		"role_id":  user.RoleID,
+		"purpose":  challengePurpose,
+		"exp":      time.Now().Add(expiry).Unix(),
+	})
+
+	return t.SignedString(i.secret)
+}
+
+// CSRFToken derives the CSRF token of a cookie session from its login
+// token, so that it is only valid together with that token.
+func (i *Issuer) CSRFToken(tokenString string) string {
+	mac := hmac.New(sha256.New, i.secret)
+	mac.Write([]byte(csrfPurpose))
+	mac.Write([]byte(tokenString))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// csrfPurpose separates CSRF tokens from other values signed with the
+// secret.
+const csrfPurpose = "csrf:"
+
+// challengePurpose marks challenge tokens, which login tokens lack.
+const challengePurpose = "mfa"
+
+// Verify checks the signature and expiry of a login token.
+func (i *Issuer) Verify(tokenString string) (*jwt.Token, error) {
+	return i.verify(tokenString, "")
+}
+
+func (i *Issuer) verify(tokenString, purpose string) (*jwt.Token, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		// validate the alg is what we expect:
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("Unexpected signing method: %v", token.Header["alg"])
+		}
+
+		return i.secret, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if actual, _ := claims["purpose"].(string); !ok || actual != purpose {
+		return nil, errors.New("token is not valid for this purpose")
+	}
+	return token, nil
+}
+
+// Parse verifies a login token and returns its claims.
+func (i *Issuer) Parse(tokenString string) (Claims, error) {
+	return i.parse(tokenString, "")
+}
+
+// ParseChallenge verifies a challenge token and returns its claims.
+func (i *Issuer) ParseChallenge(tokenString string) (Claims, error) {
+	return i.parse(tokenString, challengePurpose)
+}
+
+func (i *Issuer) parse(tokenString, purpose string) (Claims, error) {
+	token, err := i.verify(tokenString, purpose)
+	if err != nil {
+		return Claims{}, err
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return Claims{}, errors.New("token is not valid")
+	}
+
+	userID, _ := claims["user_id"].(float64)
+	roleID, _ := claims["role_id"].(float64)
+	username, _ := claims["username"].(string)
+	version, _ := claims["token_version"].(float64)
+	return Claims{UserID: int(userID), RoleID: int(roleID), Username: username, TokenVersion: int(version)}, nil
+}
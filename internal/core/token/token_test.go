@@ -0,0 +1,90 @@
+package token
+
+import (
+	"testing"
+	"time"
+	"verkaufsautomat/internal/core/domain/resource"
+)
+
+func TestIssuer(t *testing.T) {
+	issuer := NewIssuer([]byte("secret"), time.Hour)
+	user := &resource.User{UserID: 2, Username: "sally", RoleID: resource.BuyerRoleID, TokenVersion: 3}
+
+	login, err := issuer.Generate(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	challenge, err := issuer.GenerateChallenge(user, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("Login tokens carry the user and token version", func(t *testing.T) {
+		claims, err := issuer.Parse(login)
+
+		want := Claims{UserID: 2, RoleID: int(resource.BuyerRoleID), Username: "sally", TokenVersion: 3}
+		if err != nil || claims != want {
+			t.Errorf("Expected %+v, got %+v %v", want, claims, err)
+		}
+	})
+
+	t.Run("Challenge tokens carry the user", func(t *testing.T) {
+		claims, err := issuer.ParseChallenge(challenge)
+
+		if err != nil || claims.UserID != 2 || claims.Username != "sally" {
+			t.Errorf("Expected sally's claims, got %+v %v", claims, err)
+		}
+	})
+
+	t.Run("Challenge tokens are not login tokens", func(t *testing.T) {
+		if _, err := issuer.Parse(challenge); err == nil {
+			t.Error("Expected the challenge to be refused as a login token")
+		}
+		if _, err := issuer.Verify(challenge); err == nil {
+			t.Error("Expected the challenge to fail verification as a login token")
+		}
+	})
+
+	t.Run("Login tokens are not challenge tokens", func(t *testing.T) {
+		if _, err := issuer.ParseChallenge(login); err == nil {
+			t.Error("Expected the login token to be refused as a challenge")
+		}
+	})
+
+	t.Run("Expired tokens are refused", func(t *testing.T) {
+		expired, err := issuer.GenerateChallenge(user, -time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := issuer.ParseChallenge(expired); err == nil {
+			t.Error("Expected the expired challenge to be refused")
+		}
+	})
+
+	t.Run("Tokens of another secret are refused", func(t *testing.T) {
+		other, err := NewIssuer([]byte("other"), time.Hour).Generate(user)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := issuer.Parse(other); err == nil {
+			t.Error("Expected a token signed with another secret to be refused")
+		}
+	})
+
+	t.Run("CSRF tokens are bound to the login token and the secret", func(t *testing.T) {
+		other, err := issuer.Generate(&resource.User{UserID: 3, Username: "harry"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		csrf := issuer.CSRFToken(login)
+		if csrf == "" || csrf != issuer.CSRFToken(login) {
+			t.Errorf("Expected a stable CSRF token, got %q", csrf)
+		}
+		if csrf == issuer.CSRFToken(other) || csrf == NewIssuer([]byte("other"), time.Hour).CSRFToken(login) {
+			t.Error("Expected the CSRF token to differ for other tokens and secrets")
+		}
+	})
+}
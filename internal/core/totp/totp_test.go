@@ -0,0 +1,66 @@
+package totp
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA-1 key of the RFC 6238 test vectors, base32 encoded.
+var rfcSecret = encoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestCode(t *testing.T) {
+	// The RFC lists eight digit codes; ours are their last six digits.
+	for unix, want := range map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	} {
+		code, err := Code(rfcSecret, Step(time.Unix(unix, 0)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if code != want {
+			t.Errorf("Expected %s at %d, got %s", want, unix, code)
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Unix(1111111109, 0)
+
+	t.Run("Codes of neighbouring steps are accepted", func(t *testing.T) {
+		previous, _ := Code(rfcSecret, Step(now)-1)
+		step, ok := Verify(rfcSecret, previous, now, 1)
+		if !ok || step != Step(now)-1 {
+			t.Errorf("Expected the previous step to match, got %d %v", step, ok)
+		}
+	})
+
+	t.Run("Codes outside the skew are refused", func(t *testing.T) {
+		old, _ := Code(rfcSecret, Step(now)-2)
+		if _, ok := Verify(rfcSecret, old, now, 1); ok {
+			t.Error("Expected an old code to be refused")
+		}
+		if _, ok := Verify("not base32!", "081804", now, 1); ok {
+			t.Error("Expected an invalid secret to be refused")
+		}
+	})
+}
+
+func TestURI(t *testing.T) {
+	secret, err := NewSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	uri := URI("Verkaufsautomat", "sally smith", secret)
+
+	for _, part := range []string{"otpauth://totp/Verkaufsautomat:sally%20smith?", "secret=" + secret, "issuer=Verkaufsautomat", "digits=6", "period=30"} {
+		if !strings.Contains(uri, part) {
+			t.Errorf("Expected %s in %s", part, uri)
+		}
+	}
+}
@@ -0,0 +1,17 @@
+package ports
+
+import (
+	"context"
+	"time"
+	"verkaufsautomat/internal/core/domain/resource"
+)
+
+type APIKeyRepository interface {
+	CreateAPIKey(ctx context.Context, key *resource.APIKey) error
+	GetAPIKeys(ctx context.Context) ([]resource.APIKey, error)
+	GetAPIKeyById(ctx context.Context, id int) (resource.APIKey, error)
+	// GetAPIKeyByPrefix returns ErrInvalidAPIKey if no key has the prefix.
+	GetAPIKeyByPrefix(ctx context.Context, prefix string) (resource.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id int, revokedAt time.Time) error
+	TouchAPIKey(ctx context.Context, id int, usedAt time.Time) error
+}
@@ -0,0 +1,13 @@
+package ports
+
+import (
+	"context"
+	"verkaufsautomat/internal/core/domain/resource"
+)
+
+// AuditRepository is append-only: entries can be added and read, never
+// changed.
+type AuditRepository interface {
+	CreateAuditEntry(ctx context.Context, entry *resource.AuditEntry) error
+	GetAuditEntries(ctx context.Context, filter resource.AuditFilter) ([]resource.AuditEntry, error)
+}
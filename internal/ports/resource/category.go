@@ -0,0 +1,20 @@
+package ports
+
+import (
+	"context"
+	"verkaufsautomat/internal/core/domain/resource"
+)
+
+type CategoryRepository interface {
+	// CreateCategory and UpdateCategory return ErrCategoryExists if another
+	// category has the name.
+	CreateCategory(ctx context.Context, category *resource.Category) error
+	GetCategories(ctx context.Context) ([]resource.Category, error)
+	GetCategoryById(ctx context.Context, id int) (resource.Category, error)
+	// GetCategoriesByIds returns the categories that exist among ids.
+	GetCategoriesByIds(ctx context.Context, ids []uint) ([]resource.Category, error)
+	UpdateCategory(ctx context.Context, category *resource.Category) error
+	// DeleteCategory returns ErrCategoryInUse if products are in the
+	// category.
+	DeleteCategory(ctx context.Context, id int) error
+}
@@ -0,0 +1,11 @@
+package ports
+
+import "verkaufsautomat/internal/core/events"
+
+type EventPublisher interface {
+	Publish(event events.Event)
+}
+
+type EventSubscriber interface {
+	Stream(buffer int, names ...string) (<-chan events.Event, func())
+}
@@ -0,0 +1,27 @@
+package ports
+
+import (
+	"context"
+	"time"
+	"verkaufsautomat/internal/core/domain/resource"
+)
+
+type MFARepository interface {
+	// GetMFA returns ErrMFANotEnabled if the user never started enrolling.
+	GetMFA(ctx context.Context, userID int) (resource.MFA, error)
+	// SaveMFA creates or replaces the second factor of a user.
+	SaveMFA(ctx context.Context, mfa *resource.MFA) error
+	// UseMFAStep records the time step of an accepted code. It returns
+	// ErrInvalidMFACode if a code of that or a later step was accepted
+	// already, so that a code cannot be redeemed twice even by concurrent
+	// requests.
+	UseMFAStep(ctx context.Context, userID int, step int64) error
+	// DeleteMFA removes the second factor and the recovery codes of a user.
+	DeleteMFA(ctx context.Context, userID int) error
+	// ReplaceRecoveryCodes deletes the recovery codes of a user and stores
+	// the given hashes instead.
+	ReplaceRecoveryCodes(ctx context.Context, userID int, hashes []string) error
+	// UseRecoveryCode marks the unused recovery code with the hash used. It
+	// returns ErrInvalidMFACode if there is none.
+	UseRecoveryCode(ctx context.Context, userID int, hash string, usedAt time.Time) error
+}
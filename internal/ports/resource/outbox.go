@@ -0,0 +1,16 @@
+package ports
+
+import (
+	"context"
+	"time"
+	"verkaufsautomat/internal/core/domain/resource"
+)
+
+type OutboxRepository interface {
+	CreateOutboxEvent(ctx context.Context, event *resource.OutboxEvent) error
+	UpdateOutboxEvent(ctx context.Context, event resource.OutboxEvent) error
+	GetDueOutboxEvents(ctx context.Context, now time.Time, limit int) ([]resource.OutboxEvent, error)
+	// DeletePublishedOutboxEvents deletes up to limit events published
+	// before the given time and returns how many it deleted.
+	DeletePublishedOutboxEvents(ctx context.Context, before time.Time, limit int) (int64, error)
+}
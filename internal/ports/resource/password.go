@@ -0,0 +1,32 @@
+package ports
+
+import (
+	"context"
+	"time"
+	"verkaufsautomat/internal/core/domain/resource"
+)
+
+type PasswordRepository interface {
+	GetUserByUsername(ctx context.Context, username string) (resource.User, error)
+	// UpdatePassword stores a new password hash without touching other
+	// columns but the token version, which it raises to revoke the user's
+	// login tokens.
+	UpdatePassword(ctx context.Context, userID int, passwordHash string) error
+	CreatePasswordReset(ctx context.Context, reset *resource.PasswordReset) error
+	// GetPasswordReset returns ErrInvalidResetToken if no reset has the
+	// token hash.
+	GetPasswordReset(ctx context.Context, tokenHash string) (resource.PasswordReset, error)
+	// UsePasswordReset marks the reset used. It returns
+	// ErrInvalidResetToken if it was used already, so that a token cannot
+	// be redeemed twice even by concurrent requests.
+	UsePasswordReset(ctx context.Context, resetID int, usedAt time.Time) error
+	// RevokePasswordResets deletes the unused resets of a user.
+	RevokePasswordResets(ctx context.Context, userID int) error
+}
+
+// Notifier delivers messages to users outside the API.
+type Notifier interface {
+	// NotifyPasswordReset sends the user the token that lets them set a new
+	// password until expiresAt.
+	NotifyPasswordReset(ctx context.Context, user resource.User, token string, expiresAt time.Time) error
+}
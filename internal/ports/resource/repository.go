@@ -1,17 +1,60 @@
 package ports
 
-import "verkaufsautomat/internal/core/domain/resource"
+import (
+	"context"
+	"time"
+	"verkaufsautomat/internal/core/domain/resource"
+)
 
 type MachineRepository interface {
-	HealthCheck() error
-	Register(user *resource.User) error
-	Login(user *resource.User) error
-	CreateProduct(product *resource.Product) error
-	GetProducts() ([]resource.Product, error)
-	GetProductById(id int) (resource.Product, error)
-	UpdateProductByID(id int, product *resource.Product) error
-	DeleteProductByID(id int) error
-	DepositMoney(userid, amount int) error
-	GetUserById(id int) (resource.User, error)
-	UpdateUser(user resource.User) error
+	WebhookRepository
+	OutboxRepository
+	AuditRepository
+	PasswordRepository
+	APIKeyRepository
+	MFARepository
+	CategoryRepository
+	// Transaction runs fn with a repository bound to a single database
+	// transaction, which is committed when fn returns nil.
+	Transaction(ctx context.Context, fn func(repository MachineRepository) error) error
+	// HealthCheck probes the dependencies the service needs to serve
+	// requests.
+	HealthCheck(ctx context.Context) []resource.DependencyStatus
+	Register(ctx context.Context, user *resource.User) error
+	// Login checks the password and fills in the user. It returns
+	// ErrInvalidCredentials for unknown users and wrong passwords alike.
+	Login(ctx context.Context, user *resource.User) error
+	UpdateLoginState(ctx context.Context, userID int, failedLogins int, lockedUntil *time.Time) error
+	// RevokeTokens raises the token version of a user, so that the login
+	// tokens issued so far are refused.
+	RevokeTokens(ctx context.Context, userID int) error
+	CreateProduct(ctx context.Context, product *resource.Product) error
+	GetProducts(ctx context.Context) ([]resource.Product, error)
+	SearchProducts(ctx context.Context, filter resource.ProductFilter) ([]resource.Product, error)
+	GetProductById(ctx context.Context, id int) (resource.Product, error)
+	UpdateProductByID(ctx context.Context, id int, product *resource.Product) error
+	DeleteProductByID(ctx context.Context, id int) error
+	// GetCatalogueModifiedAt returns when a product was last created,
+	// changed, sold or deleted.
+	GetCatalogueModifiedAt(ctx context.Context) (time.Time, error)
+	DepositMoney(ctx context.Context, userid, amount int) error
+	ResetDeposit(ctx context.Context, userID int) error
+	GetUserById(ctx context.Context, id int) (resource.User, error)
+	// GetUserForUpdate locks the user row until the transaction of the
+	// repository ends, so that deposits read from it stay current.
+	GetUserForUpdate(ctx context.Context, id int) (resource.User, error)
+	// UpdateUser stores the username and role only. A changed role raises
+	// the token version, revoking the user's login tokens.
+	UpdateUser(ctx context.Context, user resource.User) error
+	// DeleteUser removes the user. Orders should be anonymised first.
+	DeleteUser(ctx context.Context, userID int) error
+	// AnonymiseOrders detaches the orders of a buyer from the account, so
+	// that sales figures survive its deletion.
+	AnonymiseOrders(ctx context.Context, buyerID int) error
+	GetRole(ctx context.Context, roleID int) (resource.Role, error)
+	GetPermissionsByRole(ctx context.Context, roleID int) ([]string, error)
+	BuyProduct(ctx context.Context, order *resource.Order) (int, error)
+	GetTotalDeposit(ctx context.Context) (int, error)
+	GetOrdersByBuyer(ctx context.Context, buyerID int) ([]resource.Order, error)
+	GetOrderById(ctx context.Context, id int) (resource.Order, error)
 }
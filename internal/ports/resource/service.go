@@ -1,17 +1,119 @@
 package ports
 
-import "verkaufsautomat/internal/core/domain/resource"
+import (
+	"context"
+	"time"
+	"verkaufsautomat/internal/core/domain/resource"
+)
 
 type MachineService interface {
-	HealthCheck() error
-	Register(user *resource.User) error
-	Login(user *resource.User) error
-	CreateProduct(product *resource.Product) error
-	GetProducts() ([]resource.Product, error)
-	GetProductById(id int) (resource.Product, error)
-	UpdateProductByID(id int, product *resource.Product) error
-	DeleteProductByID(id int) error
-	DepositMoney(userid, amount int) error
-	GetUserById(id int) (resource.User, error)
-	UpdateUser(user resource.User) error
+	// HealthCheck probes the dependencies the service needs to serve
+	// requests.
+	HealthCheck(ctx context.Context) []resource.DependencyStatus
+	// Register checks the plain text password against the password policy
+	// and stores the user with the password hashed.
+	Register(ctx context.Context, user *resource.User) error
+	// Login checks the credentials and returns the user, with its role, and
+	// a token for it. Repeated failures lock the account for a while;
+	// unknown users, wrong passwords and locked accounts all yield
+	// ErrInvalidCredentials. Users who need a second factor get
+	// ErrMFARequired and, instead of the login token, a challenge token.
+	Login(ctx context.Context, username, password string) (resource.User, string, error)
+	// AuthenticateToken verifies a login token and returns its user as
+	// currently stored. Tokens of deleted users and tokens issued before
+	// the password or role changed or the user logged out yield
+	// ErrInvalidToken.
+	AuthenticateToken(ctx context.Context, token string) (resource.User, error)
+	// Logout revokes the login tokens of the user, on every device.
+	Logout(ctx context.Context, userID int) error
+	// VerifyMFA completes a login with a challenge token and a TOTP or
+	// recovery code. Users who were enrolling during the login get their
+	// recovery codes too. Wrong codes yield ErrInvalidMFACode and count
+	// towards the lockout.
+	VerifyMFA(ctx context.Context, challenge, code string) (resource.User, string, []string, error)
+	// EnrollMFA starts enrolling a logged in user, EnrollMFAWithChallenge
+	// one who has to enrol before they can log in.
+	EnrollMFA(ctx context.Context, userID int) (resource.MFAEnrollment, error)
+	EnrollMFAWithChallenge(ctx context.Context, challenge string) (resource.User, resource.MFAEnrollment, error)
+	// ConfirmMFA enables the second factor with a first code and returns
+	// the recovery codes.
+	ConfirmMFA(ctx context.Context, userID int, code string) ([]string, error)
+	// DisableMFA removes the second factor after checking the password.
+	// Roles that require one get ErrMFAEnforced.
+	DisableMFA(ctx context.Context, userID int, password string) error
+	RegenerateRecoveryCodes(ctx context.Context, userID int, code string) ([]string, error)
+	// ChangePassword sets a new password after checking the current one,
+	// which counts towards the lockout like a login.
+	ChangePassword(ctx context.Context, userID int, currentPassword, newPassword string) error
+	// RequestPasswordReset sends the user a reset token through the
+	// notifier. Unknown usernames are ignored without an error, so that
+	// callers cannot tell whether an account exists.
+	RequestPasswordReset(ctx context.Context, username string) error
+	// ResetPassword redeems a reset token and sets a new password. It
+	// returns the user the token belonged to.
+	ResetPassword(ctx context.Context, token, newPassword string) (resource.User, error)
+	// CreateProduct and UpdateProductByID normalise the tags and
+	// attributes of the product and resolve its categories by ID. Unknown
+	// categories yield ErrInvalidCategory.
+	CreateProduct(ctx context.Context, product *resource.Product) error
+	GetProducts(ctx context.Context) ([]resource.Product, error)
+	// SearchProducts returns the products that match the filter.
+	SearchProducts(ctx context.Context, filter resource.ProductFilter) ([]resource.Product, error)
+	GetProductById(ctx context.Context, id int) (resource.Product, error)
+	UpdateProductByID(ctx context.Context, id int, product *resource.Product) error
+	DeleteProductByID(ctx context.Context, id int) error
+	// GetCatalogue returns the public view of all products and when any of
+	// them last changed. GetCatalogueProduct returns that of one product
+	// and when it last changed.
+	GetCatalogue(ctx context.Context, filter resource.ProductFilter) (resource.Catalogue, error)
+	GetCatalogueProduct(ctx context.Context, id int) (resource.CatalogueProduct, time.Time, error)
+	DepositMoney(ctx context.Context, userid, amount int) error
+	ResetDeposit(ctx context.Context, userID int) (int, error)
+	GetUserById(ctx context.Context, id int) (resource.User, error)
+	// UpdateUser stores the username and role of the user.
+	UpdateUser(ctx context.Context, user resource.User) error
+	// GetProfile returns the user's own view of the account.
+	GetProfile(ctx context.Context, userID int) (resource.Profile, error)
+	// UpdateProfile renames the user.
+	UpdateProfile(ctx context.Context, userID int, username string) (resource.Profile, error)
+	// DeleteAccount deletes the user after checking the password, which
+	// counts towards the lockout like a login. An outstanding deposit is
+	// refunded if refund is set and refused with ErrDepositOutstanding
+	// otherwise; sellers must delete their products first. The user's
+	// orders are kept, anonymised. It returns the refunded amount.
+	DeleteAccount(ctx context.Context, userID int, password string, refund bool) (int, error)
+	BuyProduct(ctx context.Context, buyerID, productID, quantity int) (resource.Order, int, error)
+	GetTotalDeposit(ctx context.Context) (int, error)
+	GetOrdersByBuyer(ctx context.Context, buyerID int) ([]resource.Order, error)
+	GetOrderById(ctx context.Context, id int) (resource.Order, error)
+	// CreateCategory and UpdateCategory normalise the name.
+	CreateCategory(ctx context.Context, category *resource.Category) error
+	GetCategories(ctx context.Context) ([]resource.Category, error)
+	GetCategoryById(ctx context.Context, id int) (resource.Category, error)
+	UpdateCategory(ctx context.Context, category *resource.Category) error
+	// DeleteCategory refuses categories that products are in with
+	// ErrCategoryInUse.
+	DeleteCategory(ctx context.Context, id int) error
+	CreateWebhook(ctx context.Context, webhook *resource.Webhook) error
+	GetWebhooksBySeller(ctx context.Context, sellerID int) ([]resource.Webhook, error)
+	GetWebhookById(ctx context.Context, id int) (resource.Webhook, error)
+	DeleteWebhookByID(ctx context.Context, id int) error
+	GetWebhookDeliveries(ctx context.Context, webhookID int) ([]resource.WebhookDelivery, error)
+	GetWebhookDeliveryById(ctx context.Context, id int) (resource.WebhookDelivery, error)
+	ReplayWebhookDelivery(ctx context.Context, id int) (resource.WebhookDelivery, error)
+	ChangeUserRole(ctx context.Context, userID, roleID int) (resource.User, error)
+	// UnlockUser lifts a lockout and resets the failed login count.
+	UnlockUser(ctx context.Context, userID int) (resource.User, error)
+	// ResetMFA removes the second factor of a user who lost it.
+	ResetMFA(ctx context.Context, userID int) (resource.User, error)
+	// CreateAPIKey generates the key, which is returned in Key and cannot
+	// be retrieved later.
+	CreateAPIKey(ctx context.Context, key *resource.APIKey) error
+	GetAPIKeys(ctx context.Context) ([]resource.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id int) (resource.APIKey, error)
+	// AuthenticateAPIKey returns the key and the user it acts as. Unknown,
+	// revoked and expired keys yield ErrInvalidAPIKey.
+	AuthenticateAPIKey(ctx context.Context, key string) (resource.APIKey, resource.User, error)
+	RecordAudit(ctx context.Context, entry *resource.AuditEntry) error
+	GetAuditEntries(ctx context.Context, filter resource.AuditFilter) ([]resource.AuditEntry, error)
 }
@@ -0,0 +1,21 @@
+package ports
+
+import (
+	"context"
+	"time"
+	"verkaufsautomat/internal/core/domain/resource"
+)
+
+type WebhookRepository interface {
+	CreateWebhook(ctx context.Context, webhook *resource.Webhook) error
+	GetWebhooksBySeller(ctx context.Context, sellerID int) ([]resource.Webhook, error)
+	GetWebhookById(ctx context.Context, id int) (resource.Webhook, error)
+	DeleteWebhookByID(ctx context.Context, id int) error
+	// CreateWebhookDelivery returns ErrDeliveryExists when the webhook
+	// already has a delivery with the same event ID and replay number.
+	CreateWebhookDelivery(ctx context.Context, delivery *resource.WebhookDelivery) error
+	UpdateWebhookDelivery(ctx context.Context, delivery resource.WebhookDelivery) error
+	GetWebhookDeliveries(ctx context.Context, webhookID int) ([]resource.WebhookDelivery, error)
+	GetWebhookDeliveryById(ctx context.Context, id int) (resource.WebhookDelivery, error)
+	GetDueWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]resource.WebhookDelivery, error)
+}
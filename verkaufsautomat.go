@@ -1,29 +1,269 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"github.com/gin-gonic/gin"
-	"github.com/joho/godotenv"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"io"
+	"net"
+	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
 	adapter "verkaufsautomat/internal/adapter/api/resource"
+	grpcadapter "verkaufsautomat/internal/adapter/grpc/resource"
+	"verkaufsautomat/internal/adapter/httpserver"
+	"verkaufsautomat/internal/adapter/metrics"
+	"verkaufsautomat/internal/adapter/notifier"
+	"verkaufsautomat/internal/adapter/outbox"
 	"verkaufsautomat/internal/adapter/repositories/mysql/resource"
+	"verkaufsautomat/internal/adapter/tracing"
+	"verkaufsautomat/internal/adapter/webhook"
+	"verkaufsautomat/internal/config"
+	models "verkaufsautomat/internal/core/domain/resource"
+	"verkaufsautomat/internal/core/events"
 	"verkaufsautomat/internal/core/logger"
 	services "verkaufsautomat/internal/core/services/resource"
+	"verkaufsautomat/internal/core/token"
+	ports "verkaufsautomat/internal/ports/resource"
 )
 
 func main() {
-	err := godotenv.Load("verkaufsautomat.env")
+	cfg, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		logger.Error("Error loading configuration: " + err.Error())
+		os.Exit(2)
+	}
+	if err := logger.Configure(cfg.Log); err != nil {
+		logger.Error("Error configuring logger: " + err.Error())
+		os.Exit(1)
+	}
+	defer logger.Close()
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		logger.Error("Error setting up tracing: " + err.Error())
+		os.Exit(1)
+	}
+	defer shutdownTracing(context.Background())
+
+	telemetry := metrics.New()
+	router := gin.New()
+	router.Use(gin.Recovery(), otelgin.Middleware("verkaufsautomat"), telemetry.Middleware())
+	database, err := connectDatabase(cfg)
+	if err != nil {
+		logger.Error("Error connecting to the database: " + err.Error())
+		os.Exit(1)
+	}
+	bus := events.NewBus()
+	tokens := token.NewIssuer([]byte(cfg.Auth.JWTSecret), cfg.Auth.TokenExpiry)
+	service := services.New(database, bus, tokens)
+	service.Lockout = services.LockoutPolicy{Threshold: cfg.Auth.LockoutThreshold, Duration: cfg.Auth.LockoutDuration}
+	service.Passwords, err = passwordPolicy(cfg.Auth)
 	if err != nil {
-		logger.Error("Error loading .env file")
+		logger.Error("Error loading breached passwords: " + err.Error())
+		os.Exit(1)
 	}
-	router := gin.Default()
-	database := resource.NewMachineRepositoryDB()
-	service := services.New(database)
-	handler := adapter.NewHTTPHandler(service)
+	service.Notifier = passwordNotifier(cfg.Auth)
+	service.MFA = mfaPolicy(cfg.Auth)
+	service.Webhooks = services.WebhookPolicy{AllowPrivate: cfg.Webhooks.AllowPrivate}
+	handler := adapter.NewHTTPHandler(service, bus, tokens, cfg.HTTP)
 	handler.Routes(router)
-	logger.Info("Starting server on port 8080")
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+
+	telemetry.Subscribe(bus)
+	if err := telemetry.RegisterInventory(service); err != nil {
+		logger.Error("Error registering inventory metrics: " + err.Error())
+	}
+	if db, err := database.DB(); err == nil {
+		if err := telemetry.RegisterDB(db); err != nil {
+			logger.Error("Error registering database metrics: " + err.Error())
+		}
+	}
+	metricsServer := serveMetrics(cfg.Metrics, telemetry)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	workers, stopWorkers := context.WithCancel(context.Background())
+	var running sync.WaitGroup
+
+	dispatcher := webhook.NewDispatcher(database)
+	dispatcher.AllowPrivate = cfg.Webhooks.AllowPrivate
+	sinks := outboxSinks(cfg.Outbox, dispatcher)
+	relay := outbox.NewRelay(database, sinks...)
+	relay.Retention = cfg.Outbox.Retention
+	running.Add(2)
+	go func() {
+		defer running.Done()
+		dispatcher.Run(workers)
+	}()
+	go func() {
+		defer running.Done()
+		relay.Run(workers)
+	}()
+
+	listener, err := net.Listen("tcp", ":"+cfg.GRPC.Port)
+	if err != nil {
+		logger.Error("Error listening on gRPC port: " + err.Error())
+		os.Exit(1)
+	}
+	grpcServer := grpcadapter.NewGRPCServer(service, bus, tokens).NewServer()
+	go func() {
+		logger.Info("Starting gRPC server on port " + cfg.GRPC.Port)
+		if err := grpcServer.Serve(listener); err != nil {
+			logger.Error("gRPC server stopped: " + err.Error())
+		}
+	}()
+
+	server, err := httpserver.New(cfg.HTTP, router)
+	if err != nil {
+		logger.Error("Error setting up HTTP server: " + err.Error())
+		os.Exit(1)
+	}
+	server.RegisterOnShutdown(handler.CloseStreams)
+	go func() {
+		logger.Info("Starting server on port " + cfg.HTTP.Port)
+		if err := server.ListenAndServe(workers); err != nil {
+			logger.Error("HTTP server stopped: " + err.Error())
+			stop()
+		}
+	}()
+
+	<-ctx.Done()
+	stop()
+	logger.Info("Shutting down")
+
+	// In-flight HTTP requests and gRPC calls finish first, so that no
+	// purchase is cut off halfway.
+	if err := server.Shutdown(context.Background()); err != nil {
+		logger.Error("Error draining HTTP requests: " + err.Error())
+	}
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(cfg.HTTP.ShutdownTimeout):
+		grpcServer.Stop()
+	}
+
+	// Then the workers stop, subscribers handle the events already
+	// published and the outbox is relayed once more.
+	stopWorkers()
+	running.Wait()
+	bus.Close()
+	flush, cancel := context.WithTimeout(context.Background(), cfg.HTTP.ShutdownTimeout)
+	relay.RelayDue(flush)
+	cancel()
+	for _, sink := range sinks {
+		if closer, ok := sink.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				logger.Error("Error closing " + sink.Name() + " sink: " + err.Error())
+			}
+		}
+	}
+
+	if metricsServer != nil {
+		metricsServer.Close()
+	}
+	if err := database.Close(); err != nil {
+		logger.Error("Error closing the database: " + err.Error())
+	}
+	logger.Info("Shutdown complete")
+}
+
+// serveMetrics serves Prometheus metrics on their own listener, away from
+// the public API. It returns nil when metrics are turned off.
+func serveMetrics(cfg config.Metrics, telemetry *metrics.Metrics) *http.Server {
+	if cfg.Addr == "" {
+		return nil
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", telemetry.Handler())
+	server := &http.Server{Addr: cfg.Addr, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		logger.Info("Serving metrics on " + cfg.Addr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("Metrics server stopped: " + err.Error())
+		}
+	}()
+	return server
+}
+
+// outboxSinks returns the configured sinks. Events are logged and sent to
+// webhooks by default.
+func outboxSinks(cfg config.Outbox, dispatcher *webhook.Dispatcher) []outbox.Sink {
+	var sinks []outbox.Sink
+	for _, name := range cfg.Sinks {
+		switch name {
+		case "log":
+			sinks = append(sinks, outbox.LogSink{})
+		case "webhook":
+			sinks = append(sinks, outbox.WebhookSink{Dispatcher: dispatcher})
+		case "nats":
+			sinks = append(sinks, outbox.NewNATSSink(cfg.NATSURL))
+		}
+	}
+	return sinks
+}
+
+// passwordPolicy builds the password policy, reading the breached password
+// list if one is configured.
+func passwordPolicy(cfg config.Auth) (services.PasswordPolicy, error) {
+	policy := services.PasswordPolicy{
+		MinLength:   cfg.PasswordMinLength,
+		MaxLength:   cfg.PasswordMaxLength,
+		ResetExpiry: cfg.ResetTokenExpiry,
+	}
+	if cfg.BreachedPasswordsFile == "" {
+		return policy, nil
+	}
+	breached, err := services.LoadBreachedPasswords(cfg.BreachedPasswordsFile)
+	if err != nil {
+		return policy, err
+	}
+	policy.Breached = breached
+	logger.Info("Loaded " + strconv.Itoa(len(breached)) + " breached passwords")
+	return policy, nil
+}
+
+// mfaPolicy builds the two-factor policy, mapping role names to IDs.
+func mfaPolicy(cfg config.Auth) services.MFAPolicy {
+	policy := services.DefaultMFAPolicy
+	policy.Issuer = cfg.MFAIssuer
+	policy.ChallengeExpiry = cfg.MFAChallengeExpiry
+	policy.Required = map[uint]bool{}
+	roles := map[string]uint{"buyer": models.BuyerRoleID, "seller": models.SellerRoleID, "admin": models.AdminRoleID}
+	for _, role := range cfg.MFARequiredRoles {
+		policy.Required[roles[role]] = true
+	}
+	return policy
+}
+
+// passwordNotifier returns the notifier reset tokens are sent through, or
+// nil when none is configured and password resets are refused.
+func passwordNotifier(cfg config.Auth) ports.Notifier {
+	switch cfg.Notifier {
+	case "file":
+		return notifier.NewFile(cfg.NotifierFile)
+	case "log":
+		return notifier.Log{}
 	}
-	router.Run(":" + port)
+	return nil
+}
+
+// connectDatabase waits for the database for up to its connect timeout, so
+// that the server can start before it is reachable.
+func connectDatabase(cfg config.Config) (*resource.MachineRepositoryDB, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Database.ConnectTimeout)
+	defer cancel()
+	return resource.ConnectMachineRepositoryDB(ctx, cfg.Database, cfg.Admin)
 }